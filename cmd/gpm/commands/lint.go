@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/manager"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var lintCmdLongHelp = `
+This command checks the protos of all the directories with the rule sets configured on the .gpm.yaml file: naming,
+packages, enums and comments. The compatibility rule set is only evaluated by the generate command, as it compares
+the protos with the ones published on the target repositories.
+`
+
+var lintCmdExamples = `
+# Lint the protos in the current directory.
+$ gpm lint .
+
+# Only check the naming and enum rules, ignoring the value prefixes.
+$ gpm lint . --ruleSets naming,enums --ignore ENUM_VALUE_PREFIX
+`
+
+var lintOutput string
+
+var lintCmd = &cobra.Command{
+	Use:     "lint <base_path>",
+	Short:   "Check the quality of a collection of proto specs",
+	Long:    lintCmdLongHelp,
+	Example: lintCmdExamples,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if lintOutput != "text" && lintOutput != "json" {
+			log.Fatal().Str("output", lintOutput).Msg("unsupported output format, use text or json")
+		}
+		readConfig(args[0])
+		// Flags take precedence over the configuration file.
+		if cmd.Flags().Changed("ruleSets") {
+			appConfig.Lint.RuleSets = lintRuleSets
+		}
+		if cmd.Flags().Changed("ignore") {
+			appConfig.Lint.Ignore = lintIgnore
+		}
+		gpm := manager.NewManager(appConfig)
+		report, err := gpm.Lint(args[0])
+		if err != nil {
+			log.Fatal().Err(err).Msg("linting failed")
+		}
+		if lintOutput == "json" {
+			content, err := report.JSON()
+			if err != nil {
+				log.Fatal().Err(err).Msg("unable to build JSON report")
+			}
+			fmt.Println(content)
+		} else {
+			fmt.Print(report.Text())
+		}
+		if report.HasIssues() {
+			os.Exit(1)
+		}
+	},
+}
+
+var lintRuleSets []string
+var lintIgnore []string
+
+func init() {
+	lintCmd.Flags().StringVar(&lintOutput, "output", "text", "Output format: text or json")
+	lintCmd.Flags().StringSliceVar(&lintRuleSets, "ruleSets", nil, "Rule sets to check: naming, packages, enums and comments. All of them by default")
+	lintCmd.Flags().StringSliceVar(&lintIgnore, "ignore", nil, "Rules to ignore (e.g., RPC_COMMENTED)")
+	rootCmd.AddCommand(lintCmd)
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/manager"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var planCmdLongHelp = `
+This command reports, for each directory and target language, whether the protos have changed, which files
+differ, the current and next version, and the target repository. No code is generated nor published.
+`
+
+var planCmdExamples = `
+# Show the plan for the protos in the current directory.
+$ gpm plan .
+
+# Obtain the plan in JSON format.
+$ gpm plan . --output json
+`
+
+var planOutput string
+
+var planCmd = &cobra.Command{
+	Use:     "plan <base_path>",
+	Short:   "Show what would be generated and published for a collection of proto specs",
+	Long:    planCmdLongHelp,
+	Example: planCmdExamples,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if planOutput != "text" && planOutput != "json" {
+			log.Fatal().Str("output", planOutput).Msg("unsupported output format, use text or json")
+		}
+		readConfig(args[0])
+		gpm := manager.NewManager(appConfig)
+		plan, err := gpm.Plan(args[0])
+		if err != nil {
+			log.Fatal().Err(err).Msg("planning failed")
+		}
+		if planOutput == "json" {
+			content, err := plan.JSON()
+			if err != nil {
+				log.Fatal().Err(err).Msg("unable to build JSON plan")
+			}
+			fmt.Println(content)
+		} else {
+			fmt.Print(plan.Text())
+		}
+		if plan.HasErrors() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	planCmd.Flags().StringVar(&planOutput, "output", "text", "Output format: text or json")
+	planCmd.Flags().StringVar(&appConfig.RepositoryAccessToken, "repositoryAccessToken", "", "An access token for the authentication of the repository provider.")
+	planCmd.Flags().IntVar(&appConfig.Parallelism, "parallelism", 1, "Number of directory and language pairs processed concurrently")
+	planCmd.Flags().StringVar(&appConfig.GeneratorName, "protoGenerator", "docker", "Implementation used to generate the proto code, whose identity is compared with the published one: docker, dockerized or local.")
+	planCmd.Flags().StringVar(&appConfig.PreRelease, "preRelease", "", "Plan the pre-release versions of a feature branch")
+	planCmd.Flags().BoolVar(&appConfig.GoModule.OverrideGoPackage, "overrideGoPackage", false, "Replace the go_package options that do not match the module path of the target repository instead of failing")
+	rootCmd.AddCommand(planCmd)
+}
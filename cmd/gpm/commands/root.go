@@ -5,7 +5,9 @@ import (
 	"os"
 
 	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var appConfig config.ServiceConfig
@@ -34,4 +36,13 @@ func Execute(version string, commit string) {
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&appConfig.Debug, "debug", false, "Enable debug log")
+	rootCmd.PersistentFlags().StringVar(&appConfig.GitBackend, "gitBackend", "", "Implementation of the git operations: cli (default) or gogit, which does not require the git binary")
+	rootCmd.PersistentFlags().StringVar(&appConfig.CacheDir, "cacheDir", "", "Directory where the target repositories are kept and updated incrementally across runs")
+	rootCmd.PersistentFlags().BoolVar(&appConfig.ShallowClone, "shallowClone", false, "Retrieve only the latest commit and the tags of the target repositories")
+	rootCmd.PersistentFlags().String("tempPath", "/tmp/gpm",
+		"Temporal file for the generation of intermediate data")
+	err := viper.BindPFlag("tempPath", rootCmd.PersistentFlags().Lookup("tempPath"))
+	if err != nil {
+		log.Error().Err(err).Msg("unable to bind viper key")
+	}
 }
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"strings"
+
 	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/manager"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -33,16 +35,18 @@ var generateCmd = &cobra.Command{
 }
 
 func init() {
-	generateCmd.Flags().String("tempPath", "/tmp/gpm",
-		"Temporal file for the generation of intermediate data")
-	generateCmd.Flags().StringVar(&appConfig.GeneratorName, "protoGenerator", "docker", "Implementation used to generate the proto code.")
+	generateCmd.Flags().StringVar(&appConfig.GeneratorName, "protoGenerator", "docker", "Implementation used to generate the proto code: docker, dockerized or local.")
 	generateCmd.Flags().StringVar(&appConfig.RepositoryAccessToken, "repositoryAccessToken", "", "An access token for the authentication of the repository provider. Use this for GitHub actions.")
 	generateCmd.Flags().BoolVar(&appConfig.SkipPublish, "skipPublish", false, "Flag to skip publishing the generated protos")
-	err := viper.BindPFlag("tempPath", generateCmd.Flags().Lookup("tempPath"))
-	if err != nil {
-		log.Error().Err(err).Msg("unable to bind viper key")
-	}
-
+	generateCmd.Flags().StringVar(&appConfig.PublishMode, "publishMode", "", "How the generated code is published: push (default) commits to the default branch, and pullRequest opens a pull request tagged once merged")
+	generateCmd.Flags().StringVar(&appConfig.PreRelease, "preRelease", "", "Publish a pre-release version from a feature branch: the code is pushed to a branch with this name and tagged as vX.Y.Z-<name>.N")
+	generateCmd.Flags().StringVar(&appConfig.SourceCommit, "sourceCommit", "", "Commit of the proto repository included on the commit messages and tag annotations. If empty, it is obtained from the base path")
+	generateCmd.Flags().BoolVar(&appConfig.Lint.Gate, "lint", false, "Lint the protos before generating the code, failing if any issue is found")
+	generateCmd.Flags().BoolVar(&appConfig.GoModule.OverrideGoPackage, "overrideGoPackage", false, "Replace the go_package options that do not match the module path of the target repository instead of failing")
+	generateCmd.Flags().IntVar(&appConfig.Parallelism, "parallelism", 1, "Number of directory and language pairs processed concurrently")
+	generateCmd.Flags().BoolVar(&appConfig.KeepGoing, "keepGoing", false, "Process all the directories even if some of them fail, skipping the ones depending on failed directories")
+	generateCmd.Flags().StringVar(&appConfig.ReportPath, "report", "", "Path of the file where the report of the run is written: JUnit if it ends with .xml, JSON otherwise")
+	generateCmd.Flags().BoolVar(&appConfig.CreateMissingRepos, "createMissingRepos", false, "Flag to create the target repositories that do not exist")
 	rootCmd.AddCommand(generateCmd)
 }
 
@@ -50,6 +54,10 @@ func init() {
 func readConfig(fromPath string) {
 	viper.SetEnvPrefix("GPM")
 	viper.AutomaticEnv()
+	// Nested settings are not resolved by AutomaticEnv unless they are present on the configuration file.
+	for _, key := range []string{"format", "key", "passphrase", "program", "required"} {
+		_ = viper.BindEnv("signing."+key, "GPM_SIGNING_"+strings.ToUpper(key))
+	}
 	viper.AddConfigPath(fromPath)
 	viper.SetConfigName(".gpm") // name of config file (without extension)
 	viper.SetConfigType("yaml") // REQUIRED if the config file does not have the extension in the name
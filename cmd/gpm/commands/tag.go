@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/manager"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var tagCmdLongHelp = `
+When the generated code is published through pull requests, the new versions are tagged once the pull requests
+are merged. This command tags the versions of the merged pull requests on the target repositories of all the
+directories, without waiting for the next generation.
+`
+
+var tagCmdExamples = `
+# Tag the merged pull requests of the target repositories of the protos in the current directory.
+$ gpm tag . --repositoryAccessToken <token>
+`
+
+var tagCmd = &cobra.Command{
+	Use:     "tag <base_path>",
+	Short:   "Tag the versions of the merged pull requests opened by GPM",
+	Long:    tagCmdLongHelp,
+	Example: tagCmdExamples,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		readConfig(args[0])
+		gpm := manager.NewManager(appConfig)
+		if err := gpm.Tag(args[0]); err != nil {
+			log.Fatal().Err(err).Msg("tagging failed")
+		}
+	},
+}
+
+func init() {
+	tagCmd.Flags().StringVar(&appConfig.RepositoryAccessToken, "repositoryAccessToken", "", "An access token for the authentication of the repository provider.")
+	tagCmd.Flags().IntVar(&appConfig.Parallelism, "parallelism", 1, "Number of directory and language pairs processed concurrently")
+	rootCmd.AddCommand(tagCmd)
+}
@@ -3,8 +3,11 @@ module github.com/gpm-project/grpc-proto-manager
 go 1.15
 
 require (
+	github.com/ProtonMail/go-crypto v0.0.0-20210428141323-04723f9f07d7
+	github.com/go-git/go-git/v5 v5.4.2
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/rs/zerolog v1.14.3
 	github.com/spf13/cobra v0.0.3
 	github.com/spf13/viper v1.7.1
+	gopkg.in/yaml.v2 v2.3.0
 )
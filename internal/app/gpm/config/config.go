@@ -3,8 +3,12 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/artifacts"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/protos"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -19,6 +23,14 @@ type ServiceConfig struct {
 	Commit string
 	// RepositoryProvider with the target repository provider (e.g., GitHub).
 	RepositoryProvider string
+	// RepositoryHost with the host of the repository provider. Use this for self-hosted instances.
+	RepositoryHost string
+	// RepositoryDeployTokenUser with the username associated with the access token when it is a deploy token (e.g., GitLab deploy tokens).
+	RepositoryDeployTokenUser string
+	// RepositoryURLTemplate with the template used to build the URL of the target repositories for the generic git provider (e.g., ssh://git@host:2222/{{org}}/{{repo}}.git).
+	RepositoryURLTemplate string
+	// GitBackend with the implementation of the git operations: cli relies on the git binary, and gogit uses an in-process implementation.
+	GitBackend string
 	// RepositoryOrganization with the organization that contains the generated code.
 	RepositoryOrganization string
 	// RepositoryUsername with the name of the actor pushing the changes. This value is required if GPM is executed from within a container.
@@ -27,16 +39,59 @@ type ServiceConfig struct {
 	RepositoryPusherEmail string
 	// RepositoryAccessToken with a token required to access the repository. This value is required for the github action provider.
 	RepositoryAccessToken string
+	// RepositoryNaming with the templates used to name the target repositories.
+	RepositoryNaming RepositoryNaming
+	// CreateMissingRepos determines if the target repositories are created when they do not exist.
+	CreateMissingRepos bool
+	// NewRepositorySettings with the settings applied to the repositories created by GPM.
+	NewRepositorySettings repo.RepoSettings
 	// DefaultLanguage to generate the protos if not .protolangs file is found.
 	DefaultLanguage string
 	// ProjectPath with the path of the gRPC proto repo being analyzed.
 	ProjectPath string
 	// TempPath with the path used to generated temporal data.
 	TempPath string
+	// KeepGoing determines if the remaining directories are processed after a failure. Jobs depending on failed ones are skipped.
+	KeepGoing bool
+	// ReportPath with the file where the report of the run is written. The .xml extension selects the JUnit format and JSON is used otherwise.
+	ReportPath string
+	// CacheDir with the path where the target repositories are kept across runs. If empty, the repositories are
+	// cloned on each run.
+	CacheDir string
+	// ShallowClone determines if only the latest commit and the tags of the target repositories are retrieved.
+	ShallowClone bool
 	// SkipPublish determines if the generated protos are to be published.
 	SkipPublish bool
+	// PublishMode with the way the generated code is published: push commits and tags the changes on the default
+	// branch, and pullRequest opens a pull request whose version is tagged once merged.
+	PublishMode string
+	// PreRelease with the name of the branch published as a pre-release. If set, the generated code is pushed to a
+	// branch with the same name on the target repositories and tagged as a pre-release of the next version.
+	PreRelease string
+	// MessageTemplates with the templates of the commit messages and tag annotations of the published versions.
+	MessageTemplates MessageTemplates
+	// Signing with the settings used to sign the commits and tags of the generated code.
+	Signing Signing
+	// SourceCommit with the commit of the repository containing the protos. If empty, it is obtained from the
+	// project path.
+	SourceCommit string
+	// Lint with the rules checked on the protos.
+	Lint Lint
+	// GoModule with the settings of the go.mod file of the go repositories.
+	GoModule GoModule
+	// Packaging with the settings of the package manifests of the rest of the languages.
+	Packaging Packaging
+	// Publishers with the package registries where the code of each language is published once pushed to the
+	// target repository, indexed by language.
+	Publishers map[string][]artifacts.PublisherOptions
+	// Parallelism with the number of jobs, each one being a directory and language pair, processed concurrently.
+	Parallelism int
 	// GeneratorName with the name of the provider implementing the operations of proto code generation.
 	GeneratorName string
+	// LocalGenerator with the configuration of protoc and its plugins for the local generator.
+	LocalGenerator protos.LocalOptions
+	// DockerGenerator with the images used by the docker generator, globally and per language.
+	DockerGenerator protos.DockerOptions
 }
 
 // resolvePath processes the path given as input and translates it based on relative abstractions.
@@ -79,23 +134,127 @@ func (sc *ServiceConfig) IsValid() error {
 	if sc.DefaultLanguage == "" {
 		return fmt.Errorf("defaultLanguage cannot be empty")
 	}
+	if _, exists := repo.GitBackendTypeToEnum[strings.ToLower(sc.GitBackend)]; sc.GitBackend != "" && !exists {
+		return fmt.Errorf("gitBackend must be cli or gogit")
+	}
+	if _, exists := repo.PublishModeToEnum[strings.ToLower(sc.PublishMode)]; sc.PublishMode != "" && !exists {
+		return fmt.Errorf("publishMode must be push or pullRequest")
+	}
+	if sc.PreRelease != "" {
+		if repo.PreReleaseIdentifier(sc.PreRelease) == "" {
+			return fmt.Errorf("preRelease must contain alphanumeric characters")
+		}
+		if sc.UsePullRequests() {
+			return fmt.Errorf("preRelease cannot be published through pull requests")
+		}
+	}
+	for language, publishers := range sc.Publishers {
+		for _, publisher := range publishers {
+			if err := publisher.IsValid(); err != nil {
+				return fmt.Errorf("invalid publisher of %s: %w", language, err)
+			}
+		}
+		if len(publishers) > 0 && sc.UsePullRequests() {
+			return fmt.Errorf("publishers cannot be used with pull requests")
+		}
+	}
+	if sc.Parallelism < 1 {
+		return fmt.Errorf("parallelism must be at least 1")
+	}
+	if err := sc.RepositoryNaming.IsValid(); err != nil {
+		return fmt.Errorf("invalid repositoryNaming: %w", err)
+	}
+	if err := sc.Signing.IsValid(sc.GitBackend); err != nil {
+		return err
+	}
+	if err := sc.Lint.Options().IsValid(); err != nil {
+		return fmt.Errorf("invalid lint settings: %w", err)
+	}
+	if err := sc.DockerGenerator.IsValid(); err != nil {
+		return fmt.Errorf("invalid dockerGenerator: %w", err)
+	}
+	if err := sc.GoModule.IsValid(); err != nil {
+		return fmt.Errorf("invalid goModule: %w", err)
+	}
+	if err := sc.Packaging.IsValid(); err != nil {
+		return fmt.Errorf("invalid packaging: %w", err)
+	}
+	if err := sc.MessageTemplates.IsValid(); err != nil {
+		return fmt.Errorf("invalid messageTemplates: %w", err)
+	}
 	if err := sc.createDirectoryIfNotExists(sc.TempPath); err != nil {
 		return err
 	}
+	if sc.CacheDir != "" {
+		// The temporal path is removed after each run.
+		if relative, err := filepath.Rel(sc.TempPath, sc.CacheDir); err == nil && !strings.HasPrefix(relative, "..") {
+			return fmt.Errorf("cacheDir cannot be placed inside tempPath")
+		}
+		if err := sc.createDirectoryIfNotExists(sc.CacheDir); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// UsePullRequests determines if the generated code is published through pull requests.
+func (sc *ServiceConfig) UsePullRequests() bool {
+	return repo.PublishModeToEnum[strings.ToLower(sc.PublishMode)] == repo.PullRequestMode
+}
+
 // Print the configuration using the application logger.
 func (sc *ServiceConfig) Print() {
 	// Use logger to print the configuration
 	log.Info().Str("version", sc.Version).Str("commit", sc.Commit).Msg("app config")
-	log.Info().Str("Project", sc.ProjectPath).Str("Temp", sc.TempPath).Msg("Paths")
-	log.Info().Str("Repository", sc.RepositoryProvider).Str("generator", sc.GeneratorName).Msg("Providers")
-	log.Info().Str("Language", sc.DefaultLanguage).Msg("Defaults")
+	log.Info().Str("Project", sc.ProjectPath).Str("Temp", sc.TempPath).Str("Cache", sc.CacheDir).Bool("shallowClone", sc.ShallowClone).Msg("Paths")
+	providersInfo := log.Info().Str("Repository", sc.RepositoryProvider).Str("generator", sc.GeneratorName)
+	if sc.RepositoryHost != "" {
+		providersInfo = providersInfo.Str("host", sc.RepositoryHost)
+	}
+	if sc.RepositoryURLTemplate != "" {
+		providersInfo = providersInfo.Str("urlTemplate", sc.RepositoryURLTemplate)
+	}
+	if sc.GitBackend != "" {
+		providersInfo = providersInfo.Str("gitBackend", sc.GitBackend)
+	}
+	providersInfo.Msg("Providers")
+	if generator := protos.GeneratorTypeToEnum[sc.GeneratorName]; generator == protos.DockerCmd {
+		log.Info().Str("image", sc.DockerGenerator.ImageFor(sc.DefaultLanguage).Reference()).Str("pullPolicy", sc.DockerGenerator.PullPolicy).Int("languageOverrides", len(sc.DockerGenerator.Languages)).Msg("Generator image")
+	}
+	log.Info().Str("Language", sc.DefaultLanguage).Int("parallelism", sc.Parallelism).Msg("Defaults")
 	if sc.SkipPublish {
 		log.Warn().Msg("Proto publication is disabled")
+	} else if sc.PublishMode != "" {
+		log.Info().Str("mode", sc.PublishMode).Msg("Publication")
+	}
+	if sc.PreRelease != "" {
+		log.Info().Str("branch", sc.PreRelease).Str("identifier", repo.PreReleaseIdentifier(sc.PreRelease)).Msg("pre-release versions will be published")
 	}
-	log.Info().Str("URL", sc.RepositoryOrganization).Msg("generated code repository")
+	if sc.Signing.Format != "" {
+		log.Info().Str("format", sc.Signing.Format).Str("key", sc.Signing.Key).Msg("commits and tags will be signed")
+	}
+	if sc.Lint.Gate {
+		log.Info().Strs("ruleSets", sc.Lint.RuleSets).Strs("ignore", sc.Lint.Ignore).Msg("protos will be linted before generation")
+	}
+	if sc.GoModule.Skip {
+		log.Info().Msg("go.mod files and go_package options will not be managed")
+	}
+	if sc.Packaging.Skip {
+		log.Info().Msg("package manifests will not be written")
+	}
+	for language, publishers := range sc.Publishers {
+		for _, publisher := range publishers {
+			log.Info().Str("language", language).Str("type", publisher.Type).Msg("packages will be published")
+		}
+	}
+	if sc.CreateMissingRepos {
+		log.Info().Bool("public", sc.NewRepositorySettings.Public).Str("defaultBranch", sc.NewRepositorySettings.DefaultBranch).Strs("topics", sc.NewRepositorySettings.Topics).Msg("missing repositories will be created")
+	}
+	namingTemplate := sc.RepositoryNaming.Template
+	if namingTemplate == "" {
+		namingTemplate = DefaultRepositoryNameTemplate
+	}
+	log.Info().Str("URL", sc.RepositoryOrganization).Str("naming", namingTemplate).Int("languageOverrides", len(sc.RepositoryNaming.Languages)).Int("directoryOverrides", len(sc.RepositoryNaming.Directories)).Msg("generated code repository")
 	// Pusher related information.
 	pusherInfo := log.Info()
 	if sc.RepositoryPusherUsername == "" {
@@ -111,6 +270,9 @@ func (sc *ServiceConfig) Print() {
 	if sc.RepositoryAccessToken != "" {
 		pusherInfo = pusherInfo.Str("accessToken", strings.Repeat("*", len(sc.RepositoryAccessToken)))
 	}
+	if sc.RepositoryDeployTokenUser != "" {
+		pusherInfo = pusherInfo.Str("deployTokenUser", sc.RepositoryDeployTokenUser)
+	}
 
 	pusherInfo.Msg("pusher information")
 }
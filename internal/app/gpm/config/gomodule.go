@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// goVersionMatcher matches the versions accepted by the go directive (e.g., 1.16).
+var goVersionMatcher = regexp.MustCompile(`^[1-9][0-9]*\.[0-9]+$`)
+
+// GoModule structure with the settings of the go module maintained on the repositories of the go code.
+type GoModule struct {
+	// Skip disables the management of the go.mod file and the go_package validation.
+	Skip bool
+	// GoVersion with the go directive of the created go.mod files. Defaults to 1.16.
+	GoVersion string
+	// OverrideGoPackage replaces the go_package options whose import path does not match the module path instead
+	// of failing.
+	OverrideGoPackage bool
+	// Requirements with the versions of the modules required by the generated code overriding the default ones,
+	// using the module@version syntax (e.g., google.golang.org/grpc@v1.40.0).
+	Requirements []string
+}
+
+// IsValid checks the go version and the requirements.
+func (gm *GoModule) IsValid() error {
+	if gm.GoVersion != "" && !goVersionMatcher.MatchString(gm.GoVersion) {
+		return fmt.Errorf("invalid go version %s, expecting a version such as 1.16", gm.GoVersion)
+	}
+	_, err := gm.RequiredVersions()
+	return err
+}
+
+// RequiredVersions returns the configured versions indexed by module path.
+func (gm *GoModule) RequiredVersions() (map[string]string, error) {
+	result := make(map[string]string, len(gm.Requirements))
+	for _, requirement := range gm.Requirements {
+		parts := strings.SplitN(requirement, "@", 2)
+		if len(parts) != 2 || parts[0] == "" || !strings.HasPrefix(parts[1], "v") {
+			return nil, fmt.Errorf("invalid requirement %s, expecting module@version", requirement)
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result, nil
+}
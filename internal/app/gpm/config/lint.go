@@ -0,0 +1,19 @@
+package config
+
+import "github.com/gpm-project/grpc-proto-manager/internal/pkg/lint"
+
+// Lint structure with the settings of the proto linter.
+type Lint struct {
+	// RuleSets with the enabled rule sets: naming, packages, enums, comments and compatibility. All of them are
+	// enabled if empty.
+	RuleSets []string
+	// Ignore with the identifiers of the rules that are not checked (e.g., RPC_COMMENTED).
+	Ignore []string
+	// Gate determines if the generation fails when the protos have lint issues.
+	Gate bool
+}
+
+// Options returns the options of the linter.
+func (l *Lint) Options() *lint.Options {
+	return &lint.Options{RuleSets: l.RuleSets, Ignore: l.Ignore}
+}
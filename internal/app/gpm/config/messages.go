@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultCommitMessageTemplate with the template of the commits containing the generated code if none is configured.
+const DefaultCommitMessageTemplate = `Publish {{.Version}} of {{.Directory}} for {{.Language}}
+
+Generated by gpm {{.GPMVersion}} from source commit {{.SourceCommit}}.
+{{- if .GeneratorImage}}
+Generator image: {{.GeneratorImage}}
+{{- end}}
+Version: {{.PreviousVersion}} -> {{.Version}} ({{.Bump}})
+Summary: {{.Summary}}
+{{- if .ChangedFiles}}
+
+Changed files:
+{{- range .ChangedFiles}}
+- {{.}}
+{{- end}}
+{{- end}}
+{{- if .Changes}}
+
+Changes:
+{{- range .Changes}}
+- {{.}}
+{{- end}}
+{{- end}}
+`
+
+// DefaultTagMessageTemplate with the template of the version tag annotations if none is configured.
+const DefaultTagMessageTemplate = `Version {{.Version}} of {{.Directory}} for {{.Language}}
+
+Generated by gpm {{.GPMVersion}} from source commit {{.SourceCommit}}.
+Summary: {{.Summary}}
+{{- if .Changes}}
+
+Changes:
+{{- range .Changes}}
+- {{.}}
+{{- end}}
+{{- end}}
+`
+
+// UnknownValue is used on the messages when a value cannot be determined.
+const UnknownValue = "unknown"
+
+// MessageTemplates structure with the templates of the messages used to publish the generated code. The templates
+// use the text/template syntax with the fields of MessageData (e.g., {{.Version}}).
+type MessageTemplates struct {
+	// Commit with the template of the commit message.
+	Commit string
+	// Tag with the template of the version tag annotation.
+	Tag string
+}
+
+// MessageData structure with the information available to the message templates.
+type MessageData struct {
+	// Directory containing the protos relative to the base path.
+	Directory string
+	// Language of the generated code.
+	Language string
+	// Repository with the name of the target repository.
+	Repository string
+	// Version being published.
+	Version string
+	// PreviousVersion with the latest version published before this one.
+	PreviousVersion string
+	// Bump with the type of version increment.
+	Bump string
+	// Summary of the semantic differences among the protos.
+	Summary string
+	// Changes with the classified differences among the protos.
+	Changes []string
+	// ChangedFiles with the proto files that differ from the published ones, prefixed by their status.
+	ChangedFiles []string
+	// Reasons with the inputs of the generation that changed since the published version.
+	Reasons []string
+	// SourceCommit with the commit of the repository containing the protos.
+	SourceCommit string
+	// GPMVersion with the version of the grpc-proto-manager tool.
+	GPMVersion string
+	// GPMCommit with the commit from which the grpc-proto-manager tool was built.
+	GPMCommit string
+	// GeneratorImage with the digest reference of the docker image used to generate the code. Empty if the code is
+	// not generated with docker.
+	GeneratorImage string
+}
+
+// parse returns the template of a message, or the default one if none is configured.
+func (mt *MessageTemplates) parse(name string, text string, defaultText string) (*template.Template, error) {
+	if text == "" {
+		text = defaultText
+	}
+	return template.New(name).Parse(text)
+}
+
+// IsValid checks that the templates can be parsed and rendered with sample data.
+func (mt *MessageTemplates) IsValid() error {
+	sample := MessageData{
+		Directory:       "agenda",
+		Language:        "go",
+		Repository:      "grpc-agenda-go",
+		Version:         "v1.1.0",
+		PreviousVersion: "v1.0.0",
+		Bump:            "minor",
+		Summary:         "minor bump required: 1 minor",
+		Changes:         []string{"[minor] agenda.Agenda.Add: method added"},
+		ChangedFiles:    []string{"modified agenda.proto"},
+		Reasons:         []string{"proto files changed"},
+		SourceCommit:    UnknownValue,
+		GPMVersion:      UnknownValue,
+		GPMCommit:       UnknownValue,
+		GeneratorImage:  "namely/protoc-all@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+	}
+	_, _, err := mt.Render(sample)
+	return err
+}
+
+// Render builds the commit and tag messages for the given data.
+func (mt *MessageTemplates) Render(data MessageData) (string, string, error) {
+	commit, err := mt.render("commit", mt.Commit, DefaultCommitMessageTemplate, data)
+	if err != nil {
+		return "", "", err
+	}
+	tag, err := mt.render("tag", mt.Tag, DefaultTagMessageTemplate, data)
+	if err != nil {
+		return "", "", err
+	}
+	return commit, tag, nil
+}
+
+// render executes a message template. Surrounding blank lines are removed as git ignores them anyway.
+func (mt *MessageTemplates) render(name string, text string, defaultText string, data MessageData) (string, error) {
+	tmpl, err := mt.parse(name, text, defaultText)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s message template: %w", name, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("cannot render %s message: %w", name, err)
+	}
+	message := strings.TrimSpace(sb.String())
+	if message == "" {
+		return "", fmt.Errorf("%s message template produces an empty message", name)
+	}
+	return message, nil
+}
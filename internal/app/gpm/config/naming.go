@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultRepositoryNameTemplate with the template used to name the target repositories if none is configured.
+const DefaultRepositoryNameTemplate = "grpc-{{entity}}-{{language}}"
+
+// Placeholders supported on the repository name templates.
+const (
+	// EntityPlaceholder is replaced with the path of the directory containing the protos relative to the base path.
+	// Nested directories are joined with dashes (e.g., payments/billing is replaced with payments-billing).
+	EntityPlaceholder = "{{entity}}"
+	// LanguagePlaceholder is replaced with the target language.
+	LanguagePlaceholder = "{{language}}"
+)
+
+var placeholderMatcher = regexp.MustCompile(`{{[^}]*}}`)
+
+var repoNameMatcher = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// RepositoryNaming structure with the templates used to name the target repositories. Directory overrides take
+// precedence over language overrides, and those over the general template.
+type RepositoryNaming struct {
+	// Template used to name the target repositories (e.g., {{entity}}-api-{{language}}).
+	Template string
+	// Languages with per language template overrides (e.g., node: web-protos).
+	Languages map[string]string
+	// Directories with per directory template overrides, using the path relative to the base path as key.
+	Directories map[string]string
+}
+
+// template returns the template used to name the repository of a given directory and language.
+func (rn *RepositoryNaming) template(entity string, language string) string {
+	template := rn.Template
+	if template == "" {
+		template = DefaultRepositoryNameTemplate
+	}
+	if override, exists := rn.Languages[language]; exists {
+		template = override
+	}
+	if override, exists := rn.Directories[entity]; exists {
+		template = override
+	}
+	return template
+}
+
+// RepoName obtains the name of the target repository associated with a given directory and language.
+func (rn *RepositoryNaming) RepoName(entity string, language string) string {
+	replacer := strings.NewReplacer(EntityPlaceholder, strings.ReplaceAll(entity, "/", "-"), LanguagePlaceholder, language)
+	return replacer.Replace(rn.template(entity, language))
+}
+
+// Shared checks if the repository of a given directory and language may be shared with other directories, which
+// happens when its template does not contain the entity placeholder (e.g., web-protos).
+func (rn *RepositoryNaming) Shared(entity string, language string) bool {
+	return !strings.Contains(rn.template(entity, language), EntityPlaceholder)
+}
+
+// validateTemplate checks that a template only uses supported placeholders and produces valid repository names.
+func validateTemplate(template string) error {
+	for _, placeholder := range placeholderMatcher.FindAllString(template, -1) {
+		if placeholder != EntityPlaceholder && placeholder != LanguagePlaceholder {
+			return fmt.Errorf("unsupported placeholder %s in repository name template %s", placeholder, template)
+		}
+	}
+	sample := strings.NewReplacer(EntityPlaceholder, "entity", LanguagePlaceholder, "go").Replace(template)
+	if !repoNameMatcher.MatchString(sample) {
+		return fmt.Errorf("repository name template %s does not produce a valid repository name", template)
+	}
+	return nil
+}
+
+// IsValid checks that all the templates are valid.
+func (rn *RepositoryNaming) IsValid() error {
+	if rn.Template != "" {
+		if err := validateTemplate(rn.Template); err != nil {
+			return err
+		}
+		if !strings.Contains(rn.Template, EntityPlaceholder) {
+			return fmt.Errorf("repository name template %s must contain %s so each directory has its own repository", rn.Template, EntityPlaceholder)
+		}
+	}
+	for language, template := range rn.Languages {
+		if err := validateTemplate(template); err != nil {
+			return fmt.Errorf("invalid override for language %s: %w", language, err)
+		}
+	}
+	for directory, template := range rn.Directories {
+		if err := validateTemplate(template); err != nil {
+			return fmt.Errorf("invalid override for directory %s: %w", directory, err)
+		}
+	}
+	return nil
+}
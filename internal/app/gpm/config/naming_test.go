@@ -0,0 +1,91 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepoName(t *testing.T) {
+	naming := RepositoryNaming{
+		Template:    "{{entity}}-api-{{language}}",
+		Languages:   map[string]string{"typescript": "web-protos", "java": "{{entity}}-sdk-java"},
+		Directories: map[string]string{"legacy": "grpc-{{entity}}-{{language}}", "agenda": "agenda-{{language}}"},
+	}
+	testCases := []struct {
+		name     string
+		naming   RepositoryNaming
+		entity   string
+		language string
+		expected string
+		shared   bool
+	}{
+		{"default template", RepositoryNaming{}, "agenda", "go", "grpc-agenda-go", false},
+		{"nested directory", RepositoryNaming{}, "payments/billing", "go", "grpc-payments-billing-go", false},
+		{"template", naming, "billing", "go", "billing-api-go", false},
+		{"language override", naming, "billing", "java", "billing-sdk-java", false},
+		{"shared language override", naming, "billing", "typescript", "web-protos", true},
+		{"directory override", naming, "legacy", "go", "grpc-legacy-go", false},
+		{"directory over language override", naming, "legacy", "typescript", "grpc-legacy-typescript", false},
+		{"shared directory override", naming, "agenda", "typescript", "agenda-typescript", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if repoName := tc.naming.RepoName(tc.entity, tc.language); repoName != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, repoName)
+			}
+			if shared := tc.naming.Shared(tc.entity, tc.language); shared != tc.shared {
+				t.Errorf("expected shared to be %t", tc.shared)
+			}
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	testCases := []struct {
+		template string
+		err      string
+	}{
+		{"grpc-{{entity}}-{{language}}", ""},
+		{"web-protos", ""},
+		{"{{entity}}.api_{{language}}", ""},
+		{"{{org}}-{{entity}}", "unsupported placeholder {{org}}"},
+		{"{{entity}}/{{language}}", "does not produce a valid repository name"},
+		{"", "does not produce a valid repository name"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			err := validateTemplate(tc.template)
+			if tc.err == "" && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Errorf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestRepositoryNamingIsValid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		naming RepositoryNaming
+		err    string
+	}{
+		{"default", RepositoryNaming{}, ""},
+		{"shared overrides", RepositoryNaming{Languages: map[string]string{"typescript": "web-protos"}, Directories: map[string]string{"agenda": "agenda-{{language}}"}}, ""},
+		{"template without entity", RepositoryNaming{Template: "protos-{{language}}"}, "must contain {{entity}}"},
+		{"invalid language override", RepositoryNaming{Languages: map[string]string{"go": "{{repo}}"}}, "invalid override for language go"},
+		{"invalid directory override", RepositoryNaming{Directories: map[string]string{"agenda": "agenda protos"}}, "invalid override for directory agenda"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.naming.IsValid()
+			if tc.err == "" && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Errorf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
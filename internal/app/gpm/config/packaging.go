@@ -0,0 +1,62 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/packaging"
+)
+
+// Packaging structure with the settings of the package manifests written on the repositories of the generated code.
+type Packaging struct {
+	// Skip disables the package manifests.
+	Skip bool
+	// Group with the namespace of the packages, such as the maven groupId. Defaults to the repository organization.
+	Group string
+	// Templates with the manifests of each language, replacing the default ones (pyproject.toml for python, pom.xml
+	// for java and package.json for node).
+	Templates map[string][]packaging.FileTemplate
+}
+
+// IsValid checks that the templates can be rendered with sample data.
+func (p *Packaging) IsValid() error {
+	sample := packaging.Data{
+		Name:        "grpc-agenda-python",
+		Group:       "acme",
+		Directory:   "agenda",
+		Language:    "python",
+		Description: "agenda protos",
+		Version:     "1.1.0",
+		Tag:         "v1.1.0",
+		Files:       []string{"agenda_pb2.py"},
+		Modules:     []string{"agenda_pb2"},
+		Packages:    []string{},
+	}
+	for language, templates := range p.Templates {
+		for _, fileTemplate := range templates {
+			if fileTemplate.Path == "" {
+				return fmt.Errorf("missing path of %s manifest template", language)
+			}
+		}
+		if _, err := packaging.Render(templates, sample); err != nil {
+			return fmt.Errorf("invalid %s manifest template: %w", language, err)
+		}
+	}
+	return nil
+}
+
+// Fingerprint summarizes the settings affecting the manifests of a language, so changing them triggers the
+// generation. It is empty if the defaults are used or the language has no manifests.
+func (p *Packaging) Fingerprint(language string) string {
+	_, configured := p.Templates[language]
+	templates := packaging.Templates(language, p.Templates)
+	if p.Skip || len(templates) == 0 || (!configured && p.Group == "") {
+		return ""
+	}
+	parts := []string{p.Group}
+	for _, fileTemplate := range templates {
+		parts = append(parts, fileTemplate.Path, fileTemplate.Template)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(parts, "\x00"))))[:12]
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+)
+
+// Signing structure with the settings used to sign the commits and tags of the generated code.
+type Signing struct {
+	// Format of the signatures: gpg or ssh. If empty, commits and tags are not signed.
+	Format string
+	// Key used to sign. For gpg, the key ID with the cli backend, or the path of an armored private key with the
+	// gogit backend. For ssh, the path of the key or a public key prefixed by key::.
+	Key string
+	// Passphrase protecting the armored private key used by the gogit backend.
+	Passphrase string
+	// Program used to sign instead of the one of the git configuration.
+	Program string
+	// Required determines if the execution fails when no signing key is configured.
+	Required bool
+}
+
+// IsValid checks that the signing settings are consistent with the git backend.
+func (s *Signing) IsValid(gitBackend string) error {
+	if s.Format == "" {
+		if s.Key != "" {
+			return fmt.Errorf("signing.format must be set to use signing key %s", s.Key)
+		}
+		if s.Required {
+			return fmt.Errorf("signing is required but not configured: set signing.format and signing.key on .gpm.yaml or the GPM_SIGNING_FORMAT and GPM_SIGNING_KEY environment variables")
+		}
+		return nil
+	}
+	format, exists := repo.SigningFormatToEnum[strings.ToLower(s.Format)]
+	if !exists {
+		return fmt.Errorf("signing.format must be gpg or ssh")
+	}
+	if s.Key == "" {
+		return fmt.Errorf("signing.key is required to sign with %s", s.Format)
+	}
+	useGoGit := repo.GitBackendTypeToEnum[strings.ToLower(gitBackend)] == repo.GoGit
+	if useGoGit && format != repo.GPGSigning {
+		return fmt.Errorf("%s signing is not supported by the gogit backend, use the cli backend", s.Format)
+	}
+	// Key IDs of the gpg keyring are resolved by git when signing.
+	if (format == repo.SSHSigning && !strings.HasPrefix(s.Key, repo.SSHLiteralKeyPrefix)) || useGoGit {
+		if _, err := os.Stat(s.Key); err != nil {
+			return fmt.Errorf("cannot access signing key: %w", err)
+		}
+	}
+	return nil
+}
+
+// SigningKey returns the key used to sign the commits and tags, or nil if they are not signed.
+func (s *Signing) SigningKey() *repo.SigningKey {
+	if s.Format == "" {
+		return nil
+	}
+	return &repo.SigningKey{
+		Format:     repo.SigningFormatToEnum[strings.ToLower(s.Format)],
+		Key:        s.Key,
+		Passphrase: s.Passphrase,
+		Program:    s.Program,
+	}
+}
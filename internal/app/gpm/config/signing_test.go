@@ -0,0 +1,62 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+)
+
+func TestSigningIsValid(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "signing.key")
+	if err := ioutil.WriteFile(keyPath, []byte("key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	missingPath := filepath.Join(t.TempDir(), "missing.key")
+	testCases := []struct {
+		name       string
+		signing    Signing
+		gitBackend string
+		err        string
+	}{
+		{"not configured", Signing{}, "cli", ""},
+		{"required but not configured", Signing{Required: true}, "cli", "signing is required but not configured"},
+		{"required but only key", Signing{Key: keyPath, Required: true}, "cli", "signing.format must be set"},
+		{"unknown format", Signing{Format: "x509", Key: keyPath}, "cli", "signing.format must be gpg or ssh"},
+		{"missing key", Signing{Format: "gpg"}, "cli", "signing.key is required"},
+		{"gpg key id on cli", Signing{Format: "gpg", Key: "ABCD1234", Required: true}, "cli", ""},
+		{"ssh key file on cli", Signing{Format: "ssh", Key: keyPath}, "cli", ""},
+		{"ssh literal key on cli", Signing{Format: "ssh", Key: repo.SSHLiteralKeyPrefix + "ssh-ed25519 AAAA"}, "cli", ""},
+		{"ssh missing key file", Signing{Format: "ssh", Key: missingPath}, "cli", "cannot access signing key"},
+		{"gpg key file on gogit", Signing{Format: "GPG", Key: keyPath}, "gogit", ""},
+		{"gpg key id on gogit", Signing{Format: "gpg", Key: "ABCD1234"}, "gogit", "cannot access signing key"},
+		{"ssh on gogit", Signing{Format: "ssh", Key: keyPath}, "gogit", "ssh signing is not supported by the gogit backend"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.signing.IsValid(tc.gitBackend)
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestSigningKey(t *testing.T) {
+	if key := (&Signing{Key: "ABCD1234"}).SigningKey(); key != nil {
+		t.Errorf("expected no key without format, got %+v", key)
+	}
+	signing := Signing{Format: "SSH", Key: "id_ed25519.pub", Program: "ssh-keygen"}
+	key := signing.SigningKey()
+	if key == nil || key.Format != repo.SSHSigning || key.Key != signing.Key || key.Program != signing.Program {
+		t.Errorf("unexpected key %+v", key)
+	}
+}
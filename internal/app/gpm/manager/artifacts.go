@@ -0,0 +1,47 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/artifacts"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/packaging"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+	"github.com/rs/zerolog"
+)
+
+// publishArtifacts publishes the code of a job on the package registries configured for its language. It is called
+// once the version has been pushed to the target repository, so the packages match the tagged code. The code is
+// staged on a temporal directory so the packaging tools do not modify the repository.
+func (gpm *GPM) publishArtifacts(logger zerolog.Logger, job *Job, repoName string, tmpRepoDir string, version *repo.Version, result *JobReport) error {
+	configured := gpm.cfg.Publishers[job.Language]
+	if len(configured) == 0 {
+		return nil
+	}
+	stagingDir, cleanup, err := artifacts.Stage(tmpRepoDir, gpm.cfg.TempPath)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	pkg := artifacts.Package{
+		Name:       repoName,
+		Path:       stagingDir,
+		Version:    packaging.Version(job.Language, version),
+		Tag:        version.String(),
+		ModulePath: job.GoModule,
+	}
+	if version.IsPreRelease() {
+		pkg.PreRelease = version.PreRelease[0]
+	}
+	for _, options := range configured {
+		publisher, err := artifacts.NewPublisher(options)
+		if err != nil {
+			return err
+		}
+		if err := publisher.Publish(pkg); err != nil {
+			return fmt.Errorf("cannot publish %s to %s registry %s: %w", version.String(), options.Type, publisher.Registry(), err)
+		}
+		logger.Info().Str("type", options.Type).Str("registry", publisher.Registry()).Str("version", pkg.Version).Msg("package published")
+		result.Artifacts = append(result.Artifacts, fmt.Sprintf("%s %s", options.Type, publisher.Registry()))
+	}
+	return nil
+}
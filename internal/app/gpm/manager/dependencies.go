@@ -0,0 +1,134 @@
+package manager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/graph"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/parser"
+	"github.com/rs/zerolog/log"
+)
+
+// isEntity checks if a directory contains protos to be generated, that is, proto files or a file with the target languages.
+func (gpm *GPM) isEntity(dirPath string) (bool, error) {
+	fileInfo, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return false, err
+	}
+	for _, info := range fileInfo {
+		if !info.IsDir() && (hasProtoLangs(info.Name()) || strings.HasSuffix(info.Name(), parser.ProtoExtension)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// findEntities returns the paths, relative to the base path, of the directories containing the protos of each entity.
+// Directories without protos are inspected recursively so services may be grouped (e.g., payments/billing). The
+// subdirectories of an entity are considered subpackages of the entity.
+func (gpm *GPM) findEntities(basePath string) ([]string, error) {
+	result := make([]string, 0)
+	var visit func(relativePath string) error
+	visit = func(relativePath string) error {
+		fileInfo, err := ioutil.ReadDir(path.Join(basePath, relativePath))
+		if err != nil {
+			return err
+		}
+		for _, info := range fileInfo {
+			if !info.IsDir() || gpm.isExcluded(info.Name()) {
+				continue
+			}
+			candidate := path.Join(relativePath, info.Name())
+			entity, err := gpm.isEntity(path.Join(basePath, candidate))
+			if err != nil {
+				return err
+			}
+			if entity {
+				result = append(result, candidate)
+			} else if err := visit(candidate); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(""); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ownerEntity returns the entity containing an imported file, that is, the one with the longest path that is a
+// prefix of the import. An empty string is returned if the import does not belong to any entity.
+func ownerEntity(importPath string, entities []string) string {
+	owner := ""
+	for _, entity := range entities {
+		if strings.HasPrefix(importPath, entity+"/") && len(entity) > len(owner) {
+			owner = entity
+		}
+	}
+	return owner
+}
+
+// BuildDependencyGraph parses the import statements of the protos of each entity and builds the graph of
+// dependencies among entity directories. Imports are resolved relative to the base path, so an import of
+// common/types.proto from the agenda directory makes agenda depend on common.
+func (gpm *GPM) BuildDependencyGraph(basePath string, entities []string) (*graph.DependencyGraph, error) {
+	dependencies := graph.NewDependencyGraph()
+	for _, entity := range entities {
+		dependencies.AddNode(entity)
+		protoFiles, err := parser.ParseTree(path.Join(basePath, entity))
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse protos of %s: %w", entity, err)
+		}
+		for _, protoFile := range protoFiles {
+			for _, imported := range protoFile.Imports {
+				dependency := ownerEntity(imported.Path, entities)
+				if dependency != "" && dependency != entity {
+					dependencies.AddDependency(entity, dependency)
+				}
+			}
+		}
+		if direct := dependencies.DirectDependencies(entity); len(direct) > 0 {
+			log.Debug().Str("entity", entity).Strs("dependencies", direct).Msg("cross directory dependencies")
+		}
+	}
+	return dependencies, nil
+}
+
+// loadLayers finds the entities of the base path and groups them so that each entity appears after the ones it depends on.
+func (gpm *GPM) loadLayers(basePath string) ([][]string, error) {
+	entities, err := gpm.findEntities(basePath)
+	if err != nil {
+		return nil, err
+	}
+	gpm.dependencies, err = gpm.BuildDependencyGraph(basePath, entities)
+	if err != nil {
+		return nil, err
+	}
+	return gpm.dependencies.Layers()
+}
+
+// markChanged registers that the protos of an entity have changed.
+func (gpm *GPM) markChanged(entity string) {
+	gpm.mutex.Lock()
+	defer gpm.mutex.Unlock()
+	gpm.changedEntities[entity] = true
+}
+
+// changedDependencies returns the dependencies of an entity, including transitive ones, that have changed on this run.
+func (gpm *GPM) changedDependencies(entity string) []string {
+	result := make([]string, 0)
+	if gpm.dependencies == nil {
+		return result
+	}
+	gpm.mutex.Lock()
+	defer gpm.mutex.Unlock()
+	for _, dependency := range gpm.dependencies.Dependencies(entity) {
+		if gpm.changedEntities[dependency] {
+			result = append(result, dependency)
+		}
+	}
+	return result
+}
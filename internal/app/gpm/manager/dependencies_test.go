@@ -0,0 +1,66 @@
+package manager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+)
+
+// writeProtoTree creates a set of protos importing the given files, indexed by their path relative to the base path.
+func writeProtoTree(t *testing.T, imports map[string][]string) string {
+	t.Helper()
+	basePath := t.TempDir()
+	for name, imported := range imports {
+		content := "syntax = \"proto3\";\n"
+		for _, importPath := range imported {
+			content += "import \"" + importPath + "\";\n"
+		}
+		filePath := filepath.Join(basePath, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return basePath
+}
+
+func TestLoadLayers(t *testing.T) {
+	basePath := writeProtoTree(t, map[string][]string{
+		"common/types.proto":               {"google/protobuf/timestamp.proto"},
+		"payments/billing/billing.proto":   {"common/types.proto", "payments/billing/sub/items.proto"},
+		"payments/billing/sub/items.proto": {},
+		"payments/ledger/ledger.proto":     {"common/types.proto"},
+		"api/api.proto":                    {"payments/billing/billing.proto", "payments/ledger/ledger.proto", "api/types.proto"},
+		"api/types.proto":                  {},
+	})
+	gpm := NewManager(config.ServiceConfig{})
+	layers, err := gpm.loadLayers(basePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]string{{"common"}, {"payments/billing", "payments/ledger"}, {"api"}}
+	if !reflect.DeepEqual(layers, expected) {
+		t.Errorf("expected %v, got %v", expected, layers)
+	}
+	if direct := gpm.dependencies.DirectDependencies("payments/billing"); !reflect.DeepEqual(direct, []string{"common"}) {
+		t.Errorf("imports of the same entity must be ignored, got %v", direct)
+	}
+}
+
+func TestLoadLayersCycle(t *testing.T) {
+	basePath := writeProtoTree(t, map[string][]string{
+		"agenda/agenda.proto": {"common/types.proto"},
+		"common/types.proto":  {"agenda/agenda.proto"},
+	})
+	_, err := NewManager(config.ServiceConfig{}).loadLayers(basePath)
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle detected: agenda -> common -> agenda") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package manager
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/gomod"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/parser"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/protos"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+	"github.com/rs/zerolog"
+)
+
+// goPackageName returns the name of the go package of the generated code: the one given by a go_package option,
+// the last segment of the proto package, or the last element of the module path in this order.
+func goPackageName(protoFiles []*parser.ProtoFile, modulePath string) string {
+	for _, protoFile := range protoFiles {
+		if parts := strings.SplitN(protoFile.Options["go_package"], ";", 2); len(parts) == 2 && parts[1] != "" {
+			return parts[1]
+		}
+	}
+	for _, protoFile := range protoFiles {
+		if protoFile.Package != "" {
+			segments := strings.Split(protoFile.Package, ".")
+			return gomod.PackageName(segments[len(segments)-1])
+		}
+	}
+	return gomod.PackageName(path.Base(modulePath))
+}
+
+// goModulePath returns the module path of the target repository of a go job. The path of local repositories is not
+// importable, so the package of the target is used as module path and must be set explicitly.
+func goModulePath(target protos.Target, repoURL string) (string, error) {
+	if !strings.HasPrefix(repoURL, repo.FileURLPrefix) {
+		return gomod.ModulePathFromURL(repoURL)
+	}
+	if target.Package == "" {
+		return "", fmt.Errorf("cannot derive module path from local repository URL %s, set the package of the go language on .protolangs", repoURL)
+	}
+	return gomod.ImportPath(target.Package), nil
+}
+
+// prepareGoTarget derives the module path of a go job from the URL of its target repository and checks that the
+// go_package options of the protos match the import path of the generated code. As the generated files are stored
+// on the root of the repository, the import path is the module path, or the package of the directory on repositories
+// shared by several directories. Files without go_package, or with a different one if OverrideGoPackage is set, are
+// mapped to the import path through the package of the target.
+func (gpm *GPM) prepareGoTarget(logger zerolog.Logger, job *Job, repoURL string) error {
+	if job.Language != "go" || gpm.cfg.GoModule.Skip {
+		return nil
+	}
+	modulePath, err := goModulePath(job.Target, repoURL)
+	if err != nil {
+		return err
+	}
+	job.GoModule = modulePath
+	importPath := path.Join(modulePath, gpm.codePath(job, gpm.getRepoName(job)))
+	if job.Target.Package != "" {
+		if packagePath := gomod.ImportPath(job.Target.Package); packagePath != importPath && !gpm.cfg.GoModule.OverrideGoPackage {
+			return fmt.Errorf("package %s does not match the import path %s of the target repository", packagePath, importPath)
+		} else if packagePath == importPath {
+			return nil
+		}
+		logger.Warn().Str("package", job.Target.Package).Str("importPath", importPath).Msg("overriding package")
+	}
+	protoFiles, err := parser.ParseDirectory(job.TargetPath)
+	if err != nil {
+		return err
+	}
+	inject := job.Target.Package != ""
+	for _, protoFile := range protoFiles {
+		goPackage := protoFile.Options["go_package"]
+		switch {
+		case goPackage == "":
+			inject = true
+		case gomod.ImportPath(goPackage) == importPath:
+		case gpm.cfg.GoModule.OverrideGoPackage:
+			logger.Warn().Str("file", protoFile.Name).Str("goPackage", goPackage).Str("importPath", importPath).Msg("overriding go_package")
+			inject = true
+		default:
+			return fmt.Errorf("go_package %s of %s does not match the import path %s of the target repository", goPackage, protoFile.Name, importPath)
+		}
+	}
+	if inject {
+		job.Target.Package = fmt.Sprintf("%s;%s", importPath, goPackageName(protoFiles, importPath))
+		logger.Debug().Str("package", job.Target.Package).Msg("go_package injected")
+	}
+	return nil
+}
+
+// goRequirements returns the versions of the modules that can be required by the generated code, applying the
+// configured ones over the defaults.
+func (gpm *GPM) goRequirements(configured map[string]string) map[string]string {
+	result := make(map[string]string, len(gomod.KnownModules)+len(configured))
+	for module, version := range gomod.KnownModules {
+		result[module] = version
+	}
+	for module, version := range configured {
+		result[module] = version
+	}
+	return result
+}
+
+// updateGoModule creates or maintains the go.mod file of the target repository of a go job. The module path is
+// fixed, the modules imported by the generated code are required, and the known modules that are no longer imported
+// are dropped. Versions already present are preserved unless configured.
+func (gpm *GPM) updateGoModule(logger zerolog.Logger, job *Job, tmpRepoDir string) error {
+	if job.Language != "go" || job.GoModule == "" {
+		return nil
+	}
+	modFile, err := gomod.Read(tmpRepoDir)
+	if err != nil {
+		return err
+	}
+	if modFile == nil {
+		logger.Info().Str("module", job.GoModule).Msg("creating go.mod")
+		modFile = gomod.New(job.GoModule, gpm.cfg.GoModule.GoVersion)
+	} else if modFile.Module() != job.GoModule {
+		logger.Info().Str("previous", modFile.Module()).Str("module", job.GoModule).Msg("updating module path")
+		modFile.SetModule(job.GoModule)
+	}
+	imports, err := gomod.Imports(tmpRepoDir)
+	if err != nil {
+		return fmt.Errorf("cannot obtain imports of the generated code: %w", err)
+	}
+	configured, err := gpm.cfg.GoModule.RequiredVersions()
+	if err != nil {
+		return err
+	}
+	candidates := gpm.goRequirements(configured)
+	required := make(map[string]bool, 0)
+	for _, importPath := range imports {
+		module := gomod.ModuleOf(importPath, candidates)
+		switch {
+		case module != "":
+			required[module] = true
+		case strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".") && !strings.HasPrefix(importPath, job.GoModule):
+			logger.Warn().Str("import", importPath).Msg("unknown module, add it to goModule.requirements")
+		}
+	}
+	modules := make([]string, 0, len(candidates))
+	for module := range candidates {
+		modules = append(modules, module)
+	}
+	// Modules are added in reverse order so the require block ends up sorted.
+	sort.Sort(sort.Reverse(sort.StringSlice(modules)))
+	for _, module := range modules {
+		version := candidates[module]
+		_, pinned := configured[module]
+		switch {
+		case !required[module]:
+			modFile.DropRequire(module)
+		case pinned:
+			modFile.SetRequire(module, version)
+		default:
+			modFile.AddRequire(module, version)
+		}
+	}
+	return modFile.Write(tmpRepoDir)
+}
@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/protos"
+	"github.com/rs/zerolog"
+)
+
+// agendaRepoURL with the URL of the target repository used on the tests.
+const agendaRepoURL = "git@github.com:acme/grpc-agenda-go.git"
+
+// agendaModule with the module path derived from agendaRepoURL.
+const agendaModule = "github.com/acme/grpc-agenda-go"
+
+// goProto returns a proto file of the agenda package with an optional go_package option.
+func goProto(goPackage string) string {
+	content := "syntax = \"proto3\";\npackage acme.agenda.v1;\n"
+	if goPackage != "" {
+		content += "option go_package = \"" + goPackage + "\";\n"
+	}
+	return content + "message Entry {\n  string entry_id = 1;\n}\n"
+}
+
+func TestPrepareGoTarget(t *testing.T) {
+	testCases := []struct {
+		name          string
+		protos        map[string]string
+		language      string
+		repoURL       string
+		targetPackage string
+		config        config.GoModule
+		module        string
+		expected      string
+		fails         bool
+	}{
+		{name: "go_package missing", protos: map[string]string{"a.proto": goProto("")},
+			module: agendaModule, expected: agendaModule + ";v1"},
+		{name: "go_package missing on some files", protos: map[string]string{"a.proto": goProto(agendaModule + ";agenda"), "b.proto": goProto("")},
+			module: agendaModule, expected: agendaModule + ";agenda"},
+		{name: "go_package matches", protos: map[string]string{"a.proto": goProto(agendaModule), "b.proto": goProto(agendaModule + ";agenda")},
+			module: agendaModule, expected: ""},
+		{name: "go_package differs", protos: map[string]string{"a.proto": goProto("github.com/acme/other;other")},
+			fails: true},
+		{name: "go_package overridden", protos: map[string]string{"a.proto": goProto("github.com/acme/other;other")}, config: config.GoModule{OverrideGoPackage: true},
+			module: agendaModule, expected: agendaModule + ";other"},
+		{name: "https URL", protos: map[string]string{"a.proto": goProto("")}, repoURL: "https://TOKEN@gitlab.acme.com/group/grpc-agenda-go.git",
+			module: "gitlab.acme.com/group/grpc-agenda-go", expected: "gitlab.acme.com/group/grpc-agenda-go;v1"},
+		{name: "ssh URL", protos: map[string]string{"a.proto": goProto("git.acme.com/acme/grpc-agenda-go")}, repoURL: "ssh://git@git.acme.com:2222/acme/grpc-agenda-go.git",
+			module: "git.acme.com/acme/grpc-agenda-go", expected: ""},
+		{name: "package matches", protos: map[string]string{"a.proto": goProto("github.com/acme/other")}, targetPackage: agendaModule + ";agenda",
+			module: agendaModule, expected: agendaModule + ";agenda"},
+		{name: "package differs", protos: map[string]string{"a.proto": goProto("")}, targetPackage: "github.com/acme/other",
+			fails: true},
+		{name: "package overridden", protos: map[string]string{"a.proto": goProto("")}, targetPackage: "github.com/acme/other", config: config.GoModule{OverrideGoPackage: true},
+			module: agendaModule, expected: agendaModule + ";v1"},
+		{name: "local repository without package", protos: map[string]string{"a.proto": goProto("")}, repoURL: "file:///tmp/repos/grpc-agenda-go.git",
+			fails: true},
+		{name: "local repository with package", protos: map[string]string{"a.proto": goProto("")}, repoURL: "file:///tmp/repos/grpc-agenda-go.git", targetPackage: "example.com/agenda;agenda",
+			module: "example.com/agenda", expected: "example.com/agenda;agenda"},
+		{name: "other language", protos: map[string]string{"a.proto": goProto("github.com/acme/other")}, language: "python", repoURL: "file:///tmp/repos/grpc-agenda-python.git",
+			module: "", expected: ""},
+		{name: "skipped", protos: map[string]string{"a.proto": goProto("github.com/acme/other")}, config: config.GoModule{Skip: true},
+			module: "", expected: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			targetPath := t.TempDir()
+			for name, content := range tc.protos {
+				if err := ioutil.WriteFile(filepath.Join(targetPath, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			language, repoURL := tc.language, tc.repoURL
+			if language == "" {
+				language = "go"
+			}
+			if repoURL == "" {
+				repoURL = agendaRepoURL
+			}
+			job := &Job{Entity: "agenda", TargetPath: targetPath, Language: language, Target: protos.NewTarget(language)}
+			job.Target.Package = tc.targetPackage
+			gpm := NewManager(config.ServiceConfig{GoModule: tc.config})
+			err := gpm.prepareGoTarget(zerolog.Nop(), job, repoURL)
+			if tc.fails {
+				if err == nil {
+					t.Errorf("expected error, got package %s", job.Target.Package)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if job.GoModule != tc.module {
+				t.Errorf("expected module %q, got %q", tc.module, job.GoModule)
+			}
+			if job.Target.Package != tc.expected {
+				t.Errorf("expected package %q, got %q", tc.expected, job.Target.Package)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package manager
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/protos"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Job structure with a unit of work: the generation of the code of a proto directory in a given language.
+type Job struct {
+	// Entity with the name of the directory containing the protos.
+	Entity string
+	// TargetPath with the path of the directory containing the protos.
+	TargetPath string
+	// Language to be generated.
+	Language string
+	// Target with the settings of the language defined on the directory.
+	Target protos.Target
+	// GoModule with the path of the go module of the target repository. Only set for go jobs.
+	GoModule string
+}
+
+// String representation of the job.
+func (j *Job) String() string {
+	return fmt.Sprintf("%s/%s", j.Entity, j.Language)
+}
+
+// workDir returns the name of the temporal directory used by the job. Nested entities are flattened so each job
+// uses a single directory.
+func (j *Job) workDir() string {
+	return fmt.Sprintf("%s-%s", strings.ReplaceAll(j.Entity, "/", "-"), j.Language)
+}
+
+// JobError structure with the failure of a given job.
+type JobError struct {
+	// Job with the string representation of the failed job.
+	Job string
+	// Err with the cause of the failure.
+	Err error
+}
+
+// RunError structure aggregating the failures of the jobs of a run.
+type RunError struct {
+	// Failures with the errors of each failed job.
+	Failures []JobError
+	// Skipped with the jobs that have not been processed because a dependency failed.
+	Skipped []JobError
+}
+
+// describe joins the description of a set of job errors.
+func describe(jobErrors []JobError) string {
+	descriptions := make([]string, 0, len(jobErrors))
+	for _, jobError := range jobErrors {
+		descriptions = append(descriptions, fmt.Sprintf("%s: %s", jobError.Job, jobError.Err.Error()))
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// Error returns the description of all the failures.
+func (re *RunError) Error() string {
+	result := fmt.Sprintf("%d job(s) failed: %s", len(re.Failures), describe(re.Failures))
+	if len(re.Skipped) > 0 {
+		result = fmt.Sprintf("%s; %d job(s) skipped: %s", result, len(re.Skipped), describe(re.Skipped))
+	}
+	return result
+}
+
+// jobLogger returns a logger whose lines are attributed to a given job.
+func (gpm *GPM) jobLogger(entity string, language string) zerolog.Logger {
+	return log.With().Str("job", fmt.Sprintf("%s/%s", entity, language)).Logger()
+}
+
+// buildJobs creates the jobs associated with a set of entities, one per target language.
+func (gpm *GPM) buildJobs(basePath string, entities []string) ([]*Job, error) {
+	result := make([]*Job, 0)
+	for _, entity := range entities {
+		targetPath := path.Join(basePath, entity)
+		targets, err := gpm.LoadProtoLangs(targetPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range targets {
+			log.Debug().Str("entity", entity).Str("target", target.String()).Msg("target")
+			result = append(result, &Job{Entity: entity, TargetPath: targetPath, Language: target.Language, Target: target})
+		}
+	}
+	return result, nil
+}
+
+// registerRepos registers the target repositories shared by several directories, whose code is stored on a
+// subdirectory per directory. Repositories named after a directory cannot be targeted by the jobs of other
+// directories, as that would be an accidental collision of the naming templates.
+func (gpm *GPM) registerRepos(basePath string, layers [][]string) error {
+	entities := make(map[string]map[string]bool, 0)
+	dedicated := make(map[string]bool, 0)
+	for _, layer := range layers {
+		jobs, err := gpm.buildJobs(basePath, layer)
+		if err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			repoName := gpm.getRepoName(job)
+			if _, exists := entities[repoName]; !exists {
+				entities[repoName] = make(map[string]bool, 0)
+			}
+			entities[repoName][job.Entity] = true
+			if !gpm.isSharedRepo(job) {
+				dedicated[repoName] = true
+			}
+		}
+	}
+	collisions := make([]string, 0)
+	for repoName, targeting := range entities {
+		if len(targeting) < 2 {
+			continue
+		}
+		if dedicated[repoName] {
+			collisions = append(collisions, repoName)
+			continue
+		}
+		log.Debug().Str("repo", repoName).Int("directories", len(targeting)).Msg("shared repository")
+		gpm.sharedRepos[repoName] = true
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+	sort.Strings(collisions)
+	directories := make([]string, 0, len(entities[collisions[0]]))
+	for entity := range entities[collisions[0]] {
+		directories = append(directories, entity)
+	}
+	sort.Strings(directories)
+	return fmt.Errorf("repository %s is targeted by directories %s, use a repository name template without %s to share it", collisions[0], strings.Join(directories, ", "), config.EntityPlaceholder)
+}
+
+// runJobs processes a set of independent jobs using a pool of workers. All the jobs are processed and their
+// failures are registered and returned sorted by job.
+func (gpm *GPM) runJobs(jobs []*Job, process func(job *Job) error) []JobError {
+	parallelism := gpm.cfg.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	queue := make(chan *Job)
+	failures := make([]JobError, 0)
+	var failuresMutex sync.Mutex
+	var wg sync.WaitGroup
+	for worker := 0; worker < parallelism && worker < len(jobs); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				if err := process(job); err != nil {
+					logger := gpm.jobLogger(job.Entity, job.Language)
+					logger.Error().Err(err).Msg("job failed")
+					gpm.markFailed(job)
+					failuresMutex.Lock()
+					failures = append(failures, JobError{Job: job.String(), Err: err})
+					failuresMutex.Unlock()
+				}
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Job < failures[j].Job
+	})
+	return failures
+}
+
+// jobKey structure identifying the jobs of a directory and language.
+type jobKey struct {
+	entity   string
+	language string
+}
+
+// markFailed registers that a job has failed.
+func (gpm *GPM) markFailed(job *Job) {
+	gpm.mutex.Lock()
+	defer gpm.mutex.Unlock()
+	gpm.failedJobs[jobKey{entity: job.Entity, language: job.Language}] = true
+}
+
+// failedDependencies returns the dependencies of a job, including transitive ones, whose code for the same language
+// could not be generated on this run.
+func (gpm *GPM) failedDependencies(job *Job) []string {
+	result := make([]string, 0)
+	if gpm.dependencies == nil {
+		return result
+	}
+	gpm.mutex.Lock()
+	defer gpm.mutex.Unlock()
+	for _, dependency := range gpm.dependencies.Dependencies(job.Entity) {
+		if gpm.failedJobs[jobKey{entity: dependency, language: job.Language}] {
+			result = append(result, dependency)
+		}
+	}
+	return result
+}
+
+// skipFailedDependencies separates the jobs that can be processed from those depending on failed jobs. Skipped
+// jobs are registered on the run report and considered failed so their dependents are skipped too.
+func (gpm *GPM) skipFailedDependencies(jobs []*Job) ([]*Job, []JobError) {
+	runnable := make([]*Job, 0, len(jobs))
+	skipped := make([]JobError, 0)
+	for _, job := range jobs {
+		failed := gpm.failedDependencies(job)
+		if len(failed) == 0 {
+			runnable = append(runnable, job)
+			continue
+		}
+		err := fmt.Errorf("skipped as dependencies failed: %s", strings.Join(failed, ", "))
+		logger := gpm.jobLogger(job.Entity, job.Language)
+		logger.Warn().Strs("dependencies", failed).Msg("job skipped")
+		gpm.report.skipJob(job, err)
+		skipped = append(skipped, JobError{Job: job.String(), Err: err})
+	}
+	return runnable, skipped
+}
+
+// repoLock returns the lock associated with a target repository. Jobs publishing on the same repository, for
+// example due to a shared repository naming override, must not run concurrently.
+func (gpm *GPM) repoLock(repoName string) *sync.Mutex {
+	gpm.mutex.Lock()
+	defer gpm.mutex.Unlock()
+	lock, exists := gpm.repoLocks[repoName]
+	if !exists {
+		lock = &sync.Mutex{}
+		gpm.repoLocks[repoName] = lock
+	}
+	return lock
+}
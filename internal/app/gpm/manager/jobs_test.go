@@ -0,0 +1,133 @@
+package manager
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+)
+
+func TestRunJobsInParallel(t *testing.T) {
+	gpm := NewManager(config.ServiceConfig{Parallelism: 4})
+	jobs := []*Job{
+		{Entity: "agenda", Language: "go"},
+		{Entity: "agenda", Language: "python"},
+		{Entity: "billing", Language: "go"},
+		{Entity: "billing", Language: "python"},
+		{Entity: "ledger", Language: "go"},
+		{Entity: "ledger", Language: "python"},
+	}
+	// Both python jobs of billing and ledger publish on the same repository.
+	repoNames := map[string]string{
+		"agenda/go":      "grpc-agenda-go",
+		"agenda/python":  "grpc-agenda-python",
+		"billing/go":     "grpc-billing-go",
+		"billing/python": "grpc-payments-python",
+		"ledger/go":      "grpc-ledger-go",
+		"ledger/python":  "grpc-payments-python",
+	}
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	active := make(map[string]int, 0)
+	overlapped := make([]string, 0)
+	failures := gpm.runJobs(jobs, func(job *Job) error {
+		repoName := repoNames[job.String()]
+		lock := gpm.repoLock(repoName)
+		lock.Lock()
+		defer lock.Unlock()
+
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		active[repoName]++
+		if active[repoName] > 1 {
+			overlapped = append(overlapped, repoName)
+		}
+		mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mutex.Lock()
+		running--
+		active[repoName]--
+		mutex.Unlock()
+		if job.Language == "python" && job.Entity != "agenda" {
+			return errors.New("publication failed")
+		}
+		return nil
+	})
+
+	if len(overlapped) > 0 {
+		t.Errorf("jobs targeting the same repository ran concurrently: %v", overlapped)
+	}
+	if maxRunning < 2 {
+		t.Errorf("expected jobs to run concurrently, got at most %d", maxRunning)
+	}
+	failed := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		failed = append(failed, failure.Job)
+	}
+	if expected := []string{"billing/python", "ledger/python"}; !reflect.DeepEqual(failed, expected) {
+		t.Errorf("expected failures %v, got %v", expected, failed)
+	}
+	if !gpm.failedJobs[jobKey{entity: "billing", language: "python"}] || gpm.failedJobs[jobKey{entity: "billing", language: "go"}] {
+		t.Errorf("unexpected failed jobs %v", gpm.failedJobs)
+	}
+}
+
+func TestRunJobsWithoutJobs(t *testing.T) {
+	gpm := NewManager(config.ServiceConfig{Parallelism: 4})
+	if failures := gpm.runJobs([]*Job{}, func(job *Job) error { return errors.New("unexpected") }); len(failures) != 0 {
+		t.Errorf("unexpected failures %v", failures)
+	}
+}
+
+func TestRegisterRepos(t *testing.T) {
+	basePath := writeProtoTree(t, map[string][]string{
+		"agenda/entry.proto":             {},
+		"payments/billing/billing.proto": {},
+		"payments-billing/legacy.proto":  {},
+	})
+	if err := ioutil.WriteFile(filepath.Join(basePath, "agenda", ProtoLangFileName), []byte("go\ntypescript\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name   string
+		naming config.RepositoryNaming
+		shared map[string]bool
+		err    string
+	}{
+		{"nested directories", config.RepositoryNaming{}, map[string]bool{}, "repository grpc-payments-billing-go is targeted by directories payments-billing, payments/billing"},
+		{"dedicated repositories", config.RepositoryNaming{Directories: map[string]string{"payments-billing": "legacy-billing-go"}}, map[string]bool{}, ""},
+		{"shared repository", config.RepositoryNaming{Languages: map[string]string{"go": "go-protos"}}, map[string]bool{"go-protos": true}, ""},
+		{"shared template of a single directory", config.RepositoryNaming{Languages: map[string]string{"typescript": "web-protos"}, Directories: map[string]string{"payments-billing": "legacy-billing-go"}}, map[string]bool{}, ""},
+		{"override on a dedicated repository", config.RepositoryNaming{Directories: map[string]string{"payments-billing": "grpc-agenda-go"}}, map[string]bool{}, "repository grpc-agenda-go is targeted by directories agenda, payments-billing"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gpm := NewManager(config.ServiceConfig{DefaultLanguage: "go", RepositoryNaming: tc.naming})
+			layers, err := gpm.loadLayers(basePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = gpm.registerRepos(basePath, layers)
+			if tc.err == "" && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Errorf("expected error containing %q, got %v", tc.err, err)
+			}
+			if tc.err == "" && !reflect.DeepEqual(gpm.sharedRepos, tc.shared) {
+				t.Errorf("expected shared repositories %v, got %v", tc.shared, gpm.sharedRepos)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package manager
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/lint"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Lint checks the protos of all the directories with the configured rule sets. The compatibility rules are not
+// evaluated as they require the previously published protos.
+func (gpm *GPM) Lint(basePath string) (*lint.Report, error) {
+	log.Debug().Msg("Linting protos")
+	if err := gpm.cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid configuration options: %w", err)
+	}
+	linter, err := lint.NewLinter(*gpm.cfg.Lint.Options())
+	if err != nil {
+		return nil, err
+	}
+	return gpm.lintDirectories(linter, basePath)
+}
+
+// lintDirectories checks the protos of all the directories found on the base path.
+func (gpm *GPM) lintDirectories(linter *lint.Linter, basePath string) (*lint.Report, error) {
+	entities, err := gpm.findEntities(basePath)
+	if err != nil {
+		return nil, err
+	}
+	result := lint.NewReport()
+	for _, entity := range entities {
+		report, err := linter.LintDirectory(basePath, entity)
+		if err != nil {
+			return nil, fmt.Errorf("cannot lint %s: %w", entity, err)
+		}
+		result.Merge(report)
+	}
+	return result, nil
+}
+
+// logIssues prints the issues of a lint report.
+func logIssues(logger zerolog.Logger, report *lint.Report) {
+	for _, issue := range report.Issues {
+		logger.Error().Str("file", path.Join(issue.Directory, issue.File)).Int("line", issue.Line).Str("rule", issue.Rule).Msg(issue.Message)
+	}
+}
+
+// lintGate checks the protos of all the directories before any code is generated, failing if any issue is found.
+func (gpm *GPM) lintGate(basePath string) error {
+	linter, err := lint.NewLinter(*gpm.cfg.Lint.Options())
+	if err != nil {
+		return err
+	}
+	gpm.linter = linter
+	report, err := gpm.lintDirectories(linter, basePath)
+	if err != nil {
+		return err
+	}
+	if report.HasIssues() {
+		logIssues(log.Logger, report)
+		return fmt.Errorf("lint failed with %d issue(s), use gpm lint to list them", len(report.Issues))
+	}
+	log.Info().Msg("protos linted without issues")
+	return nil
+}
+
+// lintCompatibility checks that the elements removed from the protos published on the target repository are
+// reserved. It is a noop unless the lint gate is enabled.
+func (gpm *GPM) lintCompatibility(logger zerolog.Logger, job *Job, tmpRepoDir string) error {
+	if gpm.linter == nil || !gpm.linter.Enabled(lint.Compatibility) {
+		return nil
+	}
+	report, err := gpm.linter.LintChanges(job.Entity, tmpRepoDir, job.TargetPath)
+	if err != nil {
+		return fmt.Errorf("cannot lint changes: %w", err)
+	}
+	if report.HasIssues() {
+		logIssues(logger, report)
+		return fmt.Errorf("lint failed with %d compatibility issue(s)", len(report.Issues))
+	}
+	return nil
+}
@@ -1,22 +1,24 @@
 package manager
 
 import (
-	"bufio"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path"
+	"strings"
+	"sync"
 
 	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/analysis"
 	"github.com/gpm-project/grpc-proto-manager/internal/pkg/files"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/graph"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/lint"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/manifest"
 	"github.com/gpm-project/grpc-proto-manager/internal/pkg/protos"
 	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
-// ProtoLangFileName defines the name of the file that specifies the target languages.
-const ProtoLangFileName = ".protolangs"
-
 // ExcludedDirs with the list of directories that will be excluded by default.
 var ExcludedDirs = []string{".git", ".github"}
 
@@ -25,11 +27,37 @@ type GPM struct {
 	cfg                config.ServiceConfig
 	repositoryProvider repo.Provider
 	protoGenerator     protos.Generator
+	// linter used to check the compatibility of the protos when the lint gate is enabled.
+	linter *lint.Linter
+	// dependencies with the graph of imports among entity directories.
+	dependencies *graph.DependencyGraph
+	// changedEntities with the entities whose protos have changed on this run.
+	changedEntities map[string]bool
+	// failedJobs with the jobs that could not be processed on this run.
+	failedJobs map[jobKey]bool
+	// repoLocks with the locks used to serialize the jobs targeting the same repository.
+	repoLocks map[string]*sync.Mutex
+	// sharedRepos with the repositories targeted by several directories on this run.
+	sharedRepos map[string]bool
+	// report with the outcome of the jobs of the run.
+	report *RunReport
+	// sourceCommitHash with the commit of the repository containing the protos, obtained once per run.
+	sourceCommitHash string
+	sourceCommitOnce sync.Once
+	// mutex protecting the state shared among concurrent jobs.
+	mutex sync.Mutex
 }
 
 // NewManager creates a new GPM entity.
 func NewManager(cfg config.ServiceConfig) *GPM {
-	return &GPM{cfg: cfg}
+	return &GPM{
+		cfg:             cfg,
+		changedEntities: make(map[string]bool, 0),
+		failedJobs:      make(map[jobKey]bool, 0),
+		repoLocks:       make(map[string]*sync.Mutex, 0),
+		sharedRepos:     make(map[string]bool, 0),
+		report:          NewRunReport(),
+	}
 }
 
 // SetupGeneratorConfig determines if the required parameters are present depending on the selected environment.
@@ -48,7 +76,8 @@ func (gpm *GPM) SetupGeneratorConfig() error {
 	case protos.DockerizedCmd:
 		return gpm.SetupDockerizedGeneration(repoProvider)
 	}
-	return nil
+	// Outside containerized environments, the system git configuration is used unless overridden.
+	return gpm.repositoryProvider.ConfigurePusher(gpm.cfg.RepositoryPusherUsername, gpm.cfg.RepositoryPusherEmail, gpm.cfg.RepositoryAccessToken)
 }
 
 // SetupDockerizedGeneration configures the different provider attending to the execution environment. In this
@@ -63,22 +92,36 @@ func (gpm *GPM) SetupDockerizedGeneration(repoProvider repo.RepositoryType) erro
 	return gpm.repositoryProvider.ConfigurePusher(gpm.cfg.RepositoryPusherUsername, gpm.cfg.RepositoryPusherEmail, gpm.cfg.RepositoryAccessToken)
 }
 
-// Run triggers the execution of the command.
-func (gpm *GPM) Run(basePath string) error {
+// Run triggers the execution of the command. If a report path is configured, the outcome of the jobs is written
+// even if the run fails.
+func (gpm *GPM) Run(basePath string) (err error) {
 	log.Debug().Msg("Launching GPM")
 	if err := gpm.cfg.IsValid(); err != nil {
 		log.Fatal().Err(err).Msg("invalid configuration options")
 	}
 	gpm.cfg.Print()
 	defer gpm.cleanup(basePath, gpm.cfg.TempPath)
+	defer func() {
+		if reportErr := gpm.writeReport(); reportErr != nil {
+			log.Error().Err(reportErr).Str("path", gpm.cfg.ReportPath).Msg("unable to write run report")
+			if err == nil {
+				err = reportErr
+			}
+		}
+	}()
 
-	repoProvider, err := repo.NewRepoProvider(gpm.cfg.RepositoryProvider)
+	repoProvider, err := repo.NewRepoProvider(gpm.cfg.RepositoryProvider, gpm.providerOptions())
 	if err != nil {
 		return err
 	}
 	gpm.repositoryProvider = repoProvider
+	if gpm.cfg.UsePullRequests() && !gpm.cfg.SkipPublish {
+		if _, err := gpm.pullRequestManager(); err != nil {
+			return err
+		}
+	}
 
-	protoGenerator, err := protos.NewGenerator(gpm.cfg.GeneratorName)
+	protoGenerator, err := protos.NewGenerator(gpm.cfg.GeneratorName, protos.GeneratorOptions{Local: gpm.cfg.LocalGenerator, Docker: gpm.cfg.DockerGenerator})
 	if err != nil {
 		return err
 	}
@@ -89,24 +132,91 @@ func (gpm *GPM) Run(basePath string) error {
 		return err
 	}
 
-	// Iterate over the project directories
+	if gpm.cfg.Lint.Gate {
+		if err := gpm.lintGate(basePath); err != nil {
+			return err
+		}
+	}
 
-	fileInfo, err := ioutil.ReadDir(basePath)
+	// Iterate over the project directories so that dependencies are processed before their dependents.
+	layers, err := gpm.loadLayers(basePath)
 	if err != nil {
 		return err
 	}
+	if err := gpm.registerRepos(basePath, layers); err != nil {
+		return err
+	}
 
-	for _, info := range fileInfo {
-		if info.IsDir() && !gpm.isExcluded(info.Name()) {
-			targetPath := path.Join(basePath, info.Name())
-			err := gpm.ProcessProtoDirectory(targetPath, info.Name())
-			if err != nil {
-				return err
-			}
+	return gpm.processLayers(basePath, layers, gpm.ProcessJob)
+}
+
+// processLayers processes the jobs of each layer of directories. Directories on the same layer do not depend on each
+// other, so their jobs are processed concurrently. In keep going mode, the remaining layers are processed after a
+// failure skipping the jobs that depend on the failed ones.
+func (gpm *GPM) processLayers(basePath string, layers [][]string, process func(job *Job) error) error {
+	runError := &RunError{Failures: make([]JobError, 0), Skipped: make([]JobError, 0)}
+	for index, layer := range layers {
+		jobs, err := gpm.buildJobs(basePath, layer)
+		if err != nil {
+			return err
+		}
+		jobs, skipped := gpm.skipFailedDependencies(jobs)
+		runError.Skipped = append(runError.Skipped, skipped...)
+		log.Debug().Int("layer", index).Strs("directories", layer).Int("jobs", len(jobs)).Msg("processing layer")
+		failures := gpm.runJobs(jobs, process)
+		runError.Failures = append(runError.Failures, failures...)
+		if len(failures) > 0 && !gpm.cfg.KeepGoing {
+			break
 		}
 	}
+	if len(runError.Failures) > 0 || len(runError.Skipped) > 0 {
+		gpm.logSummary(runError)
+		return runError
+	}
+	return nil
+}
 
-	return err
+// logSummary prints the jobs that could not be processed.
+func (gpm *GPM) logSummary(runError *RunError) {
+	for _, failure := range runError.Failures {
+		log.Error().Str("job", failure.Job).Err(failure.Err).Msg("failed")
+	}
+	for _, skipped := range runError.Skipped {
+		log.Warn().Str("job", skipped.Job).Err(skipped.Err).Msg("skipped")
+	}
+	succeeded := len(gpm.report.Jobs) - len(runError.Failures) - len(runError.Skipped)
+	log.Error().Int("succeeded", succeeded).Int("failed", len(runError.Failures)).Int("skipped", len(runError.Skipped)).Msg("run finished with failures")
+}
+
+// Report returns the outcome of the jobs processed so far.
+func (gpm *GPM) Report() *RunReport {
+	return gpm.report
+}
+
+// writeReport stores the run report on the configured path, if any.
+func (gpm *GPM) writeReport() error {
+	gpm.report.finish()
+	if gpm.cfg.ReportPath == "" {
+		return nil
+	}
+	if err := gpm.report.Write(gpm.cfg.ReportPath); err != nil {
+		return err
+	}
+	log.Info().Str("path", gpm.cfg.ReportPath).Int("jobs", len(gpm.report.Jobs)).Msg("run report written")
+	return nil
+}
+
+// providerOptions builds the options for the repository provider from the configuration.
+func (gpm *GPM) providerOptions() repo.ProviderOptions {
+	return repo.ProviderOptions{
+		Host:            gpm.cfg.RepositoryHost,
+		DeployTokenUser: gpm.cfg.RepositoryDeployTokenUser,
+		URLTemplate:     gpm.cfg.RepositoryURLTemplate,
+		GitBackend:      gpm.cfg.GitBackend,
+		ShallowClone:    gpm.cfg.ShallowClone,
+		DefaultBranch:   gpm.cfg.NewRepositorySettings.DefaultBranch,
+		SigningKey:      gpm.cfg.Signing.SigningKey(),
+	}
 }
 
 // cleanup function to delete temporal directories.
@@ -139,98 +249,393 @@ func (gpm *GPM) isExcluded(dirName string) bool {
 	return false
 }
 
-// LoadProtoLangs loads the file in each directory that defines the target languages. If none is found, the default one for
-// the project will be returned.
-func (gpm *GPM) LoadProtoLangs(targetPath string) ([]string, error) {
-	protolangsFile := path.Join(targetPath, ProtoLangFileName)
-	log.Debug().Str("path", protolangsFile).Msg("Protofile")
-	if _, err := os.Stat(protolangsFile); os.IsNotExist(err) {
-		log.Debug().Msg("using default language")
-		return []string{gpm.cfg.DefaultLanguage}, nil
+// getRepoName obtains the name of the target repository associated with a given job. The repository set on the
+// directory settings takes precedence over the repository naming.
+func (gpm *GPM) getRepoName(job *Job) string {
+	if job.Target.Repository != "" {
+		return job.Target.Repository
 	}
-	// read the file, no long lines are expected
-	readFile, err := os.Open(protolangsFile)
-	if err != nil {
-		return nil, err
-	}
-	scanner := bufio.NewScanner(readFile)
-	scanner.Split(bufio.ScanLines)
-	langs := make([]string, 0)
-	for scanner.Scan() {
-		langs = append(langs, scanner.Text())
+	return gpm.cfg.RepositoryNaming.RepoName(job.Entity, job.Language)
+}
+
+// isSharedRepo checks if the target repository of a job may be shared with other directories. Explicit repositories
+// and the ones named by templates without the entity placeholder are shared.
+func (gpm *GPM) isSharedRepo(job *Job) bool {
+	return job.Target.Repository != "" || gpm.cfg.RepositoryNaming.Shared(job.Entity, job.Language)
+}
+
+// codePath returns the path of the directory of the target repository storing the code of a job. Repositories
+// targeted by several directories store the code and the manifest of each directory on its own subdirectory, so
+// their files are compared and replaced independently.
+func (gpm *GPM) codePath(job *Job, repoName string) string {
+	if gpm.sharedRepos[repoName] {
+		return job.Entity
 	}
-	readFile.Close()
-	return langs, nil
+	return ""
 }
 
-// getRepoName obtains the name of the target repository associated with a given directory and language
-func (gpm *GPM) getRepoName(directoryName string, language string) string {
-	return fmt.Sprintf("grpc-%s-%s", directoryName, language)
+// createRepoIfNotExists creates the target repository through the repository provider if it does not exist.
+func (gpm *GPM) createRepoIfNotExists(repoName string) error {
+	creator, supported := gpm.repositoryProvider.(repo.Creator)
+	if !supported {
+		return fmt.Errorf("repository provider %s does not support creating repositories", gpm.cfg.RepositoryProvider)
+	}
+	settings := gpm.cfg.NewRepositorySettings
+	if settings.Description == "" {
+		settings.Description = fmt.Sprintf("gRPC code generated by GPM for %s", repoName)
+	}
+	if err := creator.CreateRepoIfNotExists(gpm.cfg.RepositoryOrganization, repoName, settings); err != nil {
+		return fmt.Errorf("cannot create target repository %s: %w", repoName, err)
+	}
+	return nil
 }
 
-// ProcessProtoDirectory is the main function to compile, calculate the difference in code with the previous version, and commit the changes.
+// ProcessProtoDirectory processes all the target languages of a proto directory. The name is the path of the
+// directory relative to the base path.
 func (gpm *GPM) ProcessProtoDirectory(targetPath string, name string) error {
 	log.Info().Str("path", targetPath).Msg("processing proto directory")
+	basePath := strings.TrimSuffix(path.Clean(targetPath), name)
+	jobs, err := gpm.buildJobs(basePath, []string{name})
+	if err != nil {
+		return err
+	}
+	if failures := gpm.runJobs(jobs, gpm.ProcessJob); len(failures) > 0 {
+		return &RunError{Failures: failures}
+	}
+	return nil
+}
+
+// ProcessJob is the main function to compile, calculate the difference in code with the previous version, and commit the changes
+// for a given directory and language. The outcome of the job is registered on the run report.
+func (gpm *GPM) ProcessJob(job *Job) error {
+	result := gpm.report.startJob(job)
+	err := gpm.processJob(job, result)
+	gpm.report.finishJob(result, err)
+	return err
+}
+
+// processJob generates and publishes the code of a job, filling its report.
+func (gpm *GPM) processJob(job *Job, result *JobReport) error {
+	logger := gpm.jobLogger(job.Entity, job.Language)
+	logger.Info().Str("path", job.TargetPath).Msg("processing proto directory")
 
-	targetLanguages, err := gpm.LoadProtoLangs(targetPath)
+	repoName := gpm.getRepoName(job)
+	result.Repository = repoName
+	repoURL, err := gpm.repositoryProvider.GetRepoURL(gpm.cfg.RepositoryOrganization, repoName)
 	if err != nil {
+		return fmt.Errorf("cannot determine repository URL: %w", err)
+	}
+	if err := gpm.prepareGoTarget(logger, job, repoURL); err != nil {
 		return err
 	}
-	log.Debug().Interface("languages", targetLanguages).Msg("target")
-	for _, language := range targetLanguages {
-		repoName := gpm.getRepoName(name, language)
-		repoURL, err := gpm.repositoryProvider.GetRepoURL(gpm.cfg.RepositoryOrganization, repoName)
-		if err != nil {
-			return fmt.Errorf("cannot determine repository URL: %w", err)
+	// Jobs targeting the same repository are serialized.
+	lock := gpm.repoLock(repoName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if gpm.cfg.CreateMissingRepos {
+		if err := gpm.createRepoIfNotExists(repoName); err != nil {
+			return err
 		}
-		// First step is to clone the generated proto repo to compare the files. Notice that generated files have timestamped data,
-		// and diff is not recommended on that data.
-		tmpRepoDir := path.Join(gpm.cfg.TempPath, repoName)
-		err = gpm.repositoryProvider.Clone(repoURL, tmpRepoDir)
-		if err != nil {
-			return fmt.Errorf("cannot clone target repository %s to calculate diff: %w", repoURL, err)
+	}
+	// First step is to clone the generated proto repo to compare the files. Notice that generated files have timestamped data,
+	// and diff is not recommended on that data.
+	tmpRepoDir, release, err := gpm.checkoutRepo(job, repoName, repoURL)
+	if err != nil {
+		return fmt.Errorf("cannot clone target repository %s to calculate diff: %w", maskURL(repoURL), err)
+	}
+	defer release()
+
+	// Versions published through pull requests are tagged once merged.
+	if gpm.cfg.UsePullRequests() && !gpm.cfg.SkipPublish {
+		if _, err := gpm.tagMergedPullRequests(logger, repoName, tmpRepoDir); err != nil {
+			return err
 		}
+	}
 
-		// Now compare the content.
-		equal, err := files.CompareDirectoriesAreEqual(".proto", targetPath, tmpRepoDir)
+	// Now compare the inputs of the generation with the ones of the published code.
+	current, reasons, err := gpm.detectChanges(logger, job, path.Join(tmpRepoDir, gpm.codePath(job, repoName)))
+	if err != nil {
+		return err
+	}
+	result.Reasons = reasons
+	regenerate := len(reasons) > 0
+	if changedDependencies := gpm.changedDependencies(job.Entity); !regenerate && len(changedDependencies) > 0 {
+		logger.Info().Str("repo", repoName).Strs("dependencies", changedDependencies).Msg("dependencies changed, forcing generation")
+		regenerate = true
+	}
+	if !regenerate {
+		logger.Info().Str("repo", repoName).Msg("no changes detected, skipping generation")
+		if version, err := gpm.repositoryProvider.GetLastVersion(tmpRepoDir); err == nil {
+			result.PreviousVersion = version.String()
+		} else {
+			logger.Warn().Err(err).Msg("cannot obtain current version")
+		}
+		return nil
+	}
+	// Pre-releases are compared with the default branch, so they are only published again if the inputs changed
+	// since the latest one.
+	if gpm.cfg.PreRelease != "" {
+		published, err := gpm.publishedPreRelease(logger, tmpRepoDir, gpm.codePath(job, repoName), current)
 		if err != nil {
-			return fmt.Errorf("cannot compare files: %w", err)
+			return err
+		}
+		if published != nil {
+			logger.Info().Str("repo", repoName).Str("preRelease", published.String()).Msg("no changes since the latest pre-release, skipping generation")
+			result.PreviousVersion = published.String()
+			return nil
+		}
+	}
+	gpm.markChanged(job.Entity)
+	result.Changed = true
+	// If there is a change, generate the proto stubs on the given language
+	err = gpm.OrchestrateGeneration(job, tmpRepoDir, current, result)
+	if err != nil {
+		return fmt.Errorf("cannot generate proto code: %w", err)
+	}
+	return nil
+}
+
+// checkoutRepo obtains an up to date copy of the target repository of a job. If a cache directory is configured,
+// the copy is kept across runs and updated incrementally, otherwise a temporal copy is cloned on a directory
+// owned by the job. The returned function must be called once the copy is no longer needed. Callers must hold
+// the lock of the repository.
+func (gpm *GPM) checkoutRepo(job *Job, repoName string, repoURL string) (string, func(), error) {
+	if gpm.cfg.CacheDir == "" {
+		tmpRepoDir := path.Join(gpm.cfg.TempPath, job.workDir(), repoName)
+		release := func() {
+			// Remove the temporal directory once finished
+			os.RemoveAll(path.Dir(tmpRepoDir))
+		}
+		if err := gpm.repositoryProvider.Clone(repoURL, tmpRepoDir); err != nil {
+			release()
+			return "", nil, err
+		}
+		return tmpRepoDir, release, nil
+	}
+
+	logger := gpm.jobLogger(job.Entity, job.Language)
+	cachedRepoDir := path.Join(gpm.cfg.CacheDir, gpm.cfg.RepositoryOrganization, repoName)
+	release := func() {}
+	if _, err := os.Stat(cachedRepoDir); err == nil {
+		err := gpm.repositoryProvider.Update(repoURL, cachedRepoDir)
+		if err == nil {
+			logger.Debug().Str("path", cachedRepoDir).Msg("cached repository updated")
+			return cachedRepoDir, release, nil
+		}
+		// Clone it again if the cached copy cannot be reused.
+		logger.Warn().Err(err).Str("path", cachedRepoDir).Msg("cannot update cached repository, cloning it again")
+		if err := os.RemoveAll(cachedRepoDir); err != nil {
+			return "", nil, err
 		}
-		if !equal {
-			// If there is a change, generate the proto stubs on the given languages
-			err := gpm.OrchestrateGeneration(name, tmpRepoDir, language)
-			if err != nil {
-				return fmt.Errorf("cannot generate proto code: %w", err)
+	}
+	if err := gpm.repositoryProvider.Clone(repoURL, cachedRepoDir); err != nil {
+		os.RemoveAll(cachedRepoDir)
+		return "", nil, err
+	}
+	return cachedRepoDir, release, nil
+}
+
+// analyzeChanges compares the protos previously published on the target repository with the new ones and classifies
+// the differences to determine the required version bump.
+func (gpm *GPM) analyzeChanges(logger zerolog.Logger, sourcePath string, tmpRepoDir string) (*analysis.Report, error) {
+	report, err := analysis.CompareDirectories(tmpRepoDir, sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range report.Changes {
+		logger.Info().Str("changeLevel", change.Level.String()).Str("element", change.Element).Msg(change.Description)
+	}
+	logger.Info().Str("bump", report.Bump.String()).Msg(report.Summary())
+	return report, nil
+}
+
+// nextVersion calculates the version to be published attending to the classified changes. Changes that do not
+// alter the definitions are published as a patch as some file has changed anyway.
+func (gpm *GPM) nextVersion(version *repo.Version, report *analysis.Report) {
+	switch report.Bump {
+	case analysis.MajorChange:
+		version.IncrementMajor()
+	case analysis.MinorChange:
+		version.IncrementMinor()
+	default:
+		version.IncrementPatch()
+	}
+}
+
+// buildManifest calculates the manifest with the inputs of the generation of a job: the files of the directory, the
+// protos of its dependencies, the generator and the settings of the language.
+func (gpm *GPM) buildManifest(job *Job) (*manifest.Manifest, error) {
+	identity, err := gpm.protoGenerator.Identity(job.Target)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain generator identity: %w", err)
+	}
+	result := manifest.NewManifest(identity, job.Target.String()+gpm.packagingSettings(job))
+	// The languages are part of the settings, so changes on other languages are ignored.
+	if err := result.AddSources(job.TargetPath, hasProtoLangs); err != nil {
+		return nil, err
+	}
+	if gpm.dependencies != nil {
+		for _, dependency := range gpm.dependencies.Dependencies(job.Entity) {
+			if err := result.AddImports(dependency, path.Join(gpm.cfg.ProjectPath, dependency)); err != nil {
+				return nil, err
 			}
-		} else {
-			log.Info().Str("repo", repoName).Msg("no changes detected, skipping generation")
 		}
-		// Remove the temporal directory
-		_ = os.RemoveAll(tmpRepoDir)
 	}
+	result.Seal()
+	return result, nil
+}
 
-	return nil
+// detectChanges compares the manifest of a job with the one stored on the directory of the target repository
+// containing its code, returning the current manifest and the reasons to generate the code, if any. Code published
+// without a manifest is compared using its proto files.
+func (gpm *GPM) detectChanges(logger zerolog.Logger, job *Job, codeDir string) (*manifest.Manifest, []string, error) {
+	current, err := gpm.buildManifest(job)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot build manifest: %w", err)
+	}
+	previous, err := manifest.Read(codeDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if previous == nil {
+		logger.Debug().Msg("no manifest found on target repository, comparing proto files")
+		equal, err := files.CompareDirectoriesAreEqual(".proto", job.TargetPath, codeDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot compare files: %w", err)
+		}
+		if equal {
+			return current, []string{}, nil
+		}
+		return current, []string{"proto files changed"}, nil
+	}
+	reasons := current.Differences(previous)
+	for _, reason := range reasons {
+		logger.Info().Msg(reason)
+	}
+	return current, reasons, nil
+}
+
+// generate generates the code of a job on a staging directory and copies it into the directory of the target
+// repository containing its code, removing the files written by the previous generation that are no longer generated.
+// The written files are registered on the manifest, which is stored along with the code. The go.mod file on the root
+// of the go repositories is maintained as well.
+func (gpm *GPM) generate(job *Job, tmpRepoDir string, codeDir string, current *manifest.Manifest) error {
+	stagingDir := path.Join(gpm.cfg.TempPath, "generated", job.workDir())
+	if err := os.RemoveAll(stagingDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return fmt.Errorf("cannot create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	if err := gpm.protoGenerator.Generate(gpm.cfg.ProjectPath, job.Entity, stagingDir, job.Target); err != nil {
+		return err
+	}
+	written, err := files.CopyTree(stagingDir, codeDir)
+	if err != nil {
+		return fmt.Errorf("cannot copy generated files: %w", err)
+	}
+	current.Files = written
+	previous, err := manifest.Read(codeDir)
+	if err != nil {
+		return err
+	}
+	if previous != nil {
+		for _, stale := range current.StaleFiles(previous) {
+			if err := os.Remove(path.Join(codeDir, stale)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("cannot remove stale file %s: %w", stale, err)
+			}
+		}
+	}
+	if err := gpm.updateGoModule(gpm.jobLogger(job.Entity, job.Language), job, tmpRepoDir); err != nil {
+		return err
+	}
+	return current.Write(codeDir)
 }
 
 // OrchestrateGeneration orchestrates the generation of the protos.
-func (gpm *GPM) OrchestrateGeneration(name string, tmpRepoDir string, language string) error {
+func (gpm *GPM) OrchestrateGeneration(job *Job, tmpRepoDir string, current *manifest.Manifest, result *JobReport) error {
+	name := job.Entity
+	logger := gpm.jobLogger(name, job.Language)
+	repoName := gpm.getRepoName(job)
+	codeDir := path.Join(tmpRepoDir, gpm.codePath(job, repoName))
+	// Classify the changes before the generated code overwrites the previous sources.
+	report, err := gpm.analyzeChanges(logger, path.Join(gpm.cfg.ProjectPath, name), codeDir)
+	if err != nil {
+		return fmt.Errorf("cannot analyze proto changes: %w", err)
+	}
+	changedFiles, err := files.ListDifferences(".proto", path.Join(gpm.cfg.ProjectPath, name), codeDir)
+	if err != nil {
+		return fmt.Errorf("cannot compare files: %w", err)
+	}
+	if err := gpm.lintCompatibility(logger, job, codeDir); err != nil {
+		return err
+	}
 	// Generate the code
-	err := gpm.protoGenerator.Generate(gpm.cfg.ProjectPath, name, tmpRepoDir, language)
+	err = gpm.generate(job, tmpRepoDir, codeDir, current)
 	if err != nil {
 		return err
 	}
+	if resolver, ok := gpm.protoGenerator.(protos.ImageResolver); ok {
+		image, err := resolver.ResolvedImage(job.Target)
+		if err != nil {
+			return err
+		}
+		logger.Info().Str("image", image).Msg("code generated")
+		result.GeneratorImage = image
+	}
 	// Calculate version
 	version, err := gpm.repositoryProvider.GetLastVersion(tmpRepoDir)
 	if err != nil {
 		return err
 	}
-	log.Debug().Str("previous", version.String()).Msg("version")
+	logger.Debug().Str("previous", version.String()).Msg("version")
+	result.PreviousVersion = version.String()
+	gpm.nextVersion(version, report)
+	if gpm.cfg.PreRelease != "" {
+		if version, err = gpm.preReleaseVersion(tmpRepoDir, version); err != nil {
+			return err
+		}
+	}
+	if err := gpm.writePackageManifests(logger, job, repoName, codeDir, version, current.Files); err != nil {
+		return fmt.Errorf("cannot write package manifests: %w", err)
+	}
 	// Publish if required
 	if gpm.cfg.SkipPublish {
-		log.Warn().Str("repo", tmpRepoDir).Msg("changes will not be published")
+		logger.Warn().Str("repo", tmpRepoDir).Str("newVersion", version.String()).Msg("changes will not be published")
 		return nil
 	}
-	version.IncrementMinor()
-	log.Info().Str("newVersion", version.String()).Str("repo", gpm.getRepoName(name, language)).Msg("publishing new version")
-	return gpm.repositoryProvider.Publish(tmpRepoDir, version)
+	logger.Info().Str("newVersion", version.String()).Str("bump", report.Bump.String()).Str("repo", repoName).Msg("publishing new version")
+	data := gpm.messageData(job, repoName, version, report, changedFiles, result)
+	messages, err := gpm.publishMessages(data)
+	if err != nil {
+		return err
+	}
+	if gpm.cfg.UsePullRequests() {
+		return gpm.publishPullRequest(logger, job, repoName, tmpRepoDir, version, data, messages, result)
+	}
+	if gpm.cfg.PreRelease != "" {
+		if err := gpm.publishPreRelease(logger, repoName, tmpRepoDir, version, messages, result); err != nil {
+			return err
+		}
+	} else {
+		commit, err := gpm.repositoryProvider.Publish(tmpRepoDir, version, messages)
+		if err != nil {
+			return err
+		}
+		if commit != "" {
+			result.Published = true
+			result.NewVersion = version.String()
+			result.Commit = commit
+		}
+	}
+	if result.Published {
+		// The go module covers the whole repository.
+		if job.Language == "go" {
+			codeDir = tmpRepoDir
+		}
+		return gpm.publishArtifacts(logger, job, repoName, codeDir, version, result)
+	}
+	return nil
 }
@@ -0,0 +1,234 @@
+package manager
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/manifest"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/protos"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// captureLogs redirects the global logger to a buffer for the duration of a test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	buffer := new(bytes.Buffer)
+	previous := log.Logger
+	log.Logger = zerolog.New(buffer)
+	t.Cleanup(func() { log.Logger = previous })
+	return buffer
+}
+
+// writeLayersFixture creates a set of entities generating go code where billing and ledger depend on common, api
+// depends on both, and standalone has no dependencies.
+func writeLayersFixture(t *testing.T) string {
+	t.Helper()
+	basePath := writeProtoTree(t, map[string][]string{
+		"common/types.proto":      {},
+		"standalone/report.proto": {},
+		"billing/billing.proto":   {"common/types.proto"},
+		"ledger/ledger.proto":     {"common/types.proto"},
+		"api/api.proto":           {"billing/billing.proto", "ledger/ledger.proto"},
+	})
+	for _, entity := range []string{"common", "standalone", "billing", "ledger", "api"} {
+		if err := ioutil.WriteFile(filepath.Join(basePath, entity, ProtoLangFileName), []byte("go\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return basePath
+}
+
+func TestProcessLayersSkipsDependents(t *testing.T) {
+	testCases := []struct {
+		name      string
+		keepGoing bool
+		processed []string
+		skipped   []string
+	}{
+		{"keep going", true, []string{"common/go", "standalone/go"}, []string{"api/go", "billing/go", "ledger/go"}},
+		{"stop on failure", false, []string{"common/go", "standalone/go"}, []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			basePath := writeLayersFixture(t)
+			gpm := NewManager(config.ServiceConfig{KeepGoing: tc.keepGoing, Parallelism: 2})
+			layers, err := gpm.loadLayers(basePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			processed := make([]string, 0)
+			var mutex sync.Mutex
+			err = gpm.processLayers(basePath, layers, func(job *Job) error {
+				mutex.Lock()
+				processed = append(processed, job.String())
+				mutex.Unlock()
+				if job.Entity == "common" {
+					return errors.New("generation failed")
+				}
+				return nil
+			})
+
+			var runError *RunError
+			if !errors.As(err, &runError) {
+				t.Fatalf("expected run error, got %v", err)
+			}
+			sort.Strings(processed)
+			if !reflect.DeepEqual(processed, tc.processed) {
+				t.Errorf("expected processed jobs %v, got %v", tc.processed, processed)
+			}
+			if len(runError.Failures) != 1 || runError.Failures[0].Job != "common/go" {
+				t.Errorf("unexpected failures %+v", runError.Failures)
+			}
+			skipped := make([]string, 0, len(runError.Skipped))
+			for _, jobError := range runError.Skipped {
+				skipped = append(skipped, jobError.Job)
+			}
+			sort.Strings(skipped)
+			if !reflect.DeepEqual(skipped, tc.skipped) {
+				t.Errorf("expected skipped jobs %v, got %v", tc.skipped, skipped)
+			}
+			if !strings.Contains(logs.String(), "run finished with failures") {
+				t.Errorf("expected the summary to be logged, got %s", logs.String())
+			}
+		})
+	}
+}
+
+// copyGenerator is a generator that publishes the protos of a directory along with a python module per proto.
+type copyGenerator struct {
+	fakeGenerator
+}
+
+// Generate copies the protos and writes an empty module for each one.
+func (cg *copyGenerator) Generate(rootPath string, targetName string, generatedPath string, target protos.Target) error {
+	protoFiles, err := filepath.Glob(filepath.Join(rootPath, targetName, "*.proto"))
+	if err != nil {
+		return err
+	}
+	for _, protoFile := range protoFiles {
+		content, err := ioutil.ReadFile(protoFile)
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(protoFile)
+		if err := ioutil.WriteFile(filepath.Join(generatedPath, name), content, 0644); err != nil {
+			return err
+		}
+		module := strings.TrimSuffix(name, ".proto") + "_pb2.py"
+		if err := ioutil.WriteFile(filepath.Join(generatedPath, module), []byte("# generated\n"), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processShared processes all the directories of a project whose python code is published on a shared repository,
+// returning the reports of the jobs.
+func processShared(t *testing.T, basePath string, remotesPath string) []*JobReport {
+	t.Helper()
+	cfg := config.ServiceConfig{
+		ProjectPath:            basePath,
+		TempPath:               t.TempDir(),
+		RepositoryProvider:     "git",
+		RepositoryURLTemplate:  repo.FileURLPrefix + remotesPath + "/{{repo}}.git",
+		RepositoryOrganization: "gpm",
+		DefaultLanguage:        "python",
+		Parallelism:            1,
+		RepositoryNaming:       config.RepositoryNaming{Languages: map[string]string{"python": "web-protos"}},
+	}
+	gpm := NewManager(cfg)
+	provider, err := repo.NewRepoProvider(cfg.RepositoryProvider, gpm.providerOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.ConfigurePusher("gpm", "gpm@example.com", ""); err != nil {
+		t.Fatal(err)
+	}
+	gpm.repositoryProvider = provider
+	gpm.protoGenerator = &copyGenerator{}
+	layers, err := gpm.loadLayers(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gpm.registerRepos(basePath, layers); err != nil {
+		t.Fatal(err)
+	}
+	if err := gpm.processLayers(basePath, layers, gpm.ProcessJob); err != nil {
+		t.Fatal(err)
+	}
+	return gpm.report.Jobs
+}
+
+// checkRepoFiles checks the presence of a set of files on the latest commit of a repository.
+func checkRepoFiles(t *testing.T, remotePath string, present []string, absent []string) {
+	t.Helper()
+	output, err := exec.Command("git", "--git-dir", remotePath, "ls-tree", "-r", "--name-only", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := make(map[string]bool, 0)
+	for _, name := range strings.Fields(string(output)) {
+		published[name] = true
+	}
+	for _, name := range present {
+		if !published[name] {
+			t.Errorf("expected %s on the repository, got %s", name, output)
+		}
+	}
+	for _, name := range absent {
+		if published[name] {
+			t.Errorf("unexpected %s on the repository", name)
+		}
+	}
+}
+
+func TestProcessJobSharedRepository(t *testing.T) {
+	basePath := writeProtoTree(t, map[string][]string{
+		"agenda/entry.proto":    {},
+		"billing/invoice.proto": {},
+	})
+	remotesPath := t.TempDir()
+	remotePath := filepath.Join(remotesPath, "web-protos.git")
+	writeRemote(t, remotePath, "v1.0.0")
+
+	reports := processShared(t, basePath, remotesPath)
+	// The files of other directories are not considered removed protos, so both versions are patches.
+	versions := make([]string, 0, len(reports))
+	for _, report := range reports {
+		versions = append(versions, report.Directory+" "+report.NewVersion)
+	}
+	if expected := []string{"agenda v1.0.1", "billing v1.0.2"}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("expected versions %v, got %v", expected, versions)
+	}
+	checkRepoFiles(t, remotePath, []string{
+		"README.md",
+		"agenda/" + manifest.FileName, "agenda/entry.proto", "agenda/entry_pb2.py",
+		"billing/" + manifest.FileName, "billing/invoice.proto", "billing/invoice_pb2.py",
+	}, []string{manifest.FileName, "entry_pb2.py", "invoice_pb2.py"})
+
+	// Only the stale files of the changed directory are removed.
+	if err := os.Rename(filepath.Join(basePath, "agenda", "entry.proto"), filepath.Join(basePath, "agenda", "calendar.proto")); err != nil {
+		t.Fatal(err)
+	}
+	reports = processShared(t, basePath, remotesPath)
+	if len(reports) != 2 || reports[0].NewVersion != "v1.0.3" || reports[1].Changed {
+		t.Errorf("unexpected reports %+v, %+v", reports[0], reports[1])
+	}
+	checkRepoFiles(t, remotePath, []string{
+		"agenda/" + manifest.FileName, "agenda/calendar.proto", "agenda/calendar_pb2.py",
+		"billing/" + manifest.FileName, "billing/invoice.proto", "billing/invoice_pb2.py",
+	}, []string{"agenda/entry.proto", "agenda/entry_pb2.py"})
+}
@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/analysis"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/files"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+	"github.com/rs/zerolog/log"
+)
+
+// sourceCommit returns the commit of the repository containing the protos. The configured value takes precedence
+// over the one obtained from the project path.
+func (gpm *GPM) sourceCommit() string {
+	gpm.sourceCommitOnce.Do(func() {
+		gpm.sourceCommitHash = gpm.cfg.SourceCommit
+		if gpm.sourceCommitHash != "" {
+			return
+		}
+		gpm.sourceCommitHash = config.UnknownValue
+		backend, err := repo.NewGitBackend(gpm.cfg.GitBackend)
+		if err != nil {
+			log.Warn().Err(err).Msg("cannot determine source commit")
+			return
+		}
+		commit, err := backend.HeadCommit(gpm.cfg.ProjectPath)
+		if err != nil {
+			log.Warn().Err(err).Str("path", gpm.cfg.ProjectPath).Msg("cannot determine source commit, use --sourceCommit to set it")
+			return
+		}
+		gpm.sourceCommitHash = commit
+	})
+	return gpm.sourceCommitHash
+}
+
+// messageData gathers the information available to the message templates of a new version.
+func (gpm *GPM) messageData(job *Job, repoName string, version *repo.Version, report *analysis.Report, changedFiles []files.FileDiff, result *JobReport) config.MessageData {
+	data := config.MessageData{
+		Directory:       job.Entity,
+		Language:        job.Language,
+		Repository:      repoName,
+		Version:         version.String(),
+		PreviousVersion: result.PreviousVersion,
+		Bump:            report.Bump.String(),
+		Summary:         report.Summary(),
+		Changes:         make([]string, 0, len(report.Changes)),
+		ChangedFiles:    make([]string, 0, len(changedFiles)),
+		Reasons:         result.Reasons,
+		SourceCommit:    gpm.sourceCommit(),
+		GPMVersion:      gpm.cfg.Version,
+		GPMCommit:       gpm.cfg.Commit,
+		GeneratorImage:  result.GeneratorImage,
+	}
+	for _, change := range report.Changes {
+		data.Changes = append(data.Changes, change.String())
+	}
+	for _, fileDiff := range changedFiles {
+		data.ChangedFiles = append(data.ChangedFiles, fmt.Sprintf("%s %s", fileDiff.Status, fileDiff.Name))
+	}
+	if data.GPMVersion == "" {
+		data.GPMVersion = config.UnknownValue
+	}
+	if data.GPMCommit == "" {
+		data.GPMCommit = config.UnknownValue
+	}
+	return data
+}
+
+// publishMessages renders the commit message and tag annotation of a new version.
+func (gpm *GPM) publishMessages(data config.MessageData) (repo.PublishMessages, error) {
+	commit, tag, err := gpm.cfg.MessageTemplates.Render(data)
+	if err != nil {
+		return repo.PublishMessages{}, err
+	}
+	return repo.PublishMessages{Commit: commit, Tag: tag}, nil
+}
@@ -0,0 +1,59 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/packaging"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+	"github.com/rs/zerolog"
+)
+
+// packagingSettings returns the settings of the manifest of a job that are part of the inputs of the generation.
+func (gpm *GPM) packagingSettings(job *Job) string {
+	if fingerprint := gpm.cfg.Packaging.Fingerprint(job.Language); fingerprint != "" {
+		return fmt.Sprintf(" packaging=%s", fingerprint)
+	}
+	return ""
+}
+
+// writePackageManifests writes the package manifests of a job on the target repository so the generated code can
+// be installed by version. The version of the manifests is the one being published.
+func (gpm *GPM) writePackageManifests(logger zerolog.Logger, job *Job, repoName string, tmpRepoDir string, version *repo.Version, generated []string) error {
+	if gpm.cfg.Packaging.Skip {
+		return nil
+	}
+	templates := packaging.Templates(job.Language, gpm.cfg.Packaging.Templates)
+	if len(templates) == 0 {
+		return nil
+	}
+	group := gpm.cfg.Packaging.Group
+	if group == "" {
+		group = strings.ToLower(gpm.cfg.RepositoryOrganization)
+	}
+	data := packaging.Data{
+		Name:        repoName,
+		Group:       group,
+		Directory:   job.Entity,
+		Language:    job.Language,
+		Description: fmt.Sprintf("%s code generated from the %s protos", job.Language, job.Entity),
+		Version:     packaging.Version(job.Language, version),
+		Tag:         version.String(),
+		Files:       generated,
+		Modules:     []string{},
+		Packages:    []string{},
+	}
+	if job.Language == "python" {
+		modules, packages, err := packaging.PythonLayout(tmpRepoDir, generated)
+		if err != nil {
+			return err
+		}
+		data.Modules, data.Packages = modules, packages
+	}
+	manifests, err := packaging.Render(templates, data)
+	if err != nil {
+		return err
+	}
+	logger.Debug().Str("version", data.Version).Int("manifests", len(manifests)).Msg("writing package manifests")
+	return packaging.Write(tmpRepoDir, manifests)
+}
@@ -0,0 +1,284 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/files"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/protos"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+	"github.com/rs/zerolog/log"
+)
+
+// PlanEntry structure with the expected outcome of a job.
+type PlanEntry struct {
+	// Directory containing the protos.
+	Directory string `json:"directory"`
+	// Language to be generated.
+	Language string `json:"language"`
+	// Repository with the name of the target repository.
+	Repository string `json:"repository"`
+	// RepositoryURL with the URL of the target repository. Credentials are masked.
+	RepositoryURL string `json:"repositoryURL"`
+	// Changed determines if the code would be generated and published.
+	Changed bool `json:"changed"`
+	// Reasons with the inputs of the generation that changed since the published version.
+	Reasons []string `json:"reasons,omitempty"`
+	// ChangedDependencies with the changed directories that force the generation.
+	ChangedDependencies []string `json:"changedDependencies,omitempty"`
+	// Files with the proto files that differ from the published ones.
+	Files []files.FileDiff `json:"files,omitempty"`
+	// CurrentVersion with the latest published version.
+	CurrentVersion string `json:"currentVersion,omitempty"`
+	// NextVersion with the version that would be published.
+	NextVersion string `json:"nextVersion,omitempty"`
+	// Bump with the type of version increment.
+	Bump string `json:"bump,omitempty"`
+	// Changes with the classified differences among the protos.
+	Changes []string `json:"changes,omitempty"`
+	// Error found while planning the job, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Plan structure with the expected outcome of a generation run.
+type Plan struct {
+	// Entries with one element per directory and language.
+	Entries []*PlanEntry `json:"entries"`
+	mutex   sync.Mutex
+}
+
+// add registers a new entry on the plan.
+func (p *Plan) add(entry *PlanEntry) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.Entries = append(p.Entries, entry)
+}
+
+// HasErrors checks if any of the entries could not be planned.
+func (p *Plan) HasErrors() bool {
+	for _, entry := range p.Entries {
+		if entry.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON representation of the plan.
+func (p *Plan) JSON() (string, error) {
+	content, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// Text returns a human readable representation of the plan.
+func (p *Plan) Text() string {
+	var sb strings.Builder
+	changed := 0
+	for _, entry := range p.Entries {
+		fmt.Fprintf(&sb, "%s/%s -> %s (%s)\n", entry.Directory, entry.Language, entry.Repository, entry.RepositoryURL)
+		switch {
+		case entry.Error != "":
+			fmt.Fprintf(&sb, "  error: %s\n", entry.Error)
+		case !entry.Changed:
+			fmt.Fprintf(&sb, "  no changes, current version %s\n", entry.CurrentVersion)
+		default:
+			changed++
+			fmt.Fprintf(&sb, "  version: %s -> %s (%s)\n", entry.CurrentVersion, entry.NextVersion, entry.Bump)
+			if len(entry.Reasons) > 0 {
+				fmt.Fprintf(&sb, "  reasons: %s\n", strings.Join(entry.Reasons, ", "))
+			}
+			if len(entry.ChangedDependencies) > 0 {
+				fmt.Fprintf(&sb, "  changed dependencies: %s\n", strings.Join(entry.ChangedDependencies, ", "))
+			}
+		}
+		if len(entry.Files) > 0 {
+			sb.WriteString("  files:\n")
+			for _, fileDiff := range entry.Files {
+				fmt.Fprintf(&sb, "    %-8s %s\n", fileDiff.Status, fileDiff.Name)
+			}
+		}
+		if entry.Changed && len(entry.Changes) > 0 {
+			sb.WriteString("  changes:\n")
+			for _, change := range entry.Changes {
+				fmt.Fprintf(&sb, "    %s\n", change)
+			}
+		}
+	}
+	fmt.Fprintf(&sb, "%d of %d repositories would be updated\n", changed, len(p.Entries))
+	return sb.String()
+}
+
+// maskURL hides the credentials that may be part of a repository URL.
+func maskURL(repoURL string) string {
+	parsed, err := url.Parse(repoURL)
+	if err != nil || parsed.User == nil || parsed.Scheme == "" {
+		return repoURL
+	}
+	if _, hasPassword := parsed.User.Password(); hasPassword {
+		parsed.User = url.UserPassword(parsed.User.Username(), "xxxxx")
+	} else if parsed.Scheme != "ssh" {
+		parsed.User = url.User("xxxxx")
+	}
+	return parsed.String()
+}
+
+// Plan determines for each directory and language whether the protos have changed and which version would be
+// published, without generating nor publishing any code.
+func (gpm *GPM) Plan(basePath string) (*Plan, error) {
+	log.Debug().Msg("Planning GPM execution")
+	if err := gpm.cfg.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid configuration options: %w", err)
+	}
+	defer gpm.cleanup(basePath, gpm.cfg.TempPath)
+
+	repoProvider, err := repo.NewRepoProvider(gpm.cfg.RepositoryProvider, gpm.providerOptions())
+	if err != nil {
+		return nil, err
+	}
+	gpm.repositoryProvider = repoProvider
+	if err := gpm.repositoryProvider.ConfigurePusher(gpm.cfg.RepositoryPusherUsername, gpm.cfg.RepositoryPusherEmail, gpm.cfg.RepositoryAccessToken); err != nil {
+		return nil, err
+	}
+	// The generator is not executed, but its identity is compared with the one of the published code.
+	protoGenerator, err := protos.NewGenerator(gpm.cfg.GeneratorName, protos.GeneratorOptions{Local: gpm.cfg.LocalGenerator, Docker: gpm.cfg.DockerGenerator})
+	if err != nil {
+		return nil, err
+	}
+	gpm.protoGenerator = protoGenerator
+
+	layers, err := gpm.loadLayers(basePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := gpm.registerRepos(basePath, layers); err != nil {
+		return nil, err
+	}
+	plan := &Plan{Entries: make([]*PlanEntry, 0)}
+	for _, layer := range layers {
+		jobs, err := gpm.buildJobs(basePath, layer)
+		if err != nil {
+			return nil, err
+		}
+		// Planning failures are reported on each entry.
+		gpm.runJobs(jobs, func(job *Job) error {
+			entry := gpm.planJob(job)
+			plan.add(entry)
+			if entry.Error != "" {
+				return errors.New(entry.Error)
+			}
+			return nil
+		})
+	}
+	sort.SliceStable(plan.Entries, func(i, j int) bool {
+		if plan.Entries[i].Directory != plan.Entries[j].Directory {
+			return plan.Entries[i].Directory < plan.Entries[j].Directory
+		}
+		return plan.Entries[i].Language < plan.Entries[j].Language
+	})
+	return plan, nil
+}
+
+// planJob clones the target repository of a job and determines the expected outcome of its generation.
+func (gpm *GPM) planJob(job *Job) *PlanEntry {
+	logger := gpm.jobLogger(job.Entity, job.Language)
+	repoName := gpm.getRepoName(job)
+	entry := &PlanEntry{Directory: job.Entity, Language: job.Language, Repository: repoName}
+	repoURL, err := gpm.repositoryProvider.GetRepoURL(gpm.cfg.RepositoryOrganization, repoName)
+	if err != nil {
+		entry.Error = fmt.Sprintf("cannot determine repository URL: %s", err.Error())
+		return entry
+	}
+	entry.RepositoryURL = maskURL(repoURL)
+	if err := gpm.prepareGoTarget(logger, job, repoURL); err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+
+	// Cached copies are shared among the jobs targeting the same repository.
+	lock := gpm.repoLock(repoName)
+	lock.Lock()
+	defer lock.Unlock()
+	tmpRepoDir, release, err := gpm.checkoutRepo(job, repoName, repoURL)
+	if err != nil {
+		entry.Error = fmt.Sprintf("cannot clone target repository: %s", err.Error())
+		return entry
+	}
+	defer release()
+	version, err := gpm.repositoryProvider.GetLastVersion(tmpRepoDir)
+	if err != nil {
+		entry.Error = fmt.Sprintf("cannot obtain current version: %s", err.Error())
+		return entry
+	}
+	if gpm.cfg.UsePullRequests() && !gpm.cfg.SkipPublish {
+		// Merged pull requests are tagged by the generation, so they are not tagged while planning.
+		manager, err := gpm.pullRequestManager()
+		if err != nil {
+			entry.Error = err.Error()
+			return entry
+		}
+		if version, err = gpm.lastMergedVersion(logger, manager, repoName, version); err != nil {
+			entry.Error = err.Error()
+			return entry
+		}
+	}
+	entry.CurrentVersion = version.String()
+
+	codeDir := path.Join(tmpRepoDir, gpm.codePath(job, repoName))
+	entry.Files, err = files.ListDifferences(".proto", job.TargetPath, codeDir)
+	if err != nil {
+		entry.Error = fmt.Sprintf("cannot compare files: %s", err.Error())
+		return entry
+	}
+	current, reasons, err := gpm.detectChanges(logger, job, codeDir)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	entry.Reasons = reasons
+	entry.ChangedDependencies = gpm.changedDependencies(job.Entity)
+	entry.Changed = len(entry.Reasons) > 0 || len(entry.ChangedDependencies) > 0
+	if entry.Changed && gpm.cfg.PreRelease != "" {
+		published, err := gpm.publishedPreRelease(logger, tmpRepoDir, gpm.codePath(job, repoName), current)
+		if err != nil {
+			entry.Error = err.Error()
+			return entry
+		}
+		if published != nil {
+			entry.CurrentVersion = published.String()
+			entry.Changed = false
+		}
+	}
+	if !entry.Changed {
+		logger.Info().Str("repo", repoName).Msg("no changes detected")
+		return entry
+	}
+	gpm.markChanged(job.Entity)
+
+	report, err := gpm.analyzeChanges(logger, job.TargetPath, codeDir)
+	if err != nil {
+		entry.Error = fmt.Sprintf("cannot analyze proto changes: %s", err.Error())
+		return entry
+	}
+	for _, change := range report.Changes {
+		entry.Changes = append(entry.Changes, change.String())
+	}
+	gpm.nextVersion(version, report)
+	if gpm.cfg.PreRelease != "" {
+		if version, err = gpm.preReleaseVersion(tmpRepoDir, version); err != nil {
+			entry.Error = err.Error()
+			return entry
+		}
+	}
+	entry.NextVersion = version.String()
+	entry.Bump = report.Bump.String()
+	return entry
+}
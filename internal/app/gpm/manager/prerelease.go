@@ -0,0 +1,81 @@
+package manager
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/manifest"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+	"github.com/rs/zerolog"
+)
+
+// preReleaseIdentifier returns the first identifier of the pre-release versions of the configured branch.
+func (gpm *GPM) preReleaseIdentifier() string {
+	return repo.PreReleaseIdentifier(gpm.cfg.PreRelease)
+}
+
+// preReleaseVersion returns the pre-release of the configured branch for a given release, numbered after the
+// pre-releases already published for the same release (e.g., v1.2.0-feature-login.3).
+func (gpm *GPM) preReleaseVersion(tmpRepoDir string, release *repo.Version) (*repo.Version, error) {
+	identifier := gpm.preReleaseIdentifier()
+	preReleases, err := gpm.repositoryProvider.PreReleases(tmpRepoDir, identifier)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list pre-releases: %w", err)
+	}
+	number := 1
+	for _, preRelease := range preReleases {
+		if preRelease.Release().Compare(release) != 0 || len(preRelease.PreRelease) != 2 {
+			continue
+		}
+		if previous, err := strconv.Atoi(preRelease.PreRelease[1]); err == nil && previous >= number {
+			number = previous + 1
+		}
+	}
+	result := release.Release()
+	result.PreRelease = []string{identifier, strconv.Itoa(number)}
+	return result, nil
+}
+
+// publishedPreRelease returns the latest pre-release of the configured branch if it was generated from the same
+// inputs as the current manifest, or nil otherwise. The manifest is read from the directory of the repository
+// containing the code of the job.
+func (gpm *GPM) publishedPreRelease(logger zerolog.Logger, tmpRepoDir string, codePath string, current *manifest.Manifest) (*repo.Version, error) {
+	preReleases, err := gpm.repositoryProvider.PreReleases(tmpRepoDir, gpm.preReleaseIdentifier())
+	if err != nil {
+		return nil, fmt.Errorf("cannot list pre-releases: %w", err)
+	}
+	if len(preReleases) == 0 {
+		return nil, nil
+	}
+	latest := preReleases[len(preReleases)-1]
+	content, err := gpm.repositoryProvider.ReadFile(tmpRepoDir, latest.String(), path.Join(codePath, manifest.FileName))
+	if err != nil || content == nil {
+		return nil, err
+	}
+	previous, err := manifest.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if previous.Fingerprint != current.Fingerprint {
+		logger.Debug().Str("preRelease", latest.String()).Msg("inputs changed since the latest pre-release")
+		return nil, nil
+	}
+	return latest, nil
+}
+
+// publishPreRelease pushes the generated code to the branch of the pre-release and tags it.
+func (gpm *GPM) publishPreRelease(logger zerolog.Logger, repoName string, tmpRepoDir string, version *repo.Version, messages repo.PublishMessages, result *JobReport) error {
+	commit, err := gpm.repositoryProvider.PublishBranch(tmpRepoDir, gpm.cfg.PreRelease, messages.Commit)
+	if err != nil || commit == "" {
+		return err
+	}
+	if err := gpm.repositoryProvider.TagVersion(tmpRepoDir, commit, version, messages.Tag); err != nil {
+		return err
+	}
+	logger.Info().Str("repo", repoName).Str("branch", gpm.cfg.PreRelease).Str("version", version.String()).Msg("pre-release published")
+	result.Published = true
+	result.NewVersion = version.String()
+	result.Commit = commit
+	return nil
+}
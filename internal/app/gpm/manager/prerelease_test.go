@@ -0,0 +1,96 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/analysis"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+)
+
+// tagsBackend is a git backend that only lists a fixed set of tags.
+type tagsBackend struct {
+	repo.GitBackend
+	tags []string
+}
+
+// Tags returns the configured tags.
+func (tb *tagsBackend) Tags(repoPath string) ([]string, error) {
+	return tb.tags, nil
+}
+
+// newTagsManager creates a manager publishing pre-releases of a branch on a repository with the given tags. Shallow
+// clones are used so the last version is obtained from the tags.
+func newTagsManager(branch string, tags []string) *GPM {
+	gpm := NewManager(config.ServiceConfig{PreRelease: branch})
+	gpm.repositoryProvider = &repo.GHCommon{Backend: &tagsBackend{tags: tags}, CloneDepth: repo.ShallowCloneDepth}
+	return gpm
+}
+
+func TestPreReleaseVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		branch   string
+		tags     []string
+		release  string
+		expected string
+	}{
+		{"first pre-release", "feature/login", []string{"v1.2.0"}, "v1.3.0", "v1.3.0-feature-login.1"},
+		{"numbering continues", "feature/login", []string{"v1.2.0", "v1.3.0-feature-login.1", "v1.3.0-feature-login.2"}, "v1.3.0", "v1.3.0-feature-login.3"},
+		{"numbering continues after gaps", "feature/login", []string{"v1.3.0-feature-login.5", "v1.3.0-feature-login.2"}, "v1.3.0", "v1.3.0-feature-login.6"},
+		{"numeric order", "feature/login", []string{"v1.3.0-feature-login.9", "v1.3.0-feature-login.10"}, "v1.3.0", "v1.3.0-feature-login.11"},
+		{"other releases ignored", "feature/login", []string{"v1.2.1-feature-login.4"}, "v1.3.0", "v1.3.0-feature-login.1"},
+		{"other branches ignored", "feature/login", []string{"v1.3.0-feature-signup.4"}, "v1.3.0", "v1.3.0-feature-login.1"},
+		{"other identifiers ignored", "feature/login", []string{"v1.3.0-feature-login.beta", "v1.3.0-feature-login.2.1"}, "v1.3.0", "v1.3.0-feature-login.1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gpm := newTagsManager(tc.branch, tc.tags)
+			release, err := repo.FromTag(tc.release)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := gpm.preReleaseVersion("", release)
+			if err != nil {
+				t.Fatalf("unable to obtain pre-release: %v", err)
+			}
+			if result.String() != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, result)
+			}
+			if release.String() != tc.release {
+				t.Errorf("release modified to %s", release)
+			}
+		})
+	}
+}
+
+func TestPreReleaseOfNextVersion(t *testing.T) {
+	tags := []string{"v1.2.3", "v1.3.0-feature-login.1", "v2.0.0-feature-login.1", "v2.0.0-feature-login.2"}
+	testCases := []struct {
+		bump     analysis.ChangeLevel
+		expected string
+	}{
+		{analysis.PatchChange, "v1.2.4-feature-login.1"},
+		{analysis.MinorChange, "v1.3.0-feature-login.2"},
+		{analysis.MajorChange, "v2.0.0-feature-login.3"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.bump.String(), func(t *testing.T) {
+			gpm := newTagsManager("feature/login", tags)
+			version, err := gpm.repositoryProvider.GetLastVersion("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			report := analysis.NewReport()
+			report.Bump = tc.bump
+			gpm.nextVersion(version, report)
+			result, err := gpm.preReleaseVersion("", version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.String() != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, result)
+			}
+		})
+	}
+}
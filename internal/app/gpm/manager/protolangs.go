@@ -0,0 +1,195 @@
+package manager
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/protos"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// ProtoLangFileName defines the name of the file that specifies the target languages.
+const ProtoLangFileName = ".protolangs"
+
+// ProtoLangYAMLFileName defines the name of the YAML file that specifies the target languages and their settings.
+const ProtoLangYAMLFileName = ".protolangs.yaml"
+
+// CommentPrefix with the prefix of the comments on the .protolangs file.
+const CommentPrefix = "#"
+
+// protoLangsEntry structure with the settings of a language on the YAML file. Entries may also be plain language names.
+type protoLangsEntry struct {
+	Language   string            `yaml:"language"`
+	Gateway    *bool             `yaml:"gateway"`
+	Validator  *bool             `yaml:"validator"`
+	Docs       *bool             `yaml:"docs"`
+	Repository string            `yaml:"repository"`
+	Package    string            `yaml:"package"`
+	Options    map[string]string `yaml:"options"`
+	Args       []string          `yaml:"args"`
+}
+
+// UnmarshalYAML accepts both a language name and a structure with the language settings.
+func (ple *protoLangsEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var language string
+	if err := unmarshal(&language); err == nil {
+		ple.Language = language
+		return nil
+	}
+	type plain protoLangsEntry
+	return unmarshal((*plain)(ple))
+}
+
+// protoLangsFile structure with the content of the YAML file.
+type protoLangsFile struct {
+	Languages []protoLangsEntry `yaml:"languages"`
+}
+
+// hasProtoLangs checks if a file name defines the target languages.
+func hasProtoLangs(fileName string) bool {
+	return fileName == ProtoLangFileName || fileName == ProtoLangYAMLFileName
+}
+
+// LoadProtoLangs loads the file in each directory that defines the target languages. If none is found, the default one for
+// the project will be returned.
+func (gpm *GPM) LoadProtoLangs(targetPath string) ([]protos.Target, error) {
+	protolangsFile := path.Join(targetPath, ProtoLangFileName)
+	yamlFile := path.Join(targetPath, ProtoLangYAMLFileName)
+	_, plainErr := os.Stat(protolangsFile)
+	_, yamlErr := os.Stat(yamlFile)
+	var targets []protos.Target
+	var err error
+	switch {
+	case plainErr == nil && yamlErr == nil:
+		return nil, fmt.Errorf("only one of %s and %s can be used on %s", ProtoLangFileName, ProtoLangYAMLFileName, targetPath)
+	case yamlErr == nil:
+		log.Debug().Str("path", yamlFile).Msg("Protofile")
+		targets, err = gpm.loadProtoLangsYAML(yamlFile)
+	case plainErr == nil:
+		log.Debug().Str("path", protolangsFile).Msg("Protofile")
+		targets, err = gpm.loadProtoLangsLines(protolangsFile)
+	default:
+		log.Debug().Msg("using default language")
+		return []protos.Target{protos.NewTarget(gpm.cfg.DefaultLanguage)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	languages := make(map[string]bool, 0)
+	for _, target := range targets {
+		if err := target.IsValid(); err != nil {
+			return nil, fmt.Errorf("invalid settings on %s: %w", targetPath, err)
+		}
+		if languages[target.Language] {
+			return nil, fmt.Errorf("language %s is defined more than once on %s", target.Language, targetPath)
+		}
+		languages[target.Language] = true
+	}
+	return targets, nil
+}
+
+// loadProtoLangsYAML parses the YAML file with the target languages.
+func (gpm *GPM) loadProtoLangsYAML(filePath string) ([]protos.Target, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	parsed := protoLangsFile{}
+	if err := yaml.UnmarshalStrict(content, &parsed); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", filePath, err)
+	}
+	result := make([]protos.Target, 0, len(parsed.Languages))
+	for _, entry := range parsed.Languages {
+		target := protos.NewTarget(entry.Language)
+		target.Gateway = entry.Gateway
+		target.Validator = entry.Validator
+		target.Docs = entry.Docs
+		target.Repository = entry.Repository
+		target.Package = entry.Package
+		if entry.Options != nil {
+			target.Options = entry.Options
+		}
+		if entry.Args != nil {
+			target.Args = entry.Args
+		}
+		result = append(result, target)
+	}
+	return result, nil
+}
+
+// loadProtoLangsLines parses the .protolangs file. Each line contains a language optionally followed by its settings
+// as key=value pairs. Blank lines and comments are ignored.
+func (gpm *GPM) loadProtoLangsLines(filePath string) ([]protos.Target, error) {
+	// read the file, no long lines are expected
+	readFile, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer readFile.Close()
+	scanner := bufio.NewScanner(readFile)
+	scanner.Split(bufio.ScanLines)
+	result := make([]protos.Target, 0)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if index := strings.Index(line, CommentPrefix); index >= 0 {
+			line = line[:index]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		target, err := parseProtoLangsLine(fields)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse line %d of %s: %w", lineNumber, filePath, err)
+		}
+		result = append(result, *target)
+	}
+	return result, scanner.Err()
+}
+
+// parseProtoLangsLine parses the fields of a line with a language and its settings, e.g.,
+// go gateway=false docs=true repository=grpc-custom-go option.go=paths=source_relative arg=--go-source-relative
+func parseProtoLangsLine(fields []string) (*protos.Target, error) {
+	target := protos.NewTarget(fields[0])
+	for _, field := range fields[1:] {
+		split := strings.SplitN(field, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("expecting key=value, found %s", field)
+		}
+		key, value := split[0], split[1]
+		switch {
+		case key == "gateway" || key == "validator" || key == "docs":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+			}
+			switch key {
+			case "gateway":
+				target.Gateway = &enabled
+			case "validator":
+				target.Validator = &enabled
+			default:
+				target.Docs = &enabled
+			}
+		case key == "repository":
+			target.Repository = value
+		case key == "package":
+			target.Package = value
+		case key == "arg":
+			target.Args = append(target.Args, value)
+		case strings.HasPrefix(key, "option."):
+			target.Options[strings.TrimPrefix(key, "option.")] = value
+		default:
+			return nil, fmt.Errorf("unknown setting %s", key)
+		}
+	}
+	return &target, nil
+}
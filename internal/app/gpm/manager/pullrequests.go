@@ -0,0 +1,256 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// pullRequestManager returns the repository provider as a pull request manager if supported.
+func (gpm *GPM) pullRequestManager() (repo.PullRequestManager, error) {
+	manager, supported := gpm.repositoryProvider.(repo.PullRequestManager)
+	if !supported {
+		return nil, fmt.Errorf("repository provider %s does not support pull requests", gpm.cfg.RepositoryProvider)
+	}
+	return manager, nil
+}
+
+// pullRequestDescription builds the description of the pull request that publishes a new version.
+func (gpm *GPM) pullRequestDescription(data config.MessageData) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "This pull request publishes version **%s** of the code generated by GPM from the `%s` protos for `%s`. ", data.Version, data.Directory, data.Language)
+	sb.WriteString("The version will be tagged once the pull request is merged.\n\n")
+	fmt.Fprintf(&sb, "* Previous version: %s\n", data.PreviousVersion)
+	fmt.Fprintf(&sb, "* Version increment: %s\n", data.Bump)
+	fmt.Fprintf(&sb, "* Source commit: %s\n", data.SourceCommit)
+	fmt.Fprintf(&sb, "* GPM version: %s\n", data.GPMVersion)
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\n### %s\n\n", title)
+		for _, item := range items {
+			fmt.Fprintf(&sb, "- %s\n", item)
+		}
+	}
+	writeList("Reasons", data.Reasons)
+	writeList("Changed files", data.ChangedFiles)
+	writeList("Changes", data.Changes)
+	return sb.String()
+}
+
+// mergedTagMessage builds the annotation of the tag of a merged pull request. The description of the pull request
+// contains the information of the version at the time it was opened.
+func mergedTagMessage(pullRequest repo.PullRequest) string {
+	return fmt.Sprintf("%s\n\nPull request: %s\n\n%s", pullRequest.Title, pullRequest.URL, strings.TrimSpace(pullRequest.Description))
+}
+
+// supersedePullRequests closes the open pull requests of previous versions targeting the same branch, removing
+// their branches, as the new version includes their changes.
+func (gpm *GPM) supersedePullRequests(logger zerolog.Logger, manager repo.PullRequestManager, repoName string, request repo.PullRequest, version *repo.Version) error {
+	pullRequests, err := manager.ListPullRequests(gpm.cfg.RepositoryOrganization, repoName, repo.VersionBranchPrefix)
+	if err != nil {
+		return fmt.Errorf("cannot list pull requests: %w", err)
+	}
+	comment := fmt.Sprintf("Superseded by version %s generated by GPM.", version.String())
+	for _, pullRequest := range pullRequests {
+		if pullRequest.State != repo.PullRequestOpen || pullRequest.TargetBranch != request.TargetBranch || pullRequest.SourceBranch == request.SourceBranch {
+			continue
+		}
+		if err := manager.ClosePullRequest(gpm.cfg.RepositoryOrganization, repoName, pullRequest, comment); err != nil {
+			return fmt.Errorf("cannot close superseded pull request %s: %w", pullRequest.URL, err)
+		}
+		logger.Info().Str("repo", repoName).Str("branch", pullRequest.SourceBranch).Str("url", pullRequest.URL).Msg("superseded pull request closed")
+	}
+	return nil
+}
+
+// publishPullRequest pushes the generated code to the branch of the new version and opens a pull request targeting
+// the current branch of the repository. Open pull requests of previous versions are closed as superseded.
+func (gpm *GPM) publishPullRequest(logger zerolog.Logger, job *Job, repoName string, tmpRepoDir string, version *repo.Version, data config.MessageData, messages repo.PublishMessages, result *JobReport) error {
+	manager, err := gpm.pullRequestManager()
+	if err != nil {
+		return err
+	}
+	targetBranch, err := gpm.repositoryProvider.CurrentBranch(tmpRepoDir)
+	if err != nil {
+		return fmt.Errorf("cannot determine target branch: %w", err)
+	}
+	sourceBranch := repo.VersionBranch(version)
+	commit, err := gpm.repositoryProvider.PublishBranch(tmpRepoDir, sourceBranch, messages.Commit)
+	if err != nil || commit == "" {
+		return err
+	}
+	request := repo.PullRequest{
+		SourceBranch: sourceBranch,
+		TargetBranch: targetBranch,
+		Title:        fmt.Sprintf("Publish version %s generated by GPM", version.String()),
+		Description:  gpm.pullRequestDescription(data),
+	}
+	if err := gpm.supersedePullRequests(logger, manager, repoName, request, version); err != nil {
+		return err
+	}
+	pullRequest, err := manager.OpenPullRequest(gpm.cfg.RepositoryOrganization, repoName, request)
+	if err != nil {
+		return err
+	}
+	logger.Info().Str("repo", repoName).Str("branch", sourceBranch).Str("url", pullRequest.URL).Msg("pull request opened")
+	result.Published = true
+	result.NewVersion = version.String()
+	result.Commit = commit
+	result.PullRequest = pullRequest.URL
+	return nil
+}
+
+// mergedVersion structure with a version published through a merged pull request.
+type mergedVersion struct {
+	version     *repo.Version
+	pullRequest repo.PullRequest
+}
+
+// mergedVersions returns the versions of the merged pull requests of a repository sorted in ascending order.
+func (gpm *GPM) mergedVersions(logger zerolog.Logger, manager repo.PullRequestManager, repoName string) ([]mergedVersion, error) {
+	pullRequests, err := manager.ListPullRequests(gpm.cfg.RepositoryOrganization, repoName, repo.VersionBranchPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list pull requests: %w", err)
+	}
+	merged := make([]mergedVersion, 0)
+	for _, pullRequest := range pullRequests {
+		if pullRequest.State != repo.PullRequestMerged {
+			continue
+		}
+		version, err := repo.VersionFromBranch(pullRequest.SourceBranch)
+		if err != nil {
+			logger.Debug().Str("branch", pullRequest.SourceBranch).Msg("ignoring pull request")
+			continue
+		}
+		merged = append(merged, mergedVersion{version: version, pullRequest: pullRequest})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].version.LessThan(merged[j].version)
+	})
+	return merged, nil
+}
+
+// lastMergedVersion returns the latest among the given version and the versions of the merged pull requests, as
+// the latter are tagged before generating new code.
+func (gpm *GPM) lastMergedVersion(logger zerolog.Logger, manager repo.PullRequestManager, repoName string, version *repo.Version) (*repo.Version, error) {
+	merged, err := gpm.mergedVersions(logger, manager, repoName)
+	if err != nil {
+		return nil, err
+	}
+	if len(merged) > 0 && version.LessThan(merged[len(merged)-1].version) {
+		return merged[len(merged)-1].version, nil
+	}
+	return version, nil
+}
+
+// tagMergedPullRequests tags the versions of the pull requests that have been merged since the last version was
+// published. The tags are created on the local copy of the repository as well.
+func (gpm *GPM) tagMergedPullRequests(logger zerolog.Logger, repoName string, tmpRepoDir string) ([]string, error) {
+	manager, err := gpm.pullRequestManager()
+	if err != nil {
+		return nil, err
+	}
+	merged, err := gpm.mergedVersions(logger, manager, repoName)
+	if err != nil {
+		return nil, err
+	}
+	lastVersion, err := gpm.repositoryProvider.GetLastVersion(tmpRepoDir)
+	if err != nil {
+		return nil, err
+	}
+	tagged := make([]string, 0)
+	for _, candidate := range merged {
+		if !lastVersion.LessThan(candidate.version) {
+			continue
+		}
+		if candidate.pullRequest.MergeCommit == "" {
+			logger.Warn().Str("url", candidate.pullRequest.URL).Msg("merged pull request without merge commit, skipping tag")
+			continue
+		}
+		// The merge commit may not be available on shallow clones, or if it was merged after the copy was updated.
+		if err := gpm.repositoryProvider.FetchCommit(tmpRepoDir, candidate.pullRequest.MergeCommit, candidate.pullRequest.TargetBranch); err != nil {
+			return tagged, fmt.Errorf("cannot retrieve merge commit %s of version %s: %w", candidate.pullRequest.MergeCommit, candidate.version.String(), err)
+		}
+		if err := gpm.repositoryProvider.TagVersion(tmpRepoDir, candidate.pullRequest.MergeCommit, candidate.version, mergedTagMessage(candidate.pullRequest)); err != nil {
+			return tagged, fmt.Errorf("cannot tag version %s on commit %s: %w", candidate.version.String(), candidate.pullRequest.MergeCommit, err)
+		}
+		logger.Info().Str("repo", repoName).Str("version", candidate.version.String()).Str("commit", candidate.pullRequest.MergeCommit).Msg("merged pull request tagged")
+		tagged = append(tagged, candidate.version.String())
+		lastVersion = candidate.version
+	}
+	return tagged, nil
+}
+
+// Tag creates the version tags of the pull requests merged on the target repositories of all the directories.
+func (gpm *GPM) Tag(basePath string) error {
+	log.Debug().Msg("Tagging merged pull requests")
+	if err := gpm.cfg.IsValid(); err != nil {
+		return fmt.Errorf("invalid configuration options: %w", err)
+	}
+	defer gpm.cleanup(basePath, gpm.cfg.TempPath)
+
+	repoProvider, err := repo.NewRepoProvider(gpm.cfg.RepositoryProvider, gpm.providerOptions())
+	if err != nil {
+		return err
+	}
+	gpm.repositoryProvider = repoProvider
+	if err := gpm.repositoryProvider.ConfigurePusher(gpm.cfg.RepositoryPusherUsername, gpm.cfg.RepositoryPusherEmail, gpm.cfg.RepositoryAccessToken); err != nil {
+		return err
+	}
+	if _, err := gpm.pullRequestManager(); err != nil {
+		return err
+	}
+
+	layers, err := gpm.loadLayers(basePath)
+	if err != nil {
+		return err
+	}
+	if err := gpm.registerRepos(basePath, layers); err != nil {
+		return err
+	}
+	runError := &RunError{Failures: make([]JobError, 0)}
+	for _, layer := range layers {
+		jobs, err := gpm.buildJobs(basePath, layer)
+		if err != nil {
+			return err
+		}
+		runError.Failures = append(runError.Failures, gpm.runJobs(jobs, gpm.tagJob)...)
+	}
+	if len(runError.Failures) > 0 {
+		return runError
+	}
+	return nil
+}
+
+// tagJob clones the target repository of a job and tags its merged pull requests.
+func (gpm *GPM) tagJob(job *Job) error {
+	logger := gpm.jobLogger(job.Entity, job.Language)
+	repoName := gpm.getRepoName(job)
+	repoURL, err := gpm.repositoryProvider.GetRepoURL(gpm.cfg.RepositoryOrganization, repoName)
+	if err != nil {
+		return fmt.Errorf("cannot determine repository URL: %w", err)
+	}
+	lock := gpm.repoLock(repoName)
+	lock.Lock()
+	defer lock.Unlock()
+	tmpRepoDir, release, err := gpm.checkoutRepo(job, repoName, repoURL)
+	if err != nil {
+		return fmt.Errorf("cannot clone target repository %s: %w", maskURL(repoURL), err)
+	}
+	defer release()
+	tagged, err := gpm.tagMergedPullRequests(logger, repoName, tmpRepoDir)
+	if err != nil {
+		return err
+	}
+	if len(tagged) == 0 {
+		logger.Info().Str("repo", repoName).Msg("no merged pull requests pending to be tagged")
+	}
+	return nil
+}
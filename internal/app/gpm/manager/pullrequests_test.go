@@ -0,0 +1,82 @@
+package manager
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/app/gpm/config"
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+	"github.com/rs/zerolog"
+)
+
+// fakePullRequestManager is a pull request manager that records the closed pull requests.
+type fakePullRequestManager struct {
+	pullRequests []repo.PullRequest
+	closed       []string
+}
+
+// OpenPullRequest returns the requested pull request.
+func (fm *fakePullRequestManager) OpenPullRequest(organization string, repoName string, request repo.PullRequest) (*repo.PullRequest, error) {
+	return &request, nil
+}
+
+// ListPullRequests returns the configured pull requests.
+func (fm *fakePullRequestManager) ListPullRequests(organization string, repoName string, branchPrefix string) ([]repo.PullRequest, error) {
+	return fm.pullRequests, nil
+}
+
+// ClosePullRequest records the source branch of the closed pull request.
+func (fm *fakePullRequestManager) ClosePullRequest(organization string, repoName string, pullRequest repo.PullRequest, comment string) error {
+	fm.closed = append(fm.closed, pullRequest.SourceBranch)
+	return nil
+}
+
+func TestSupersedePullRequests(t *testing.T) {
+	manager := &fakePullRequestManager{pullRequests: []repo.PullRequest{
+		{SourceBranch: "gpm/v1.1.0", TargetBranch: "main", State: repo.PullRequestOpen},
+		{SourceBranch: "gpm/v1.2.0", TargetBranch: "main", State: repo.PullRequestOpen},
+		{SourceBranch: "gpm/v1.0.1", TargetBranch: "main", State: repo.PullRequestMerged},
+		{SourceBranch: "gpm/v1.0.2", TargetBranch: "main", State: repo.PullRequestClosed},
+		{SourceBranch: "gpm/v1.1.1", TargetBranch: "release", State: repo.PullRequestOpen},
+		{SourceBranch: "gpm/v1.3.0", TargetBranch: "main", State: repo.PullRequestOpen},
+	}}
+	version, err := repo.FromTag("v1.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := repo.PullRequest{SourceBranch: repo.VersionBranch(version), TargetBranch: "main"}
+	gpm := NewManager(config.ServiceConfig{})
+	if err := gpm.supersedePullRequests(zerolog.Nop(), manager, "grpc-agenda-go", request, version); err != nil {
+		t.Fatal(err)
+	}
+	// Only the open pull requests of other versions targeting the same branch are closed.
+	expected := []string{"gpm/v1.1.0", "gpm/v1.2.0"}
+	if !reflect.DeepEqual(manager.closed, expected) {
+		t.Errorf("expected closed pull requests %v, got %v", expected, manager.closed)
+	}
+}
+
+// pullRequestProvider is a repository provider whose pull requests are managed by a fake manager.
+type pullRequestProvider struct {
+	repo.Provider
+	*fakePullRequestManager
+}
+
+func TestPlanJobMergedPullRequests(t *testing.T) {
+	gpm, job := newPlanManager(t, config.ServiceConfig{PublishMode: "pullRequest"}, "v1.2.0")
+	gpm.repositoryProvider = &pullRequestProvider{Provider: gpm.repositoryProvider, fakePullRequestManager: &fakePullRequestManager{pullRequests: []repo.PullRequest{
+		{SourceBranch: "gpm/v1.1.0", TargetBranch: "main", State: repo.PullRequestMerged},
+		{SourceBranch: "gpm/v1.4.0", TargetBranch: "main", State: repo.PullRequestMerged, MergeCommit: "4f5b6c7d8e9f"},
+		{SourceBranch: "gpm/v1.3.0", TargetBranch: "main", State: repo.PullRequestMerged},
+		{SourceBranch: "gpm/v1.5.0", TargetBranch: "main", State: repo.PullRequestOpen},
+		{SourceBranch: "feature", TargetBranch: "main", State: repo.PullRequestMerged},
+	}}}
+	// The version of the latest merged pull request is used even if it has not been tagged yet.
+	entry := gpm.planJob(job)
+	if entry.Error != "" {
+		t.Fatalf("unexpected error %s", entry.Error)
+	}
+	if entry.CurrentVersion != "v1.4.0" || entry.NextVersion != "v1.4.1" {
+		t.Errorf("unexpected versions %s -> %s", entry.CurrentVersion, entry.NextVersion)
+	}
+}
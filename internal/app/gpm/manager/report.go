@@ -0,0 +1,244 @@
+package manager
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JobReport structure with the outcome of a job.
+type JobReport struct {
+	// Directory containing the protos.
+	Directory string `json:"directory"`
+	// Language that has been generated.
+	Language string `json:"language"`
+	// Repository with the name of the target repository.
+	Repository string `json:"repository"`
+	// Changed determines if the code has been generated.
+	Changed bool `json:"changed"`
+	// Published determines if a new version has been pushed to the target repository.
+	Published bool `json:"published"`
+	// PreviousVersion with the latest version before the run.
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	// NewVersion with the version created on the run, if any.
+	NewVersion string `json:"newVersion,omitempty"`
+	// Commit with the hash of the pushed commit, if any.
+	Commit string `json:"commit,omitempty"`
+	// PullRequest with the URL of the pull request opened to publish the new version, if any.
+	PullRequest string `json:"pullRequest,omitempty"`
+	// Reasons with the inputs of the generation that changed since the published version.
+	Reasons []string `json:"reasons,omitempty"`
+	// GeneratorImage with the digest reference of the docker image used to generate the code, if any.
+	GeneratorImage string `json:"generatorImage,omitempty"`
+	// Artifacts with the package registries where the new version has been published, if any.
+	Artifacts []string `json:"artifacts,omitempty"`
+	// Duration of the job in seconds.
+	Duration float64 `json:"durationSeconds"`
+	// Skipped determines if the job has not been processed because a dependency failed.
+	Skipped bool `json:"skipped,omitempty"`
+	// Error found while processing the job, if any.
+	Error string `json:"error,omitempty"`
+	// started with the time the job started.
+	started time.Time
+}
+
+// RunReport structure with the outcome of a generation run.
+type RunReport struct {
+	// StartedAt with the time the run started.
+	StartedAt time.Time `json:"startedAt"`
+	// Duration of the run in seconds.
+	Duration float64 `json:"durationSeconds"`
+	// Jobs with the outcome of each directory and language.
+	Jobs  []*JobReport `json:"jobs"`
+	mutex sync.Mutex
+}
+
+// NewRunReport creates an empty report for a run starting now.
+func NewRunReport() *RunReport {
+	return &RunReport{StartedAt: time.Now(), Jobs: make([]*JobReport, 0)}
+}
+
+// startJob creates the report of a job and registers it.
+func (rr *RunReport) startJob(job *Job) *JobReport {
+	result := &JobReport{Directory: job.Entity, Language: job.Language, started: time.Now()}
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	rr.Jobs = append(rr.Jobs, result)
+	return result
+}
+
+// finishJob records the duration and the error, if any, of a job.
+func (rr *RunReport) finishJob(result *JobReport, err error) {
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	result.Duration = time.Since(result.started).Seconds()
+	if err != nil {
+		result.Error = err.Error()
+	}
+}
+
+// skipJob registers a job that has not been processed.
+func (rr *RunReport) skipJob(job *Job, reason error) {
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	rr.Jobs = append(rr.Jobs, &JobReport{Directory: job.Entity, Language: job.Language, Skipped: true, Error: reason.Error()})
+}
+
+// finish records the duration of the run and sorts the jobs by directory and language.
+func (rr *RunReport) finish() {
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	rr.Duration = time.Since(rr.StartedAt).Seconds()
+	sort.SliceStable(rr.Jobs, func(i, j int) bool {
+		if rr.Jobs[i].Directory != rr.Jobs[j].Directory {
+			return rr.Jobs[i].Directory < rr.Jobs[j].Directory
+		}
+		return rr.Jobs[i].Language < rr.Jobs[j].Language
+	})
+}
+
+// failures returns the number of jobs that failed.
+func (rr *RunReport) failures() int {
+	result := 0
+	for _, job := range rr.Jobs {
+		if job.Error != "" && !job.Skipped {
+			result++
+		}
+	}
+	return result
+}
+
+// skipped returns the number of jobs that have not been processed.
+func (rr *RunReport) skipped() int {
+	result := 0
+	for _, job := range rr.Jobs {
+		if job.Skipped {
+			result++
+		}
+	}
+	return result
+}
+
+// JSON representation of the report.
+func (rr *RunReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(rr, "", "  ")
+}
+
+// junitFailure structure with the failure of a JUnit test case.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",cdata"`
+}
+
+// junitSkipped structure with the reason a JUnit test case has been skipped.
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitOutput structure with the output of a JUnit test case.
+type junitOutput struct {
+	Content string `xml:",cdata"`
+}
+
+// junitTestCase structure with a JUnit test case. Each job is reported as a test case.
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+// junitTestSuite structure with a JUnit test suite.
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// JUnit representation of the report, where each job is a test case named after the language whose class is the directory.
+func (rr *RunReport) JUnit() ([]byte, error) {
+	suite := junitTestSuite{
+		Name:      "gpm",
+		Tests:     len(rr.Jobs),
+		Failures:  rr.failures(),
+		Skipped:   rr.skipped(),
+		Time:      fmt.Sprintf("%.3f", rr.Duration),
+		Timestamp: rr.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+		TestCases: make([]junitTestCase, 0, len(rr.Jobs)),
+	}
+	for _, job := range rr.Jobs {
+		details := []string{
+			fmt.Sprintf("repository: %s", job.Repository),
+			fmt.Sprintf("changed: %t", job.Changed),
+			fmt.Sprintf("published: %t", job.Published),
+		}
+		if job.PreviousVersion != "" {
+			details = append(details, fmt.Sprintf("previousVersion: %s", job.PreviousVersion))
+		}
+		if job.NewVersion != "" {
+			details = append(details, fmt.Sprintf("newVersion: %s", job.NewVersion))
+		}
+		if job.Commit != "" {
+			details = append(details, fmt.Sprintf("commit: %s", job.Commit))
+		}
+		if job.PullRequest != "" {
+			details = append(details, fmt.Sprintf("pullRequest: %s", job.PullRequest))
+		}
+		for _, reason := range job.Reasons {
+			details = append(details, fmt.Sprintf("reason: %s", reason))
+		}
+		if job.GeneratorImage != "" {
+			details = append(details, fmt.Sprintf("generatorImage: %s", job.GeneratorImage))
+		}
+		for _, artifact := range job.Artifacts {
+			details = append(details, fmt.Sprintf("artifact: %s", artifact))
+		}
+		testCase := junitTestCase{
+			ClassName: job.Directory,
+			Name:      job.Language,
+			Time:      fmt.Sprintf("%.3f", job.Duration),
+			SystemOut: &junitOutput{Content: strings.Join(details, "\n")},
+		}
+		if job.Skipped {
+			testCase.Skipped = &junitSkipped{Message: job.Error}
+		} else if job.Error != "" {
+			testCase.Failure = &junitFailure{Message: "job failed", Content: job.Error}
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	content, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
+
+// Write stores the report on a given path. Paths with the .xml extension use the JUnit format, and JSON is used otherwise.
+func (rr *RunReport) Write(filePath string) error {
+	var content []byte
+	var err error
+	if strings.HasSuffix(strings.ToLower(filePath), ".xml") {
+		content, err = rr.JUnit()
+	} else {
+		content, err = rr.JSON()
+	}
+	if err != nil {
+		return fmt.Errorf("cannot build report: %w", err)
+	}
+	if err := ioutil.WriteFile(filePath, content, 0644); err != nil {
+		return fmt.Errorf("cannot write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// reportFixture builds a finished report with a published, an unchanged, a failed and a skipped job.
+func reportFixture() *RunReport {
+	report := &RunReport{StartedAt: time.Date(2021, 6, 1, 10, 30, 0, 0, time.UTC), Duration: 12.5}
+	report.Jobs = []*JobReport{
+		{
+			Directory:       "agenda",
+			Language:        "go",
+			Repository:      "grpc-agenda-go",
+			Changed:         true,
+			Published:       true,
+			PreviousVersion: "v1.2.0",
+			NewVersion:      "v1.3.0",
+			Commit:          "4f5b6c7d8e9f",
+			Reasons:         []string{"proto agenda/entry.proto changed"},
+			GeneratorImage:  "namely/protoc-all@sha256:4f5b6c7d8e9f",
+			Artifacts:       []string{"goproxy"},
+			Duration:        4.25,
+		},
+		{Directory: "agenda", Language: "python", Repository: "grpc-agenda-python", PreviousVersion: "v1.2.0", Duration: 1},
+		{Directory: "common", Language: "go", Repository: "grpc-common-go", Duration: 2.5, Error: "cannot generate protos: exit status 1"},
+	}
+	report.skipJob(&Job{Entity: "payments", Language: "go"}, errors.New("dependency common failed"))
+	report.finish()
+	// The duration is recomputed when the report is finished.
+	report.Duration = 12.5
+	return report
+}
+
+func TestRunReportWrite(t *testing.T) {
+	testCases := []struct {
+		name   string
+		golden string
+	}{
+		{"json", "report.json"},
+		{"junit", "report.xml"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), tc.golden)
+			if err := reportFixture().Write(outputPath); err != nil {
+				t.Fatalf("cannot write report: %v", err)
+			}
+			written, err := ioutil.ReadFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := ioutil.ReadFile(filepath.Join("testdata", tc.golden))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(written) != string(expected) {
+				t.Errorf("unexpected %s report, got:\n%s", tc.name, written)
+			}
+		})
+	}
+}
+
+func TestRunReportCounters(t *testing.T) {
+	report := reportFixture()
+	if failures := report.failures(); failures != 1 {
+		t.Errorf("expected 1 failure, got %d", failures)
+	}
+	if skipped := report.skipped(); skipped != 1 {
+		t.Errorf("expected 1 skipped job, got %d", skipped)
+	}
+}
@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChangeLevel defines the impact of a change on the consumers of the generated code.
+type ChangeLevel int
+
+const (
+	// NoChange is used when the definitions are semantically equivalent.
+	NoChange ChangeLevel = iota
+	// PatchChange for changes that do not modify the API such as comments or options.
+	PatchChange
+	// MinorChange for backward compatible additions.
+	MinorChange
+	// MajorChange for changes that break existing consumers.
+	MajorChange
+)
+
+// ChangeLevelToString map associating level with its string representation.
+var ChangeLevelToString = map[ChangeLevel]string{
+	NoChange:    "none",
+	PatchChange: "patch",
+	MinorChange: "minor",
+	MajorChange: "major",
+}
+
+// String representation of the level.
+func (cl ChangeLevel) String() string {
+	return ChangeLevelToString[cl]
+}
+
+// Change structure with a single difference found between two sets of protos.
+type Change struct {
+	// Level with the impact of the change.
+	Level ChangeLevel
+	// Element with the fully qualified name of the affected element.
+	Element string
+	// Description with a human readable explanation of the change.
+	Description string
+}
+
+// String representation of the change.
+func (c Change) String() string {
+	return fmt.Sprintf("[%s] %s: %s", c.Level.String(), c.Element, c.Description)
+}
+
+// Report structure with the result of comparing two sets of proto definitions.
+type Report struct {
+	// Bump with the version increment required by the changes.
+	Bump ChangeLevel
+	// Changes with the list of differences found.
+	Changes []Change
+}
+
+// NewReport creates an empty report.
+func NewReport() *Report {
+	return &Report{Bump: NoChange, Changes: make([]Change, 0)}
+}
+
+// add registers a new change updating the required bump.
+func (r *Report) add(level ChangeLevel, element string, format string, args ...interface{}) {
+	r.Changes = append(r.Changes, Change{Level: level, Element: element, Description: fmt.Sprintf(format, args...)})
+	if level > r.Bump {
+		r.Bump = level
+	}
+}
+
+// Count returns the number of changes of a given level.
+func (r *Report) Count(level ChangeLevel) int {
+	count := 0
+	for _, change := range r.Changes {
+		if change.Level == level {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary returns a one line description of the report.
+func (r *Report) Summary() string {
+	if len(r.Changes) == 0 {
+		return "no semantic changes"
+	}
+	parts := make([]string, 0)
+	for _, level := range []ChangeLevel{MajorChange, MinorChange, PatchChange} {
+		if count := r.Count(level); count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, level.String()))
+		}
+	}
+	return fmt.Sprintf("%s bump required: %s", r.Bump.String(), strings.Join(parts, ", "))
+}
@@ -0,0 +1,300 @@
+package analysis
+
+import (
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/parser"
+	"github.com/rs/zerolog/log"
+)
+
+// definitions structure indexing all the elements of a set of proto files by their fully qualified name.
+type definitions struct {
+	files    map[string]*parser.ProtoFile
+	messages map[string]*parser.Message
+	enums    map[string]*parser.Enum
+	services map[string]*parser.Service
+}
+
+// newDefinitions indexes a set of proto files.
+func newDefinitions(protoFiles []*parser.ProtoFile) *definitions {
+	defs := &definitions{
+		files:    make(map[string]*parser.ProtoFile, 0),
+		messages: make(map[string]*parser.Message, 0),
+		enums:    make(map[string]*parser.Enum, 0),
+		services: make(map[string]*parser.Service, 0),
+	}
+	for _, protoFile := range protoFiles {
+		defs.files[protoFile.Name] = protoFile
+		for _, msg := range protoFile.AllMessages() {
+			defs.messages[msg.FullName] = msg
+		}
+		for _, enum := range protoFile.AllEnums() {
+			defs.enums[enum.FullName] = enum
+		}
+		for _, service := range protoFile.Services {
+			defs.services[service.FullName] = service
+		}
+	}
+	return defs
+}
+
+// sortedKeys returns the keys of a map sorted alphabetically.
+func sortedKeys(m interface{}) []string {
+	keys := make([]string, 0)
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CompareDirectories parses the protos found on both directories, including their subdirectories, and classifies
+// the differences. A missing old directory is considered as an empty set of definitions.
+func CompareDirectories(oldPath string, newPath string) (*Report, error) {
+	oldFiles := make([]*parser.ProtoFile, 0)
+	if _, err := os.Stat(oldPath); err == nil {
+		oldFiles, err = parser.ParseTree(oldPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	newFiles, err := parser.ParseTree(newPath)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(oldFiles, newFiles), nil
+}
+
+// Compare classifies the differences between two sets of proto definitions.
+func Compare(oldFiles []*parser.ProtoFile, newFiles []*parser.ProtoFile) *Report {
+	report := NewReport()
+	oldDefs := newDefinitions(oldFiles)
+	newDefs := newDefinitions(newFiles)
+
+	compareFiles(report, oldDefs, newDefs)
+
+	for _, name := range sortedKeys(oldDefs.messages) {
+		newMsg, exists := newDefs.messages[name]
+		if !exists {
+			report.add(MajorChange, name, "message removed")
+			continue
+		}
+		compareMessages(report, oldDefs.messages[name], newMsg)
+	}
+	for _, name := range sortedKeys(newDefs.messages) {
+		if _, exists := oldDefs.messages[name]; !exists {
+			report.add(MinorChange, name, "message added")
+		}
+	}
+
+	for _, name := range sortedKeys(oldDefs.enums) {
+		newEnum, exists := newDefs.enums[name]
+		if !exists {
+			report.add(MajorChange, name, "enum removed")
+			continue
+		}
+		compareEnums(report, oldDefs.enums[name], newEnum)
+	}
+	for _, name := range sortedKeys(newDefs.enums) {
+		if _, exists := oldDefs.enums[name]; !exists {
+			report.add(MinorChange, name, "enum added")
+		}
+	}
+
+	for _, name := range sortedKeys(oldDefs.services) {
+		newService, exists := newDefs.services[name]
+		if !exists {
+			report.add(MajorChange, name, "service removed")
+			continue
+		}
+		compareServices(report, oldDefs.services[name], newService)
+	}
+	for _, name := range sortedKeys(newDefs.services) {
+		if _, exists := oldDefs.services[name]; !exists {
+			report.add(MinorChange, name, "service added")
+		}
+	}
+
+	log.Debug().Int("changes", len(report.Changes)).Str("bump", report.Bump.String()).Msg("proto changes classified")
+	return report
+}
+
+// compareFiles checks file level differences.
+func compareFiles(report *Report, oldDefs *definitions, newDefs *definitions) {
+	for _, name := range sortedKeys(oldDefs.files) {
+		oldFile := oldDefs.files[name]
+		newFile, exists := newDefs.files[name]
+		if !exists {
+			// Removed elements are reported individually.
+			report.add(PatchChange, name, "file removed")
+			continue
+		}
+		if oldFile.Package != newFile.Package {
+			report.add(MajorChange, name, "package changed from %s to %s", oldFile.Package, newFile.Package)
+		}
+		if oldFile.Syntax != newFile.Syntax {
+			report.add(MajorChange, name, "syntax changed from %s to %s", oldFile.Syntax, newFile.Syntax)
+		}
+		if !reflect.DeepEqual(oldFile.Options, newFile.Options) {
+			report.add(PatchChange, name, "file options changed")
+		}
+		if !reflect.DeepEqual(importPaths(oldFile), importPaths(newFile)) {
+			report.add(PatchChange, name, "imports changed")
+		}
+	}
+	for _, name := range sortedKeys(newDefs.files) {
+		if _, exists := oldDefs.files[name]; !exists {
+			report.add(PatchChange, name, "file added")
+		}
+	}
+}
+
+// compareMessages checks the differences between two versions of the same message.
+func compareMessages(report *Report, oldMsg *parser.Message, newMsg *parser.Message) {
+	name := oldMsg.FullName
+	if oldMsg.Comment != newMsg.Comment {
+		report.add(PatchChange, name, "comment changed")
+	}
+	if !reflect.DeepEqual(oldMsg.Options, newMsg.Options) {
+		report.add(PatchChange, name, "options changed")
+	}
+	if !reflect.DeepEqual(oldMsg.ReservedNumbers, newMsg.ReservedNumbers) || !reflect.DeepEqual(oldMsg.ReservedNames, newMsg.ReservedNames) {
+		report.add(PatchChange, name, "reserved fields changed")
+	}
+
+	newFields := make(map[int]*parser.Field, 0)
+	for _, field := range newMsg.Fields {
+		newFields[field.Number] = field
+	}
+	oldFields := make(map[int]*parser.Field, 0)
+	for _, oldField := range oldMsg.Fields {
+		oldFields[oldField.Number] = oldField
+		fieldName := name + "." + oldField.Name
+		newField, exists := newFields[oldField.Number]
+		if !exists {
+			report.add(MajorChange, fieldName, "field %d removed", oldField.Number)
+			continue
+		}
+		if oldField.Name != newField.Name {
+			report.add(MajorChange, fieldName, "field %d renamed to %s", oldField.Number, newField.Name)
+		}
+		if oldField.Type != newField.Type {
+			report.add(MajorChange, fieldName, "type changed from %s to %s", oldField.Type, newField.Type)
+		}
+		if oldField.Label != newField.Label {
+			report.add(MajorChange, fieldName, "label changed from '%s' to '%s'", oldField.Label, newField.Label)
+		}
+		if oldField.OneOf != newField.OneOf {
+			report.add(MajorChange, fieldName, "oneof changed from '%s' to '%s'", oldField.OneOf, newField.OneOf)
+		}
+		if !reflect.DeepEqual(oldField.Options, newField.Options) {
+			report.add(PatchChange, fieldName, "options changed")
+		}
+		if oldField.Comment != newField.Comment {
+			report.add(PatchChange, fieldName, "comment changed")
+		}
+	}
+	for _, newField := range newMsg.Fields {
+		if _, exists := oldFields[newField.Number]; !exists {
+			report.add(MinorChange, name+"."+newField.Name, "field %d added", newField.Number)
+		}
+	}
+}
+
+// compareEnums checks the differences between two versions of the same enum.
+func compareEnums(report *Report, oldEnum *parser.Enum, newEnum *parser.Enum) {
+	name := oldEnum.FullName
+	if oldEnum.Comment != newEnum.Comment {
+		report.add(PatchChange, name, "comment changed")
+	}
+	if !reflect.DeepEqual(oldEnum.Options, newEnum.Options) {
+		report.add(PatchChange, name, "options changed")
+	}
+	if !reflect.DeepEqual(oldEnum.ReservedNumbers, newEnum.ReservedNumbers) || !reflect.DeepEqual(oldEnum.ReservedNames, newEnum.ReservedNames) {
+		report.add(PatchChange, name, "reserved values changed")
+	}
+	newValues := make(map[string]*parser.EnumValue, 0)
+	for _, value := range newEnum.Values {
+		newValues[value.Name] = value
+	}
+	oldValues := make(map[string]*parser.EnumValue, 0)
+	for _, oldValue := range oldEnum.Values {
+		oldValues[oldValue.Name] = oldValue
+		valueName := name + "." + oldValue.Name
+		newValue, exists := newValues[oldValue.Name]
+		if !exists {
+			report.add(MajorChange, valueName, "enum value removed")
+			continue
+		}
+		if oldValue.Number != newValue.Number {
+			report.add(MajorChange, valueName, "number changed from %d to %d", oldValue.Number, newValue.Number)
+		}
+		if !reflect.DeepEqual(oldValue.Options, newValue.Options) {
+			report.add(PatchChange, valueName, "options changed")
+		}
+		if oldValue.Comment != newValue.Comment {
+			report.add(PatchChange, valueName, "comment changed")
+		}
+	}
+	for _, newValue := range newEnum.Values {
+		if _, exists := oldValues[newValue.Name]; !exists {
+			report.add(MinorChange, name+"."+newValue.Name, "enum value added")
+		}
+	}
+}
+
+// compareServices checks the differences between two versions of the same service.
+func compareServices(report *Report, oldService *parser.Service, newService *parser.Service) {
+	name := oldService.FullName
+	if oldService.Comment != newService.Comment {
+		report.add(PatchChange, name, "comment changed")
+	}
+	if !reflect.DeepEqual(oldService.Options, newService.Options) {
+		report.add(PatchChange, name, "options changed")
+	}
+	newRPCs := make(map[string]*parser.RPC, 0)
+	for _, rpc := range newService.RPCs {
+		newRPCs[rpc.Name] = rpc
+	}
+	oldRPCs := make(map[string]*parser.RPC, 0)
+	for _, oldRPC := range oldService.RPCs {
+		oldRPCs[oldRPC.Name] = oldRPC
+		rpcName := name + "." + oldRPC.Name
+		newRPC, exists := newRPCs[oldRPC.Name]
+		if !exists {
+			report.add(MajorChange, rpcName, "rpc removed")
+			continue
+		}
+		if oldRPC.RequestType != newRPC.RequestType {
+			report.add(MajorChange, rpcName, "request type changed from %s to %s", oldRPC.RequestType, newRPC.RequestType)
+		}
+		if oldRPC.ResponseType != newRPC.ResponseType {
+			report.add(MajorChange, rpcName, "response type changed from %s to %s", oldRPC.ResponseType, newRPC.ResponseType)
+		}
+		if oldRPC.ClientStreaming != newRPC.ClientStreaming || oldRPC.ServerStreaming != newRPC.ServerStreaming {
+			report.add(MajorChange, rpcName, "streaming mode changed")
+		}
+		if !reflect.DeepEqual(oldRPC.Options, newRPC.Options) {
+			report.add(PatchChange, rpcName, "options changed")
+		}
+		if oldRPC.Comment != newRPC.Comment {
+			report.add(PatchChange, rpcName, "comment changed")
+		}
+	}
+	for _, newRPC := range newService.RPCs {
+		if _, exists := oldRPCs[newRPC.Name]; !exists {
+			report.add(MinorChange, name+"."+newRPC.Name, "rpc added")
+		}
+	}
+}
+
+// importPaths returns the list of files imported by a proto file.
+func importPaths(protoFile *parser.ProtoFile) []string {
+	result := make([]string, 0)
+	for _, imported := range protoFile.Imports {
+		result = append(result, imported.Modifier+imported.Path)
+	}
+	return result
+}
@@ -0,0 +1,162 @@
+package analysis
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// baseProto with the definitions used as previous version on the tests.
+const baseProto = `syntax = "proto3";
+package agenda.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "github.com/org/agenda-go/v1";
+
+// Entry of the agenda.
+message Entry {
+  string entry_id = 1;
+  // Title of the entry.
+  string title = 2;
+  repeated string tags = 3;
+  oneof target {
+    string person = 4;
+    string team = 5;
+  }
+}
+
+enum Kind {
+  KIND_UNSPECIFIED = 0;
+  MEETING = 1;
+}
+
+service AgendaService {
+  rpc Add(Entry) returns (Entry);
+  rpc Get(Entry) returns (Entry);
+}
+`
+
+// writeProtos stores a set of files on a new temporal directory.
+func writeProtos(t *testing.T, protos map[string]string) string {
+	t.Helper()
+	basePath := t.TempDir()
+	for name, content := range protos {
+		filePath := filepath.Join(basePath, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return basePath
+}
+
+// replace returns the base proto with a piece of text replaced.
+func replace(t *testing.T, old string, new string) string {
+	t.Helper()
+	if !strings.Contains(baseProto, old) {
+		t.Fatalf("%q not found on base proto", old)
+	}
+	return strings.Replace(baseProto, old, new, 1)
+}
+
+func TestCompareDirectories(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old      string
+		new      string
+		expected ChangeLevel
+	}{
+		{"unchanged", "", "", NoChange},
+		{"field removed", "  repeated string tags = 3;\n", "", MajorChange},
+		{"field retyped", "string title = 2;", "bytes title = 2;", MajorChange},
+		{"field renamed", "string title = 2;", "string name = 2;", MajorChange},
+		{"field renumbered", "string title = 2;", "string title = 6;", MajorChange},
+		{"field label changed", "repeated string tags = 3;", "string tags = 3;", MajorChange},
+		{"field moved out of oneof", "    string team = 5;\n  }\n", "  }\n  string team = 5;\n", MajorChange},
+		{"message removed", "message Entry {", "message Item {", MajorChange},
+		{"enum value removed", "  MEETING = 1;\n", "", MajorChange},
+		{"enum value renumbered", "MEETING = 1;", "MEETING = 2;", MajorChange},
+		{"rpc removed", "  rpc Get(Entry) returns (Entry);\n", "", MajorChange},
+		{"rpc request changed", "rpc Get(Entry)", "rpc Get(Kind)", MajorChange},
+		{"rpc streaming changed", "returns (Entry);\n}", "returns (stream Entry);\n}", MajorChange},
+		{"package changed", "package agenda.v1;", "package agenda.v2;", MajorChange},
+		{"field added", "  repeated string tags = 3;\n", "  repeated string tags = 3;\n  int64 priority = 6;\n", MinorChange},
+		{"message added", "enum Kind {", "message Filter {\n  string query = 1;\n}\n\nenum Kind {", MinorChange},
+		{"enum value added", "  MEETING = 1;\n", "  MEETING = 1;\n  REMINDER = 2;\n", MinorChange},
+		{"rpc added", "  rpc Get(Entry) returns (Entry);\n", "  rpc Get(Entry) returns (Entry);\n  rpc Delete(Entry) returns (Entry);\n", MinorChange},
+		{"service added", "", "service Other {\n  rpc Ping(Entry) returns (Entry);\n}\n", MinorChange},
+		{"message comment changed", "// Entry of the agenda.", "// Entry of the calendar.", PatchChange},
+		{"field comment changed", "// Title of the entry.", "// Short title of the entry.", PatchChange},
+		{"comment added", "  rpc Add(Entry)", "  // Adds an entry.\n  rpc Add(Entry)", PatchChange},
+		{"file option changed", "agenda-go/v1", "agenda-go/v1;agenda", PatchChange},
+		{"field option added", "string entry_id = 1;", "string entry_id = 1 [deprecated = true];", PatchChange},
+		{"import added", "import \"google/protobuf/timestamp.proto\";", "import \"google/protobuf/timestamp.proto\";\nimport \"google/protobuf/empty.proto\";", PatchChange},
+		{"import removed", "import \"google/protobuf/timestamp.proto\";\n", "", PatchChange},
+		{"formatting changed", "  string entry_id = 1;", "\n\n  string   entry_id=1 ;", NoChange},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			newProto := baseProto + tc.new
+			if tc.old != "" {
+				newProto = replace(t, tc.old, tc.new)
+			}
+			oldPath := writeProtos(t, map[string]string{"entry.proto": baseProto})
+			newPath := writeProtos(t, map[string]string{"entry.proto": newProto})
+			report, err := CompareDirectories(oldPath, newPath)
+			if err != nil {
+				t.Fatalf("unable to compare: %v", err)
+			}
+			if report.Bump != tc.expected {
+				t.Errorf("expected %s bump, got %s: %v", tc.expected, report.Bump, report.Changes)
+			}
+		})
+	}
+}
+
+func TestCompareDirectoriesFiles(t *testing.T) {
+	oldPath := writeProtos(t, map[string]string{"entry.proto": baseProto})
+	movedPath := writeProtos(t, map[string]string{"sub/entry.proto": baseProto})
+	report, err := CompareDirectories(oldPath, movedPath)
+	if err != nil {
+		t.Fatalf("unable to compare: %v", err)
+	}
+	if report.Bump != PatchChange {
+		t.Errorf("moving a file without changing its definitions should be a patch, got %s: %v", report.Bump, report.Changes)
+	}
+
+	missingPath := filepath.Join(t.TempDir(), "missing")
+	report, err = CompareDirectories(missingPath, oldPath)
+	if err != nil {
+		t.Fatalf("unable to compare with missing directory: %v", err)
+	}
+	if report.Bump != MinorChange {
+		t.Errorf("new definitions should be a minor change, got %s", report.Bump)
+	}
+
+	invalidPath := writeProtos(t, map[string]string{"entry.proto": "message {"})
+	if _, err := CompareDirectories(oldPath, invalidPath); err == nil {
+		t.Errorf("expected error for invalid proto")
+	}
+}
+
+func TestReportSummary(t *testing.T) {
+	report := NewReport()
+	if report.Summary() != "no semantic changes" {
+		t.Errorf("unexpected summary %q", report.Summary())
+	}
+	report.add(PatchChange, "a", "comment changed")
+	report.add(MajorChange, "b", "field %d removed", 1)
+	report.add(MajorChange, "c", "rpc removed")
+	expected := "major bump required: 2 major, 1 patch"
+	if report.Summary() != expected {
+		t.Errorf("expected %q, got %q", expected, report.Summary())
+	}
+	if report.Changes[1].String() != "[major] b: field 1 removed" {
+		t.Errorf("unexpected change %q", report.Changes[1].String())
+	}
+}
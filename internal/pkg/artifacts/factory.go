@@ -0,0 +1,153 @@
+package artifacts
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PublisherType defines a type for all supported package registries.
+type PublisherType int
+
+const (
+	// NPM registry for node packages.
+	NPM PublisherType = iota
+	// PyPI registry for python packages.
+	PyPI
+	// Maven repository for java packages.
+	Maven
+	// GoProxy with the file layout of the go module proxy protocol.
+	GoProxy
+)
+
+// PublisherTypeToString map associating type to its string representation.
+var PublisherTypeToString = map[PublisherType]string{
+	NPM:     "npm",
+	PyPI:    "pypi",
+	Maven:   "maven",
+	GoProxy: "goproxy",
+}
+
+// PublisherTypeToEnum map associating string representation with type.
+var PublisherTypeToEnum = map[string]PublisherType{
+	"npm":     NPM,
+	"pypi":    PyPI,
+	"maven":   Maven,
+	"goproxy": GoProxy,
+}
+
+// Publisher defines the common interface to publish the generated code on a package registry, in addition to the
+// target repository managed by repo.Provider.
+type Publisher interface {
+	// Publish builds the package of a directory and uploads it to the registry.
+	Publish(pkg Package) error
+	// Registry returns the URL of the registry without credentials.
+	Registry() string
+}
+
+// Package structure with the code to be published.
+type Package struct {
+	// Name of the package, which is the name of the target repository.
+	Name string
+	// Path of the directory containing the code and its package manifest.
+	Path string
+	// Version formatted with the conventions of the package manager (e.g., 1.2.0rc1 for python).
+	Version string
+	// Tag with the version tag of the repository (e.g., v1.2.0-rc.1).
+	Tag string
+	// PreRelease with the pre-release identifier of the version, empty for releases.
+	PreRelease string
+	// ModulePath with the path of the go module. Only set for go packages.
+	ModulePath string
+}
+
+// PublisherOptions structure with the settings of a package registry. The credentials may reference environment
+// variables (e.g., ${NPM_TOKEN}).
+type PublisherOptions struct {
+	// Type of registry: npm, pypi, maven or goproxy.
+	Type string
+	// Registry with the URL of the registry. A file:// URL or a local path is used as a directory-backed registry.
+	Registry string
+	// Username used to authenticate on the registry.
+	Username string
+	// Password used to authenticate on the registry.
+	Password string
+	// Token used to authenticate on the registry instead of the username and password.
+	Token string
+	// Command with the path of the tool used to build and upload the package (npm, python3 or mvn). If empty, the
+	// tool is searched on the PATH.
+	Command string
+}
+
+// IsValid checks the type and the registry.
+func (po *PublisherOptions) IsValid() error {
+	if _, exists := PublisherTypeToEnum[strings.ToLower(po.Type)]; !exists {
+		return fmt.Errorf("unsupported publisher type %s", po.Type)
+	}
+	if po.Registry == "" {
+		return fmt.Errorf("missing registry of %s publisher", po.Type)
+	}
+	return nil
+}
+
+// expand returns the options with the environment variables of the credentials resolved.
+func (po PublisherOptions) expand() PublisherOptions {
+	po.Username = os.ExpandEnv(po.Username)
+	po.Password = os.ExpandEnv(po.Password)
+	po.Token = os.ExpandEnv(po.Token)
+	return po
+}
+
+// command returns the configured tool or the default one.
+func (po *PublisherOptions) command(defaultCommand string) string {
+	if po.Command != "" {
+		return po.Command
+	}
+	return defaultCommand
+}
+
+// localRegistry returns the directory of a directory-backed registry, or an empty string if the registry is remote.
+func (po *PublisherOptions) localRegistry() (string, error) {
+	if strings.HasPrefix(po.Registry, "file://") {
+		parsed, err := url.Parse(po.Registry)
+		if err != nil {
+			return "", fmt.Errorf("invalid registry %s: %w", po.Registry, err)
+		}
+		return parsed.Path, nil
+	}
+	if strings.Contains(po.Registry, "://") {
+		return "", nil
+	}
+	return filepath.Abs(po.Registry)
+}
+
+// maskedRegistry returns the URL of the registry without credentials.
+func (po *PublisherOptions) maskedRegistry() string {
+	parsed, err := url.Parse(po.Registry)
+	if err != nil || parsed.User == nil {
+		return po.Registry
+	}
+	parsed.User = nil
+	return parsed.String()
+}
+
+// NewPublisher factory method to instantiate a publisher for a given registry.
+func NewPublisher(options PublisherOptions) (Publisher, error) {
+	if err := options.IsValid(); err != nil {
+		return nil, err
+	}
+	options = options.expand()
+	switch PublisherTypeToEnum[strings.ToLower(options.Type)] {
+	case NPM:
+		return NewNPMPublisher(options), nil
+	case PyPI:
+		return NewPyPIPublisher(options), nil
+	case Maven:
+		return NewMavenPublisher(options), nil
+	case GoProxy:
+		return NewGoProxyPublisher(options), nil
+	}
+	return nil, fmt.Errorf("No publisher implementation found for %s", options.Type)
+}
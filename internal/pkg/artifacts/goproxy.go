@@ -0,0 +1,207 @@
+package artifacts
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// GoProxyPublisher structure publishing go modules with the file layout of the go module proxy protocol, so the
+// registry can be used through GOPROXY. Directory-backed registries are written directly, and remote ones receive
+// the files through HTTP PUT requests, as supported by generic artifact repositories.
+type GoProxyPublisher struct {
+	options PublisherOptions
+	client  *http.Client
+}
+
+// NewGoProxyPublisher creates a publisher for a go module proxy.
+func NewGoProxyPublisher(options PublisherOptions) *GoProxyPublisher {
+	return &GoProxyPublisher{options: options, client: &http.Client{Timeout: 5 * time.Minute}}
+}
+
+// Registry returns the URL of the registry without credentials.
+func (gp *GoProxyPublisher) Registry() string {
+	return gp.options.maskedRegistry()
+}
+
+// escape applies the case encoding of the module proxy protocol, replacing upper case letters with an exclamation
+// mark followed by the lower case letter.
+func escape(value string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		if unicode.IsUpper(r) {
+			sb.WriteRune('!')
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// moduleZip builds the zip file of a module version, whose entries are prefixed by module@version.
+func moduleZip(pkg Package) ([]byte, error) {
+	found, err := packageFiles(pkg.Path)
+	if err != nil {
+		return nil, err
+	}
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+	prefix := fmt.Sprintf("%s@%s", pkg.ModulePath, pkg.Tag)
+	for _, relative := range found {
+		content, err := ioutil.ReadFile(path.Join(pkg.Path, relative))
+		if err != nil {
+			return nil, err
+		}
+		entry, err := writer.Create(path.Join(prefix, relative))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := entry.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// versionFiles builds the .info, .mod and .zip files of a module version indexed by their name.
+func versionFiles(pkg Package) (map[string][]byte, error) {
+	info, err := json.Marshal(map[string]string{"Version": pkg.Tag, "Time": time.Now().UTC().Format(time.RFC3339)})
+	if err != nil {
+		return nil, err
+	}
+	mod, err := ioutil.ReadFile(path.Join(pkg.Path, "go.mod"))
+	if os.IsNotExist(err) {
+		mod = []byte(fmt.Sprintf("module %s\n", pkg.ModulePath))
+	} else if err != nil {
+		return nil, err
+	}
+	archive, err := moduleZip(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build module zip: %w", err)
+	}
+	version := escape(pkg.Tag)
+	return map[string][]byte{
+		version + ".info": info,
+		version + ".mod":  mod,
+		version + ".zip":  archive,
+	}, nil
+}
+
+// appendVersion adds a version to the content of a list file if it is not present.
+func appendVersion(list []byte, version string) []byte {
+	for _, line := range lines(string(list)) {
+		if line == version {
+			return list
+		}
+	}
+	if len(list) > 0 && !bytes.HasSuffix(list, []byte("\n")) {
+		list = append(list, '\n')
+	}
+	return append(list, []byte(version+"\n")...)
+}
+
+// Publish writes the files of the module version and adds it to the list of versions.
+func (gp *GoProxyPublisher) Publish(pkg Package) error {
+	if pkg.ModulePath == "" {
+		return fmt.Errorf("missing module path of %s, check the goModule settings", pkg.Name)
+	}
+	versionFiles, err := versionFiles(pkg)
+	if err != nil {
+		return err
+	}
+	versionDir := path.Join(escape(pkg.ModulePath), "@v")
+	localDir, err := gp.options.localRegistry()
+	if err != nil {
+		return err
+	}
+	if localDir != "" {
+		return gp.publishLocal(path.Join(localDir, versionDir), pkg.Tag, versionFiles)
+	}
+	return gp.publishRemote(strings.TrimSuffix(gp.options.Registry, "/")+"/"+versionDir, pkg.Tag, versionFiles)
+}
+
+// publishLocal writes the files of a version on a directory-backed registry. Existing files are checked before
+// writing any of them, so a published version is never partially overwritten.
+func (gp *GoProxyPublisher) publishLocal(versionDir string, version string, versionFiles map[string][]byte) error {
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		return err
+	}
+	for name := range versionFiles {
+		if _, err := os.Stat(path.Join(versionDir, name)); err == nil {
+			return fmt.Errorf("%s already exists on the registry", name)
+		}
+	}
+	for name, content := range versionFiles {
+		if err := ioutil.WriteFile(path.Join(versionDir, name), content, 0644); err != nil {
+			return err
+		}
+	}
+	listPath := path.Join(versionDir, "list")
+	list, err := ioutil.ReadFile(listPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return ioutil.WriteFile(listPath, appendVersion(list, version), 0644)
+}
+
+// request sends an authenticated request to the registry.
+func (gp *GoProxyPublisher) request(method string, fileURL string, body []byte) (*http.Response, error) {
+	request, err := http.NewRequest(method, fileURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if gp.options.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+gp.options.Token)
+	} else if gp.options.Username != "" {
+		request.SetBasicAuth(gp.options.Username, gp.options.Password)
+	}
+	return gp.client.Do(request)
+}
+
+// upload sends a file to the registry.
+func (gp *GoProxyPublisher) upload(fileURL string, content []byte) error {
+	response, err := gp.request(http.MethodPut, fileURL, content)
+	if err != nil {
+		return fmt.Errorf("cannot upload %s: %w", path.Base(fileURL), err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("cannot upload %s: registry returned %s", path.Base(fileURL), response.Status)
+	}
+	return nil
+}
+
+// publishRemote uploads the files of a version to a remote registry.
+func (gp *GoProxyPublisher) publishRemote(versionURL string, version string, versionFiles map[string][]byte) error {
+	for name, content := range versionFiles {
+		if err := gp.upload(versionURL+"/"+name, content); err != nil {
+			return err
+		}
+	}
+	response, err := gp.request(http.MethodGet, versionURL+"/list", nil)
+	if err != nil {
+		return fmt.Errorf("cannot obtain list of versions: %w", err)
+	}
+	defer response.Body.Close()
+	list := []byte{}
+	switch {
+	case response.StatusCode == http.StatusOK:
+		if list, err = ioutil.ReadAll(response.Body); err != nil {
+			return err
+		}
+	case response.StatusCode != http.StatusNotFound:
+		return fmt.Errorf("cannot obtain list of versions: registry returned %s", response.Status)
+	}
+	return gp.upload(versionURL+"/list", appendVersion(list, version))
+}
@@ -0,0 +1,164 @@
+package artifacts
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writePackage creates the files of a package on a temporal directory.
+func writePackage(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dirPath := t.TempDir()
+	for name, content := range files {
+		filePath := filepath.Join(dirPath, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dirPath
+}
+
+func TestEscape(t *testing.T) {
+	testCases := map[string]string{
+		"github.com/gpm-project/grpc-agenda-go": "github.com/gpm-project/grpc-agenda-go",
+		"github.com/GPM-Project/Agenda":         "github.com/!g!p!m-!project/!agenda",
+		"v1.2.0-RC.1":                           "v1.2.0-!r!c.1",
+	}
+	for value, expected := range testCases {
+		if result := escape(value); result != expected {
+			t.Errorf("expected %s for %s, got %s", expected, value, result)
+		}
+	}
+}
+
+func TestAppendVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		list     string
+		expected string
+	}{
+		{"empty", "", "v1.1.0\n"},
+		{"existing versions", "v1.0.0\n", "v1.0.0\nv1.1.0\n"},
+		{"missing trailing newline", "v1.0.0", "v1.0.0\nv1.1.0\n"},
+		{"already listed", "v1.0.0\nv1.1.0\n", "v1.0.0\nv1.1.0\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := string(appendVersion([]byte(tc.list), "v1.1.0")); result != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestGoProxyPublishLocal(t *testing.T) {
+	registryDir := t.TempDir()
+	publisher, err := NewPublisher(PublisherOptions{Type: "goproxy", Registry: "file://" + registryDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgPath := writePackage(t, map[string]string{
+		"go.mod":                      "module github.com/Acme/grpc-agenda-go\n",
+		"entry.pb.go":                 "package agenda\n",
+		"v1/entry.pb.go":              "package v1\n",
+		filepath.Join(GitDir, "HEAD"): "ref: refs/heads/main\n",
+	})
+	pkg := Package{Name: "grpc-agenda-go", Path: pkgPath, Tag: "v1.2.0", ModulePath: "github.com/Acme/grpc-agenda-go"}
+	if err := publisher.Publish(pkg); err != nil {
+		t.Fatalf("unable to publish: %v", err)
+	}
+
+	versionDir := filepath.Join(registryDir, "github.com", "!acme", "grpc-agenda-go", "@v")
+	entries, err := ioutil.ReadDir(versionDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if expected := []string{"list", "v1.2.0.info", "v1.2.0.mod", "v1.2.0.zip"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected files %v, got %v", expected, names)
+	}
+	mod, err := ioutil.ReadFile(filepath.Join(versionDir, "v1.2.0.mod"))
+	if err != nil || string(mod) != "module github.com/Acme/grpc-agenda-go\n" {
+		t.Errorf("unexpected go.mod %q, %v", mod, err)
+	}
+	info, err := ioutil.ReadFile(filepath.Join(versionDir, "v1.2.0.info"))
+	if err != nil || !strings.Contains(string(info), `"Version":"v1.2.0"`) {
+		t.Errorf("unexpected info %q, %v", info, err)
+	}
+	archive, err := ioutil.ReadFile(filepath.Join(versionDir, "v1.2.0.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipped := make([]string, 0, len(reader.File))
+	for _, file := range reader.File {
+		zipped = append(zipped, file.Name)
+	}
+	sort.Strings(zipped)
+	prefix := "github.com/Acme/grpc-agenda-go@v1.2.0/"
+	if expected := []string{prefix + "entry.pb.go", prefix + "go.mod", prefix + "v1/entry.pb.go"}; !reflect.DeepEqual(zipped, expected) {
+		t.Errorf("expected zip entries %v, got %v", expected, zipped)
+	}
+
+	// Publishing a new version appends it to the list.
+	pkg.Tag = "v1.3.0"
+	if err := publisher.Publish(pkg); err != nil {
+		t.Fatalf("unable to publish second version: %v", err)
+	}
+	list, err := ioutil.ReadFile(filepath.Join(versionDir, "list"))
+	if err != nil || string(list) != "v1.2.0\nv1.3.0\n" {
+		t.Errorf("unexpected list %q, %v", list, err)
+	}
+}
+
+func TestGoProxyPublishLocalDuplicateVersion(t *testing.T) {
+	registryDir := t.TempDir()
+	publisher, err := NewPublisher(PublisherOptions{Type: "goproxy", Registry: registryDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := Package{Name: "grpc-agenda-go", Path: writePackage(t, map[string]string{"entry.pb.go": "package agenda\n"}), Tag: "v1.2.0", ModulePath: "example.com/agenda"}
+	versionDir := filepath.Join(registryDir, "example.com", "agenda", "@v")
+	// Only the zip of the version has been published before.
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(versionDir, "v1.2.0.zip"), []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = publisher.Publish(pkg)
+	if err == nil || !strings.Contains(err.Error(), "v1.2.0.zip already exists on the registry") {
+		t.Fatalf("expected duplicate version error, got %v", err)
+	}
+	for _, name := range []string{"v1.2.0.info", "v1.2.0.mod", "list"} {
+		if _, err := os.Stat(filepath.Join(versionDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s must not be written when the version already exists", name)
+		}
+	}
+	if content, err := ioutil.ReadFile(filepath.Join(versionDir, "v1.2.0.zip")); err != nil || string(content) != "previous" {
+		t.Errorf("existing zip must not be modified, got %q, %v", content, err)
+	}
+}
+
+func TestGoProxyPublishRequiresModulePath(t *testing.T) {
+	publisher := NewGoProxyPublisher(PublisherOptions{Type: "goproxy", Registry: t.TempDir()})
+	if err := publisher.Publish(Package{Name: "grpc-agenda-go", Path: t.TempDir(), Tag: "v1.0.0"}); err == nil {
+		t.Errorf("expected error without module path")
+	}
+}
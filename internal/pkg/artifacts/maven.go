@@ -0,0 +1,99 @@
+package artifacts
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// MavenRepositoryID with the identifier of the deployment repository, used to match its credentials.
+const MavenRepositoryID = "gpm"
+
+// MavenPublisher structure publishing java packages with the mvn tool.
+type MavenPublisher struct {
+	options PublisherOptions
+}
+
+// NewMavenPublisher creates a publisher for a maven repository.
+func NewMavenPublisher(options PublisherOptions) *MavenPublisher {
+	return &MavenPublisher{options: options}
+}
+
+// Registry returns the URL of the registry without credentials.
+func (mp *MavenPublisher) Registry() string {
+	return mp.options.maskedRegistry()
+}
+
+// mavenServer structure with the credentials of a repository on the maven settings.
+type mavenServer struct {
+	ID       string `xml:"id"`
+	Username string `xml:"username"`
+	Password string `xml:"password"`
+}
+
+// mavenSettings structure with the maven settings used for the deployment.
+type mavenSettings struct {
+	XMLName xml.Name      `xml:"settings"`
+	Servers []mavenServer `xml:"servers>server"`
+}
+
+// settingsFile writes a temporal maven settings file with the credentials of the repository, returning an empty
+// path if there are no credentials. Tokens are sent as password.
+func (mp *MavenPublisher) settingsFile() (string, error) {
+	if mp.options.Token == "" && mp.options.Username == "" {
+		return "", nil
+	}
+	server := mavenServer{ID: MavenRepositoryID, Username: mp.options.Username, Password: mp.options.Password}
+	if mp.options.Token != "" {
+		server.Password = mp.options.Token
+	}
+	content, err := xml.MarshalIndent(mavenSettings{Servers: []mavenServer{server}}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	f, err := ioutil.TempFile("", "gpm-maven-settings-*.xml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(content); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// repositoryURL returns the URL of the deployment repository. Directory-backed repositories use a file URL, which
+// is natively supported by maven.
+func (mp *MavenPublisher) repositoryURL() (string, error) {
+	localDir, err := mp.options.localRegistry()
+	if err != nil {
+		return "", err
+	}
+	if localDir != "" {
+		return fmt.Sprintf("file://%s", localDir), nil
+	}
+	return mp.options.Registry, nil
+}
+
+// Publish builds the package and deploys it to the repository.
+func (mp *MavenPublisher) Publish(pkg Package) error {
+	if err := requireFile(pkg, "pom.xml"); err != nil {
+		return err
+	}
+	repositoryURL, err := mp.repositoryURL()
+	if err != nil {
+		return err
+	}
+	args := []string{"--batch-mode", "deploy", fmt.Sprintf("-DaltDeploymentRepository=%s::default::%s", MavenRepositoryID, repositoryURL)}
+	settings, err := mp.settingsFile()
+	if err != nil {
+		return fmt.Errorf("cannot write maven settings: %w", err)
+	}
+	if settings != "" {
+		defer os.Remove(settings)
+		args = append(args, "--settings", settings)
+	}
+	_, err = runCmd(pkg.Path, nil, mp.options.command("mvn"), args...)
+	return err
+}
@@ -0,0 +1,75 @@
+package artifacts
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeMaven writes a script that records its arguments and the content of the settings file, if any, on the
+// given log file.
+func fakeMaven(t *testing.T, logPath string) string {
+	t.Helper()
+	script := `#!/bin/sh
+echo "$@" > ` + logPath + `
+while [ $# -gt 0 ]; do
+  if [ "$1" = "--settings" ]; then cat "$2" >> ` + logPath + `; fi
+  shift
+done
+`
+	command := filepath.Join(t.TempDir(), "mvn")
+	if err := ioutil.WriteFile(command, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return command
+}
+
+func TestMavenPublishFileRegistry(t *testing.T) {
+	registryDir := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "mvn.log")
+	testCases := []struct {
+		name     string
+		options  PublisherOptions
+		settings string
+	}{
+		{"file url", PublisherOptions{Type: "maven", Registry: "file://" + registryDir}, ""},
+		{"local path", PublisherOptions{Type: "maven", Registry: registryDir}, ""},
+		{"credentials", PublisherOptions{Type: "maven", Registry: registryDir, Username: "deployer", Token: "secret"}, "<password>secret</password>"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.options.Command = fakeMaven(t, logPath)
+			publisher, err := NewPublisher(tc.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pkg := Package{Name: "grpc-agenda-java", Path: writePackage(t, map[string]string{"pom.xml": "<project/>"}), Version: "1.2.0"}
+			if err := publisher.Publish(pkg); err != nil {
+				t.Fatalf("unable to publish: %v", err)
+			}
+			output, err := ioutil.ReadFile(logPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := "--batch-mode deploy -DaltDeploymentRepository=gpm::default::file://" + registryDir
+			if !strings.HasPrefix(string(output), expected) {
+				t.Errorf("expected arguments %q, got %q", expected, output)
+			}
+			if hasSettings := strings.Contains(string(output), "--settings"); hasSettings != (tc.settings != "") {
+				t.Errorf("unexpected settings on %q", output)
+			}
+			if tc.settings != "" && !strings.Contains(string(output), tc.settings) {
+				t.Errorf("expected settings to contain %s, got %q", tc.settings, output)
+			}
+		})
+	}
+}
+
+func TestMavenPublishRequiresPom(t *testing.T) {
+	publisher := NewMavenPublisher(PublisherOptions{Type: "maven", Registry: t.TempDir()})
+	err := publisher.Publish(Package{Name: "grpc-agenda-java", Path: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "pom.xml not found") {
+		t.Errorf("expected missing pom.xml error, got %v", err)
+	}
+}
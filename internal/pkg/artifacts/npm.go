@@ -0,0 +1,97 @@
+package artifacts
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// NPMPublisher structure publishing node packages with the npm tool.
+type NPMPublisher struct {
+	options PublisherOptions
+}
+
+// NewNPMPublisher creates a publisher for a npm registry.
+func NewNPMPublisher(options PublisherOptions) *NPMPublisher {
+	return &NPMPublisher{options: options}
+}
+
+// Registry returns the URL of the registry without credentials.
+func (np *NPMPublisher) Registry() string {
+	return np.options.maskedRegistry()
+}
+
+// userConfig writes a temporal npm configuration file with the credentials of the registry, returning an empty
+// path if there are no credentials.
+func (np *NPMPublisher) userConfig() (string, error) {
+	if np.options.Token == "" && np.options.Username == "" {
+		return "", nil
+	}
+	parsed, err := url.Parse(np.options.Registry)
+	if err != nil {
+		return "", fmt.Errorf("invalid registry %s: %w", np.Registry(), err)
+	}
+	prefix := fmt.Sprintf("//%s%s:", parsed.Host, strings.TrimSuffix(parsed.Path, "/")+"/")
+	content := fmt.Sprintf("%s_authToken=%s\n", prefix, np.options.Token)
+	if np.options.Token == "" {
+		content = fmt.Sprintf("%susername=%s\n%s_password=%s\n", prefix, np.options.Username, prefix,
+			base64.StdEncoding.EncodeToString([]byte(np.options.Password)))
+	}
+	f, err := ioutil.TempFile("", "gpm-npmrc-")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Publish packs the package and uploads it to the registry. Directory-backed registries store the tarball on a
+// directory named after the package. Pre-releases are published with their identifier as dist-tag so they are
+// not installed by default.
+func (np *NPMPublisher) Publish(pkg Package) error {
+	if err := requireFile(pkg, "package.json"); err != nil {
+		return err
+	}
+	command := np.options.command("npm")
+	localDir, err := np.options.localRegistry()
+	if err != nil {
+		return err
+	}
+	if localDir != "" {
+		output, err := runCmd(pkg.Path, nil, command, "pack")
+		if err != nil {
+			return err
+		}
+		tarball := ""
+		for _, line := range lines(output) {
+			if strings.HasSuffix(line, ".tgz") {
+				tarball = line
+			}
+		}
+		if tarball == "" {
+			return fmt.Errorf("cannot determine the tarball created by npm pack")
+		}
+		return copyToRegistry([]string{path.Join(pkg.Path, path.Base(tarball))}, path.Join(localDir, pkg.Name))
+	}
+	args := []string{"publish", "--registry", np.options.Registry}
+	if pkg.PreRelease != "" {
+		args = append(args, "--tag", pkg.PreRelease)
+	}
+	userConfig, err := np.userConfig()
+	if err != nil {
+		return fmt.Errorf("cannot write npm configuration: %w", err)
+	}
+	if userConfig != "" {
+		defer os.Remove(userConfig)
+		args = append(args, "--userconfig", userConfig)
+	}
+	_, err = runCmd(pkg.Path, nil, command, args...)
+	return err
+}
@@ -0,0 +1,77 @@
+package artifacts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// projectNameSeparators matches the separators normalized on the names of the python projects (PEP 503).
+var projectNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// PyPIPublisher structure publishing python packages with the build and twine modules.
+type PyPIPublisher struct {
+	options PublisherOptions
+}
+
+// NewPyPIPublisher creates a publisher for a PyPI registry.
+func NewPyPIPublisher(options PublisherOptions) *PyPIPublisher {
+	return &PyPIPublisher{options: options}
+}
+
+// Registry returns the URL of the registry without credentials.
+func (pp *PyPIPublisher) Registry() string {
+	return pp.options.maskedRegistry()
+}
+
+// credentials returns the environment variables with the credentials used by twine. Tokens use the __token__
+// username.
+func (pp *PyPIPublisher) credentials() []string {
+	if pp.options.Token != "" {
+		return []string{"TWINE_USERNAME=__token__", fmt.Sprintf("TWINE_PASSWORD=%s", pp.options.Token)}
+	}
+	if pp.options.Username != "" {
+		return []string{fmt.Sprintf("TWINE_USERNAME=%s", pp.options.Username), fmt.Sprintf("TWINE_PASSWORD=%s", pp.options.Password)}
+	}
+	return nil
+}
+
+// Publish builds the source distribution and the wheel of the package and uploads them to the registry.
+// Directory-backed registries follow the layout of the simple repository API (PEP 503), so they can be used with
+// pip install --find-links or served as a static index.
+func (pp *PyPIPublisher) Publish(pkg Package) error {
+	if err := requireFile(pkg, "pyproject.toml"); err != nil {
+		return err
+	}
+	command := pp.options.command("python3")
+	outDir, err := ioutil.TempDir("", "gpm-dist-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(outDir)
+	if _, err := runCmd(pkg.Path, nil, command, "-m", "build", "--sdist", "--wheel", "--outdir", outDir, "."); err != nil {
+		return err
+	}
+	built, err := filepath.Glob(path.Join(outDir, "*"))
+	if err != nil {
+		return err
+	}
+	if len(built) == 0 {
+		return fmt.Errorf("no distributions built for %s", pkg.Name)
+	}
+	localDir, err := pp.options.localRegistry()
+	if err != nil {
+		return err
+	}
+	if localDir != "" {
+		projectDir := strings.ToLower(projectNameSeparators.ReplaceAllString(pkg.Name, "-"))
+		return copyToRegistry(built, path.Join(localDir, projectDir))
+	}
+	args := append([]string{"-m", "twine", "upload", "--non-interactive", "--repository-url", pp.options.Registry}, built...)
+	_, err = runCmd(pkg.Path, pp.credentials(), command, args...)
+	return err
+}
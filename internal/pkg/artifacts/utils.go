@@ -0,0 +1,132 @@
+package artifacts
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GitDir with the name of the directory excluded from the packages.
+const GitDir = ".git"
+
+// runCmd executes a packaging tool on a directory. The given environment variables are added to the current ones,
+// so credentials are not exposed on the arguments.
+func runCmd(dir string, env []string, command string, args ...string) (string, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	log.Debug().Str("cmd", command).Strs("args", args).Msg("packaging cmd")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s failed due to %w: %s", path.Base(command), err, string(output))
+	}
+	return string(output), nil
+}
+
+// requireFile checks that the package manifest needed by a publisher exists.
+func requireFile(pkg Package, fileName string) error {
+	if _, err := os.Stat(path.Join(pkg.Path, fileName)); err != nil {
+		return fmt.Errorf("%s not found on %s, check the packaging settings", fileName, pkg.Name)
+	}
+	return nil
+}
+
+// copyFile copies a file, creating the parent directories of the destination.
+func copyFile(source string, destination string) error {
+	if err := os.MkdirAll(path.Dir(destination), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// packageFiles returns the paths of the files of a directory relative to it, skipping the git directory.
+func packageFiles(dirPath string) ([]string, error) {
+	result := make([]string, 0)
+	err := filepath.Walk(dirPath, func(currentPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == GitDir {
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relative, err := filepath.Rel(dirPath, currentPath)
+		if err != nil {
+			return err
+		}
+		result = append(result, filepath.ToSlash(relative))
+		return nil
+	})
+	return result, err
+}
+
+// Stage copies the content of a repository, except the git directory, to a temporal directory so the packaging
+// tools do not leave files behind on the repository. The returned function removes the copy.
+func Stage(repoPath string, tempPath string) (string, func(), error) {
+	stagingDir, err := ioutil.TempDir(tempPath, "package-")
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot create package staging directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(stagingDir) }
+	found, err := packageFiles(repoPath)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	for _, relative := range found {
+		if err := copyFile(path.Join(repoPath, relative), path.Join(stagingDir, relative)); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("cannot stage %s: %w", relative, err)
+		}
+	}
+	return stagingDir, cleanup, nil
+}
+
+// copyToRegistry copies the built files of a package to a directory of a directory-backed registry. Existing files
+// are checked before copying any of them.
+func copyToRegistry(builtFiles []string, registryDir string) error {
+	for _, builtFile := range builtFiles {
+		if _, err := os.Stat(path.Join(registryDir, path.Base(builtFile))); err == nil {
+			return fmt.Errorf("%s already exists on the registry", path.Base(builtFile))
+		}
+	}
+	for _, builtFile := range builtFiles {
+		if err := copyFile(builtFile, path.Join(registryDir, path.Base(builtFile))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lines returns the non empty lines of an output.
+func lines(output string) []string {
+	result := make([]string, 0)
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
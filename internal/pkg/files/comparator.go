@@ -4,9 +4,10 @@ import (
 	"crypto/md5"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -49,28 +50,112 @@ func CompareFilesAreEqual(newPath string, oldPath string) (bool, error) {
 }
 
 // CompareDirectoriesAreEqual compares two different set of files and return if there are changes between the two sets.
+// Files on subdirectories are compared using their relative path.
 func CompareDirectoriesAreEqual(extension string, newPath string, oldPath string) (bool, error) {
 	log.Debug().Str("extension", extension).Str("newPath", newPath).Str("oldPath", oldPath).Msg("comparing files")
 
 	// Iterate on the list of new files, and compare those with the previous ones.
-	fileInfo, err := ioutil.ReadDir(newPath)
+	newFiles, err := ListFiles(extension, newPath)
 	if err != nil {
 		return false, err
 	}
 
 	directoriesAreEqual := true
-	for _, info := range fileInfo {
-		if !info.IsDir() && strings.HasSuffix(info.Name(), extension) {
-			newFilePath := path.Join(newPath, info.Name())
-			oldFilePath := path.Join(oldPath, info.Name())
-			filesAreEqual, err := CompareFilesAreEqual(newFilePath, oldFilePath)
+	for name := range newFiles {
+		newFilePath := path.Join(newPath, name)
+		oldFilePath := path.Join(oldPath, name)
+		filesAreEqual, err := CompareFilesAreEqual(newFilePath, oldFilePath)
+		if err != nil {
+			return false, err
+		}
+		log.Debug().Str("newFilePath", newFilePath).Str("oldFilePath", oldFilePath).Bool("fileAreEqual", filesAreEqual).Msg("file comparison")
+		directoriesAreEqual = directoriesAreEqual && filesAreEqual
+	}
+
+	return directoriesAreEqual, nil
+}
+
+// FileDiff structure with the difference found on a given file.
+type FileDiff struct {
+	// Name of the file.
+	Name string `json:"name"`
+	// Status of the file: added, modified or removed.
+	Status string `json:"status"`
+}
+
+// Status values of the file differences.
+const (
+	// FileAdded for files only present on the new path.
+	FileAdded = "added"
+	// FileModified for files whose content differs.
+	FileModified = "modified"
+	// FileRemoved for files only present on the old path.
+	FileRemoved = "removed"
+)
+
+// ListFiles returns the paths, relative to the directory, of the files with a given extension found on a directory
+// and its subdirectories. Hidden directories are skipped, and a missing directory is considered empty.
+func ListFiles(extension string, dirPath string) (map[string]bool, error) {
+	result := make(map[string]bool, 0)
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		return result, nil
+	}
+	err := filepath.Walk(dirPath, func(currentPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if currentPath != dirPath && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(info.Name(), extension) {
+			relative, err := filepath.Rel(dirPath, currentPath)
 			if err != nil {
-				return false, err
+				return err
 			}
-			log.Debug().Str("newFilePath", newFilePath).Str("oldFilePath", oldFilePath).Bool("fileAreEqual", filesAreEqual).Msg("file comparison")
-			directoriesAreEqual = directoriesAreEqual && filesAreEqual
+			result[filepath.ToSlash(relative)] = true
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	return directoriesAreEqual, nil
+// ListDifferences returns the files with a given extension that differ between two directories, sorted by name.
+func ListDifferences(extension string, newPath string, oldPath string) ([]FileDiff, error) {
+	newFiles, err := ListFiles(extension, newPath)
+	if err != nil {
+		return nil, err
+	}
+	oldFiles, err := ListFiles(extension, oldPath)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]FileDiff, 0)
+	for name := range newFiles {
+		if !oldFiles[name] {
+			result = append(result, FileDiff{Name: name, Status: FileAdded})
+			continue
+		}
+		equal, err := CompareFilesAreEqual(path.Join(newPath, name), path.Join(oldPath, name))
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			result = append(result, FileDiff{Name: name, Status: FileModified})
+		}
+	}
+	for name := range oldFiles {
+		if !newFiles[name] {
+			result = append(result, FileDiff{Name: name, Status: FileRemoved})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
 }
@@ -3,6 +3,9 @@ package files
 import (
 	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
 )
 
 // CopyFile copies the content of a file into a new path.
@@ -34,3 +37,32 @@ func CopyFile(source string, dest string) error {
 	}
 	return nil
 }
+
+// CopyTree copies all the files of a directory and its subdirectories into another one, overwriting the existing files.
+// The paths of the copied files, relative to the directories, are returned in order.
+func CopyTree(source string, dest string) ([]string, error) {
+	result := make([]string, 0)
+	err := filepath.Walk(source, func(currentPath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(source, currentPath)
+		if err != nil {
+			return err
+		}
+		targetPath := path.Join(dest, filepath.ToSlash(relative))
+		if err := os.MkdirAll(path.Dir(targetPath), 0755); err != nil {
+			return err
+		}
+		if err := CopyFile(currentPath, targetPath); err != nil {
+			return err
+		}
+		result = append(result, filepath.ToSlash(relative))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,281 @@
+package gomod
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileName with the name of the file describing a go module.
+const FileName = "go.mod"
+
+// DefaultGoVersion with the go directive of the modules created by GPM if none is configured.
+const DefaultGoVersion = "1.16"
+
+// KnownModules with the default versions of the modules imported by the generated code, indexed by module path.
+var KnownModules = map[string]string{
+	"google.golang.org/protobuf":                "v1.26.0",
+	"google.golang.org/grpc":                    "v1.38.0",
+	"google.golang.org/genproto":                "v0.0.0-20210602131652-f16073e35f0c",
+	"github.com/golang/protobuf":                "v1.5.2",
+	"github.com/grpc-ecosystem/grpc-gateway":    "v1.16.0",
+	"github.com/grpc-ecosystem/grpc-gateway/v2": "v2.4.0",
+	"github.com/envoyproxy/protoc-gen-validate": "v0.6.1",
+}
+
+var scpLikeURLMatcher = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// ModulePathFromURL derives the path of a go module from the URL of its repository, removing the scheme, the
+// credentials, the port and the .git suffix (e.g., git@github.com:org/repo.git becomes github.com/org/repo). Local
+// repositories (file:// URLs) are rejected as their path is not importable.
+func ModulePathFromURL(repoURL string) (string, error) {
+	host, repoPath := "", ""
+	if parsed, err := url.Parse(repoURL); err == nil && parsed.Scheme == "file" {
+		return "", fmt.Errorf("cannot derive module path from local repository URL %s", repoURL)
+	} else if err == nil && parsed.Scheme != "" && parsed.Opaque == "" {
+		// SCP-like URLs without user (e.g., github.com:org/repo.git) are parsed as opaque URLs.
+		host, repoPath = parsed.Hostname(), parsed.Path
+	} else if parts := scpLikeURLMatcher.FindStringSubmatch(repoURL); parts != nil {
+		host, repoPath = parts[1], parts[2]
+	} else {
+		return "", fmt.Errorf("cannot derive module path from repository URL %s", repoURL)
+	}
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if host == "" || repoPath == "" {
+		return "", fmt.Errorf("cannot derive module path from repository URL %s", repoURL)
+	}
+	return strings.TrimPrefix(path.Join(host, repoPath), "/"), nil
+}
+
+var invalidPackageChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// PackageName transforms a name into a valid go package name (e.g., grpc-ping-go becomes grpc_ping_go).
+func PackageName(name string) string {
+	result := strings.ToLower(invalidPackageChars.ReplaceAllString(name, "_"))
+	if result == "" || (result[0] >= '0' && result[0] <= '9') {
+		result = "_" + result
+	}
+	return result
+}
+
+// ImportPath returns the import path of a go_package option, removing the optional package name.
+func ImportPath(goPackage string) string {
+	return strings.SplitN(goPackage, ";", 2)[0]
+}
+
+// File structure with the content of a go.mod file. Only the module and require directives are interpreted, the
+// rest of the content is preserved.
+type File struct {
+	lines []string
+}
+
+// New creates the go.mod file of a module.
+func New(modulePath string, goVersion string) *File {
+	if goVersion == "" {
+		goVersion = DefaultGoVersion
+	}
+	return &File{lines: []string{fmt.Sprintf("module %s", modulePath), "", fmt.Sprintf("go %s", goVersion)}}
+}
+
+// Read loads the go.mod file of a directory, returning nil if it does not exist.
+func Read(dirPath string) (*File, error) {
+	content, err := ioutil.ReadFile(path.Join(dirPath, FileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", FileName, err)
+	}
+	return &File{lines: strings.Split(strings.TrimRight(string(content), "\n"), "\n")}, nil
+}
+
+// Write stores the go.mod file on a directory.
+func (f *File) Write(dirPath string) error {
+	if err := ioutil.WriteFile(path.Join(dirPath, FileName), []byte(f.String()), 0644); err != nil {
+		return fmt.Errorf("cannot write %s: %w", FileName, err)
+	}
+	return nil
+}
+
+// String returns the content of the file.
+func (f *File) String() string {
+	return strings.Join(f.lines, "\n") + "\n"
+}
+
+// unquote removes the optional quotes of a module path.
+func unquote(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return value
+}
+
+// fields returns the fields of a line removing comments.
+func fields(line string) []string {
+	if index := strings.Index(line, "//"); index >= 0 {
+		line = line[:index]
+	}
+	return strings.Fields(line)
+}
+
+// Module returns the path of the module.
+func (f *File) Module() string {
+	for _, line := range f.lines {
+		if parts := fields(line); len(parts) == 2 && parts[0] == "module" {
+			return unquote(parts[1])
+		}
+	}
+	return ""
+}
+
+// SetModule sets the path of the module.
+func (f *File) SetModule(modulePath string) {
+	for index, line := range f.lines {
+		if parts := fields(line); len(parts) == 2 && parts[0] == "module" {
+			f.lines[index] = fmt.Sprintf("module %s", modulePath)
+			return
+		}
+	}
+	f.lines = append([]string{fmt.Sprintf("module %s", modulePath), ""}, f.lines...)
+}
+
+// Requires returns the required modules and their versions.
+func (f *File) Requires() map[string]string {
+	result := make(map[string]string, 0)
+	inBlock := false
+	for _, line := range f.lines {
+		parts := fields(line)
+		switch {
+		case inBlock && len(parts) == 1 && parts[0] == ")":
+			inBlock = false
+		case inBlock && len(parts) == 2:
+			result[unquote(parts[0])] = parts[1]
+		case len(parts) == 2 && parts[0] == "require" && parts[1] == "(":
+			inBlock = true
+		case len(parts) == 3 && parts[0] == "require":
+			result[unquote(parts[1])] = parts[2]
+		}
+	}
+	return result
+}
+
+// AddRequire adds a required module if it is not already required. Existing versions are preserved.
+func (f *File) AddRequire(modulePath string, version string) bool {
+	if _, exists := f.Requires()[modulePath]; exists {
+		return false
+	}
+	entry := fmt.Sprintf("\t%s %s", modulePath, version)
+	for index, line := range f.lines {
+		if parts := fields(line); len(parts) == 2 && parts[0] == "require" && parts[1] == "(" {
+			f.lines = append(f.lines[:index+1], append([]string{entry}, f.lines[index+1:]...)...)
+			return true
+		}
+	}
+	f.lines = append(f.lines, "", "require (", entry, ")")
+	return true
+}
+
+// requireLine returns the index of the line requiring a module, or -1 if it is not required.
+func (f *File) requireLine(modulePath string) int {
+	inBlock := false
+	for index, line := range f.lines {
+		parts := fields(line)
+		switch {
+		case inBlock && len(parts) == 1 && parts[0] == ")":
+			inBlock = false
+		case inBlock && len(parts) == 2 && unquote(parts[0]) == modulePath:
+			return index
+		case len(parts) == 2 && parts[0] == "require" && parts[1] == "(":
+			inBlock = true
+		case len(parts) == 3 && parts[0] == "require" && unquote(parts[1]) == modulePath:
+			return index
+		}
+	}
+	return -1
+}
+
+// SetRequire adds a required module or replaces its version.
+func (f *File) SetRequire(modulePath string, version string) bool {
+	index := f.requireLine(modulePath)
+	if index < 0 {
+		return f.AddRequire(modulePath, version)
+	}
+	if f.Requires()[modulePath] == version {
+		return false
+	}
+	if strings.HasPrefix(strings.TrimSpace(f.lines[index]), "require") {
+		f.lines[index] = fmt.Sprintf("require %s %s", modulePath, version)
+	} else {
+		f.lines[index] = fmt.Sprintf("\t%s %s", modulePath, version)
+	}
+	return true
+}
+
+// DropRequire removes a required module.
+func (f *File) DropRequire(modulePath string) bool {
+	index := f.requireLine(modulePath)
+	if index < 0 {
+		return false
+	}
+	f.lines = append(f.lines[:index], f.lines[index+1:]...)
+	return true
+}
+
+// Imports returns the packages imported by the go files of a directory and its subdirectories, skipping hidden
+// directories. The result is sorted.
+func Imports(dirPath string) ([]string, error) {
+	found := make(map[string]bool, 0)
+	fileSet := token.NewFileSet()
+	err := filepath.Walk(dirPath, func(currentPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if currentPath != dirPath && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(info.Name(), ".go") {
+			return nil
+		}
+		parsed, err := parser.ParseFile(fileSet, currentPath, nil, parser.ImportsOnly)
+		if err != nil {
+			return fmt.Errorf("cannot parse %s: %w", currentPath, err)
+		}
+		for _, spec := range parsed.Imports {
+			found[unquote(spec.Path.Value)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(found))
+	for importPath := range found {
+		result = append(result, importPath)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// ModuleOf returns the module among the candidates that provides an import path, choosing the longest match, or
+// an empty string if none does.
+func ModuleOf(importPath string, candidates map[string]string) string {
+	result := ""
+	for modulePath := range candidates {
+		if (importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")) && len(modulePath) > len(result) {
+			result = modulePath
+		}
+	}
+	return result
+}
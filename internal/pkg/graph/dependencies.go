@@ -0,0 +1,149 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DependencyGraph structure with the dependencies among a set of named nodes.
+type DependencyGraph struct {
+	// dependencies with the direct dependencies of each node.
+	dependencies map[string]map[string]bool
+}
+
+// NewDependencyGraph creates an empty graph.
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{dependencies: make(map[string]map[string]bool, 0)}
+}
+
+// AddNode registers a node on the graph.
+func (dg *DependencyGraph) AddNode(name string) {
+	if _, exists := dg.dependencies[name]; !exists {
+		dg.dependencies[name] = make(map[string]bool, 0)
+	}
+}
+
+// AddDependency registers that a node depends on another one.
+func (dg *DependencyGraph) AddDependency(from string, to string) {
+	dg.AddNode(from)
+	dg.AddNode(to)
+	dg.dependencies[from][to] = true
+}
+
+// Nodes returns the list of nodes sorted alphabetically.
+func (dg *DependencyGraph) Nodes() []string {
+	result := make([]string, 0, len(dg.dependencies))
+	for name := range dg.dependencies {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// DirectDependencies returns the nodes a given node depends on, sorted alphabetically.
+func (dg *DependencyGraph) DirectDependencies(name string) []string {
+	result := make([]string, 0)
+	for dependency := range dg.dependencies[name] {
+		result = append(result, dependency)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Dependencies returns all the nodes a given node depends on, including transitive ones, sorted alphabetically.
+func (dg *DependencyGraph) Dependencies(name string) []string {
+	visited := make(map[string]bool, 0)
+	var visit func(current string)
+	visit = func(current string) {
+		for dependency := range dg.dependencies[current] {
+			if !visited[dependency] {
+				visited[dependency] = true
+				visit(dependency)
+			}
+		}
+	}
+	visit(name)
+	delete(visited, name)
+	result := make([]string, 0, len(visited))
+	for dependency := range visited {
+		result = append(result, dependency)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// TopologicalOrder returns the nodes sorted so that each node appears after all its dependencies. Nodes without
+// a dependency relationship are sorted alphabetically. An error is returned if a cycle is found.
+func (dg *DependencyGraph) TopologicalOrder() ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, 0)
+	result := make([]string, 0, len(dg.dependencies))
+	stack := make([]string, 0)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			// Build the cycle from the first appearance of the node on the stack.
+			cycle := []string{name}
+			for i := len(stack) - 1; i >= 0 && stack[i] != name; i-- {
+				cycle = append([]string{stack[i]}, cycle...)
+			}
+			cycle = append([]string{name}, cycle...)
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dependency := range dg.DirectDependencies(name) {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		result = append(result, name)
+		return nil
+	}
+
+	for _, name := range dg.Nodes() {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Layers groups the nodes so that each node belongs to a layer after the ones of all its dependencies. Nodes on
+// the same layer do not depend on each other and may be processed concurrently. An error is returned if a cycle is found.
+func (dg *DependencyGraph) Layers() ([][]string, error) {
+	ordered, err := dg.TopologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+	level := make(map[string]int, 0)
+	result := make([][]string, 0)
+	for _, name := range ordered {
+		current := 0
+		for dependency := range dg.dependencies[name] {
+			if level[dependency]+1 > current {
+				current = level[dependency] + 1
+			}
+		}
+		level[name] = current
+		if current == len(result) {
+			result = append(result, make([]string, 0))
+		}
+		result[current] = append(result[current], name)
+	}
+	for _, layer := range result {
+		sort.Strings(layer)
+	}
+	return result, nil
+}
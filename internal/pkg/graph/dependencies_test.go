@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newGraph builds a graph from a list of edges with the from:to syntax. Isolated nodes are given without colon.
+func newGraph(edges ...string) *DependencyGraph {
+	result := NewDependencyGraph()
+	for _, edge := range edges {
+		parts := strings.SplitN(edge, ":", 2)
+		if len(parts) == 1 {
+			result.AddNode(parts[0])
+			continue
+		}
+		result.AddDependency(parts[0], parts[1])
+	}
+	return result
+}
+
+// position returns the index of each node on an order.
+func position(order []string) map[string]int {
+	result := make(map[string]int, len(order))
+	for index, name := range order {
+		result[name] = index
+	}
+	return result
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	testCases := []struct {
+		name     string
+		edges    []string
+		expected []string
+	}{
+		{"empty", []string{}, []string{}},
+		{"independent nodes", []string{"c", "a", "b"}, []string{"a", "b", "c"}},
+		{"chain", []string{"a:b", "b:c"}, []string{"c", "b", "a"}},
+		{"diamond", []string{"api:billing", "api:ledger", "billing:common", "ledger:common"}, []string{"common", "billing", "ledger", "api"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dependencies := newGraph(tc.edges...)
+			result, err := dependencies.TopologicalOrder()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+			positions := position(result)
+			for _, name := range dependencies.Nodes() {
+				for _, dependency := range dependencies.DirectDependencies(name) {
+					if positions[dependency] > positions[name] {
+						t.Errorf("%s appears before its dependency %s", name, dependency)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLayers(t *testing.T) {
+	dependencies := newGraph("api:billing", "api:ledger", "billing:common", "ledger:common", "standalone", "reports:ledger")
+	layers, err := dependencies.Layers()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]string{{"common", "standalone"}, {"billing", "ledger"}, {"api", "reports"}}
+	if !reflect.DeepEqual(layers, expected) {
+		t.Errorf("expected %v, got %v", expected, layers)
+	}
+	if transitive := dependencies.Dependencies("api"); !reflect.DeepEqual(transitive, []string{"billing", "common", "ledger"}) {
+		t.Errorf("unexpected transitive dependencies %v", transitive)
+	}
+}
+
+func TestCycles(t *testing.T) {
+	testCases := []struct {
+		name  string
+		edges []string
+		cycle string
+	}{
+		{"self import", []string{"agenda:agenda"}, "agenda -> agenda"},
+		{"two nodes", []string{"agenda:common", "common:agenda"}, "agenda -> common -> agenda"},
+		{"behind a diamond", []string{"api:billing", "api:ledger", "billing:common", "ledger:common", "common:ledger"}, "common -> ledger -> common"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dependencies := newGraph(tc.edges...)
+			expected := "dependency cycle detected: " + tc.cycle
+			if _, err := dependencies.TopologicalOrder(); err == nil || err.Error() != expected {
+				t.Errorf("expected error %q, got %v", expected, err)
+			}
+			if _, err := dependencies.Layers(); err == nil || err.Error() != expected {
+				t.Errorf("expected error %q on layers, got %v", expected, err)
+			}
+		})
+	}
+}
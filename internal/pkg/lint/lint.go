@@ -0,0 +1,226 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/parser"
+)
+
+// RuleSet defines a type for the groups of rules that can be enabled.
+type RuleSet int
+
+const (
+	// Naming checks the case of packages, messages, fields, enums, enum values, services and RPCs.
+	Naming RuleSet = iota
+	// Packages checks that packages are defined and consistent with the directory layout.
+	Packages
+	// Enums checks that enums have an _UNSPECIFIED zero value and that values are prefixed by the enum name.
+	Enums
+	// Comments checks that services and RPCs are documented.
+	Comments
+	// Compatibility checks that removed fields and enum values are reserved. It requires the previously published
+	// protos.
+	Compatibility
+)
+
+// RuleSetToString map associating type to its string representation.
+var RuleSetToString = map[RuleSet]string{
+	Naming:        "naming",
+	Packages:      "packages",
+	Enums:         "enums",
+	Comments:      "comments",
+	Compatibility: "compatibility",
+}
+
+// RuleSetToEnum map associating string representation with type.
+var RuleSetToEnum = map[string]RuleSet{
+	"naming":        Naming,
+	"packages":      Packages,
+	"enums":         Enums,
+	"comments":      Comments,
+	"compatibility": Compatibility,
+}
+
+// Issue structure with a rule violation found on a proto file.
+type Issue struct {
+	// Directory containing the protos relative to the base path.
+	Directory string `json:"directory"`
+	// File with the path of the proto file relative to the directory.
+	File string `json:"file"`
+	// Line where the offending element is declared, zero if it does not apply.
+	Line int `json:"line,omitempty"`
+	// Rule with the identifier of the violated rule (e.g., ENUM_ZERO_VALUE_SUFFIX).
+	Rule string `json:"rule"`
+	// Message describing the violation.
+	Message string `json:"message"`
+}
+
+// String representation of the issue.
+func (i Issue) String() string {
+	location := path.Join(i.Directory, i.File)
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, i.Line)
+	}
+	return fmt.Sprintf("%s: %s (%s)", location, i.Message, i.Rule)
+}
+
+// Report structure with the issues found on a set of directories.
+type Report struct {
+	// Issues sorted by directory, file and line.
+	Issues []Issue `json:"issues"`
+}
+
+// NewReport creates an empty report.
+func NewReport() *Report {
+	return &Report{Issues: make([]Issue, 0)}
+}
+
+// Merge adds the issues of another report.
+func (r *Report) Merge(other *Report) {
+	r.Issues = append(r.Issues, other.Issues...)
+	sort.SliceStable(r.Issues, func(i, j int) bool {
+		a, b := r.Issues[i], r.Issues[j]
+		if a.Directory != b.Directory {
+			return a.Directory < b.Directory
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+}
+
+// HasIssues checks if any rule has been violated.
+func (r *Report) HasIssues() bool {
+	return len(r.Issues) > 0
+}
+
+// Text returns a human readable representation of the report.
+func (r *Report) Text() string {
+	var sb strings.Builder
+	for _, issue := range r.Issues {
+		fmt.Fprintln(&sb, issue.String())
+	}
+	fmt.Fprintf(&sb, "%d issue(s) found\n", len(r.Issues))
+	return sb.String()
+}
+
+// JSON representation of the report.
+func (r *Report) JSON() (string, error) {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// Options structure with the configuration of the linter.
+type Options struct {
+	// RuleSets with the names of the enabled rule sets. All of them are enabled if empty.
+	RuleSets []string
+	// Ignore with the identifiers of the rules that are not checked.
+	Ignore []string
+}
+
+// IsValid checks that the rule sets are supported.
+func (o *Options) IsValid() error {
+	for _, name := range o.RuleSets {
+		if _, exists := RuleSetToEnum[strings.ToLower(name)]; !exists {
+			return fmt.Errorf("unsupported lint rule set %s", name)
+		}
+	}
+	return nil
+}
+
+// Linter structure with the enabled rules.
+type Linter struct {
+	ruleSets map[RuleSet]bool
+	ignore   map[string]bool
+}
+
+// NewLinter creates a linter with the given options.
+func NewLinter(options Options) (*Linter, error) {
+	if err := options.IsValid(); err != nil {
+		return nil, err
+	}
+	result := &Linter{ruleSets: make(map[RuleSet]bool, 0), ignore: make(map[string]bool, 0)}
+	for _, name := range options.RuleSets {
+		result.ruleSets[RuleSetToEnum[strings.ToLower(name)]] = true
+	}
+	if len(options.RuleSets) == 0 {
+		for ruleSet := range RuleSetToString {
+			result.ruleSets[ruleSet] = true
+		}
+	}
+	for _, rule := range options.Ignore {
+		result.ignore[strings.ToUpper(rule)] = true
+	}
+	return result, nil
+}
+
+// Enabled checks if a rule set is enabled.
+func (l *Linter) Enabled(ruleSet RuleSet) bool {
+	return l.ruleSets[ruleSet]
+}
+
+// LintDirectory checks the protos of a directory, including its subdirectories. The directory is given relative to
+// the base path so the package rules can be verified.
+func (l *Linter) LintDirectory(basePath string, directory string) (*Report, error) {
+	protoFiles, err := parser.ParseTree(path.Join(basePath, directory))
+	if err != nil {
+		return nil, err
+	}
+	return l.Lint(directory, protoFiles), nil
+}
+
+// Lint checks the protos of a directory. The compatibility rules are not evaluated as they require the previously
+// published protos.
+func (l *Linter) Lint(directory string, protoFiles []*parser.ProtoFile) *Report {
+	checker := newChecker(l, directory)
+	for _, protoFile := range protoFiles {
+		if l.Enabled(Naming) {
+			checker.checkNaming(protoFile)
+		}
+		if l.Enabled(Enums) {
+			checker.checkEnums(protoFile)
+		}
+		if l.Enabled(Comments) {
+			checker.checkComments(protoFile)
+		}
+	}
+	if l.Enabled(Packages) {
+		checker.checkPackages(protoFiles)
+	}
+	result := NewReport()
+	result.Merge(checker.report)
+	return result
+}
+
+// LintChanges checks the compatibility of the protos of a directory with the ones previously published on another
+// path. A missing previous path is considered as an empty set of protos.
+func (l *Linter) LintChanges(directory string, previousPath string, currentPath string) (*Report, error) {
+	result := NewReport()
+	if !l.Enabled(Compatibility) {
+		return result, nil
+	}
+	if _, err := os.Stat(previousPath); os.IsNotExist(err) {
+		return result, nil
+	}
+	previous, err := parser.ParseTree(previousPath)
+	if err != nil {
+		return nil, err
+	}
+	current, err := parser.ParseTree(currentPath)
+	if err != nil {
+		return nil, err
+	}
+	checker := newChecker(l, directory)
+	checker.checkCompatibility(previous, current)
+	result.Merge(checker.report)
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package lint
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixturesPath with the directory containing a pass and a fail fixture for each rule. The compatibility rules
+// include the previously published protos as well.
+const fixturesPath = "testdata"
+
+// fixtureDirectory with the name of the proto directory of the fixtures, used by the package rules.
+const fixtureDirectory = "agenda"
+
+// lintFixture checks the protos of a fixture with all the rule sets enabled.
+func lintFixture(t *testing.T, linter *Linter, rulePath string, kind string) *Report {
+	t.Helper()
+	report, err := linter.LintDirectory(filepath.Join(rulePath, kind), fixtureDirectory)
+	if err != nil {
+		t.Fatalf("unable to lint %s fixture: %v", kind, err)
+	}
+	previousPath := filepath.Join(rulePath, "previous", fixtureDirectory)
+	changes, err := linter.LintChanges(fixtureDirectory, previousPath, filepath.Join(rulePath, kind, fixtureDirectory))
+	if err != nil {
+		t.Fatalf("unable to lint changes of %s fixture: %v", kind, err)
+	}
+	report.Merge(changes)
+	return report
+}
+
+// rules returns the identifiers of the rules violated on a report.
+func rules(report *Report) map[string]bool {
+	result := make(map[string]bool, 0)
+	for _, issue := range report.Issues {
+		result[issue.Rule] = true
+	}
+	return result
+}
+
+func TestRules(t *testing.T) {
+	fixtures, err := ioutil.ReadDir(fixturesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatalf("no fixtures found")
+	}
+	linter, err := NewLinter(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fixture := range fixtures {
+		rule := strings.ToUpper(fixture.Name())
+		rulePath := filepath.Join(fixturesPath, fixture.Name())
+		t.Run(rule, func(t *testing.T) {
+			if passed := lintFixture(t, linter, rulePath, "pass"); passed.HasIssues() {
+				t.Errorf("unexpected issues on pass fixture:\n%s", passed.Text())
+			}
+			failed := lintFixture(t, linter, rulePath, "fail")
+			if !rules(failed)[rule] {
+				t.Errorf("rule not violated on fail fixture:\n%s", failed.Text())
+			}
+			ignoring, err := NewLinter(Options{Ignore: []string{strings.ToLower(rule)}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rules(lintFixture(t, ignoring, rulePath, "fail"))[rule] {
+				t.Errorf("ignored rule reported")
+			}
+		})
+	}
+}
+
+func TestRuleSets(t *testing.T) {
+	testCases := []struct {
+		ruleSet string
+		rules   []string
+	}{
+		{"naming", []string{"PACKAGE_LOWER_SNAKE_CASE", "MESSAGE_PASCAL_CASE", "FIELD_LOWER_SNAKE_CASE", "ENUM_PASCAL_CASE", "ENUM_VALUE_UPPER_SNAKE_CASE", "SERVICE_PASCAL_CASE", "RPC_PASCAL_CASE"}},
+		{"enums", []string{"ENUM_ZERO_VALUE_SUFFIX", "ENUM_VALUE_PREFIX", "ENUM_ZERO_VALUE_MISSING"}},
+		{"comments", []string{"SERVICE_COMMENTED", "RPC_COMMENTED"}},
+		{"packages", []string{"PACKAGE_DEFINED", "PACKAGE_DIRECTORY_MATCH", "PACKAGE_SAME_DIRECTORY"}},
+		{"compatibility", []string{"FIELD_REMOVED_NOT_RESERVED", "ENUM_VALUE_REMOVED_NOT_RESERVED"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.ruleSet, func(t *testing.T) {
+			linter, err := NewLinter(Options{RuleSets: []string{strings.ToUpper(tc.ruleSet)}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := make(map[string]bool, 0)
+			for _, rule := range tc.rules {
+				expected[rule] = true
+			}
+			fixtures, err := ioutil.ReadDir(fixturesPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, fixture := range fixtures {
+				rule := strings.ToUpper(fixture.Name())
+				for violated := range rules(lintFixture(t, linter, filepath.Join(fixturesPath, fixture.Name()), "fail")) {
+					if !expected[violated] {
+						t.Errorf("rule %s reported with rule set %s", violated, tc.ruleSet)
+					}
+				}
+				if expected[rule] && !rules(lintFixture(t, linter, filepath.Join(fixturesPath, fixture.Name()), "fail"))[rule] {
+					t.Errorf("rule %s not checked with rule set %s", rule, tc.ruleSet)
+				}
+			}
+		})
+	}
+	if _, err := NewLinter(Options{RuleSets: []string{"unknown"}}); err == nil {
+		t.Errorf("expected error for unknown rule set")
+	}
+}
+
+func TestReport(t *testing.T) {
+	report := NewReport()
+	other := NewReport()
+	other.Issues = []Issue{
+		{Directory: "b", File: "x.proto", Line: 3, Rule: "RULE", Message: "second"},
+		{Directory: "a", File: "y.proto", Line: 9, Rule: "RULE", Message: "first"},
+		{Directory: "b", File: "x.proto", Rule: "RULE", Message: "file level"},
+	}
+	report.Merge(other)
+	expected := "a/y.proto:9: first (RULE)\nb/x.proto: file level (RULE)\nb/x.proto:3: second (RULE)\n3 issue(s) found\n"
+	if report.Text() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, report.Text())
+	}
+	if _, err := report.JSON(); err != nil {
+		t.Errorf("unable to serialize report: %v", err)
+	}
+}
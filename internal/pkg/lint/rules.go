@@ -0,0 +1,251 @@
+package lint
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/parser"
+)
+
+var (
+	pascalCaseMatcher     = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	lowerSnakeCaseMatcher = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	upperSnakeCaseMatcher = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+	// versionMatcher matches the version suffix of a package (e.g., v1, v2beta1).
+	versionMatcher = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]*)?$`)
+)
+
+// UnspecifiedValue with the name of the enum zero values after the enum prefix (e.g., STATUS_UNSPECIFIED).
+const UnspecifiedValue = "UNSPECIFIED"
+
+// checker structure accumulating the issues of a directory.
+type checker struct {
+	linter    *Linter
+	directory string
+	report    *Report
+}
+
+// newChecker creates a checker for the protos of a directory.
+func newChecker(linter *Linter, directory string) *checker {
+	return &checker{linter: linter, directory: directory, report: NewReport()}
+}
+
+// add registers an issue unless the rule is ignored.
+func (c *checker) add(protoFile *parser.ProtoFile, line int, rule string, format string, args ...interface{}) {
+	if c.linter.ignore[rule] {
+		return
+	}
+	c.report.Issues = append(c.report.Issues, Issue{
+		Directory: c.directory,
+		File:      protoFile.Name,
+		Line:      line,
+		Rule:      rule,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// upperSnakeCase converts a PascalCase name into UPPER_SNAKE_CASE (e.g., HTTPStatus becomes HTTP_STATUS).
+func upperSnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for index, r := range runes {
+		if index > 0 && unicode.IsUpper(r) {
+			previous := runes[index-1]
+			nextIsLower := index+1 < len(runes) && unicode.IsLower(runes[index+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
+
+// checkNaming verifies the case of the elements of a file.
+func (c *checker) checkNaming(protoFile *parser.ProtoFile) {
+	for _, segment := range strings.Split(protoFile.Package, ".") {
+		if protoFile.Package != "" && !lowerSnakeCaseMatcher.MatchString(segment) {
+			c.add(protoFile, 0, "PACKAGE_LOWER_SNAKE_CASE", "package %s should be lower_snake_case", protoFile.Package)
+			break
+		}
+	}
+	for _, msg := range protoFile.AllMessages() {
+		if !pascalCaseMatcher.MatchString(msg.Name) {
+			c.add(protoFile, msg.Line, "MESSAGE_PASCAL_CASE", "message %s should be PascalCase", msg.FullName)
+		}
+		for _, field := range msg.Fields {
+			if !lowerSnakeCaseMatcher.MatchString(field.Name) {
+				c.add(protoFile, field.Line, "FIELD_LOWER_SNAKE_CASE", "field %s of %s should be lower_snake_case", field.Name, msg.FullName)
+			}
+		}
+	}
+	for _, enum := range protoFile.AllEnums() {
+		if !pascalCaseMatcher.MatchString(enum.Name) {
+			c.add(protoFile, enum.Line, "ENUM_PASCAL_CASE", "enum %s should be PascalCase", enum.FullName)
+		}
+		for _, value := range enum.Values {
+			if !upperSnakeCaseMatcher.MatchString(value.Name) {
+				c.add(protoFile, value.Line, "ENUM_VALUE_UPPER_SNAKE_CASE", "value %s of %s should be UPPER_SNAKE_CASE", value.Name, enum.FullName)
+			}
+		}
+	}
+	for _, service := range protoFile.Services {
+		if !pascalCaseMatcher.MatchString(service.Name) {
+			c.add(protoFile, service.Line, "SERVICE_PASCAL_CASE", "service %s should be PascalCase", service.FullName)
+		}
+		for _, rpc := range service.RPCs {
+			if !pascalCaseMatcher.MatchString(rpc.Name) {
+				c.add(protoFile, rpc.Line, "RPC_PASCAL_CASE", "rpc %s of %s should be PascalCase", rpc.Name, service.FullName)
+			}
+		}
+	}
+}
+
+// checkEnums verifies that enums have an _UNSPECIFIED zero value and that their values are prefixed by the name
+// of the enum, as the values of the enums of a package share the same scope.
+func (c *checker) checkEnums(protoFile *parser.ProtoFile) {
+	for _, enum := range protoFile.AllEnums() {
+		prefix := upperSnakeCase(enum.Name) + "_"
+		hasZero := false
+		for _, value := range enum.Values {
+			if value.Number == 0 {
+				hasZero = true
+				if value.Name != prefix+UnspecifiedValue {
+					c.add(protoFile, value.Line, "ENUM_ZERO_VALUE_SUFFIX", "zero value %s of %s should be named %s%s", value.Name, enum.FullName, prefix, UnspecifiedValue)
+				}
+			}
+			if !strings.HasPrefix(value.Name, prefix) {
+				c.add(protoFile, value.Line, "ENUM_VALUE_PREFIX", "value %s of %s should be prefixed with %s", value.Name, enum.FullName, prefix)
+			}
+		}
+		if !hasZero {
+			c.add(protoFile, enum.Line, "ENUM_ZERO_VALUE_MISSING", "enum %s should define a zero value %s%s", enum.FullName, prefix, UnspecifiedValue)
+		}
+	}
+}
+
+// checkComments verifies that services and RPCs, which are the public interface of the protos, are documented.
+func (c *checker) checkComments(protoFile *parser.ProtoFile) {
+	for _, service := range protoFile.Services {
+		if strings.TrimSpace(service.Comment) == "" {
+			c.add(protoFile, service.Line, "SERVICE_COMMENTED", "service %s should have a leading comment", service.FullName)
+		}
+		for _, rpc := range service.RPCs {
+			if strings.TrimSpace(rpc.Comment) == "" {
+				c.add(protoFile, rpc.Line, "RPC_COMMENTED", "rpc %s of %s should have a leading comment", rpc.Name, service.FullName)
+			}
+		}
+	}
+}
+
+// expectedPackageSuffix returns the segments the package of a file should end with: the name of the directory
+// followed by the subdirectories of the file.
+func (c *checker) expectedPackageSuffix(protoFile *parser.ProtoFile) []string {
+	result := []string{path.Base(c.directory)}
+	if subdirectory := path.Dir(protoFile.Name); subdirectory != "." {
+		result = append(result, strings.Split(subdirectory, "/")...)
+	}
+	return result
+}
+
+// checkPackages verifies that all the files declare a package consistent with the directory layout, and that files
+// on the same directory share the package.
+func (c *checker) checkPackages(protoFiles []*parser.ProtoFile) {
+	packageByDirectory := make(map[string]string, 0)
+	for _, protoFile := range protoFiles {
+		if protoFile.Package == "" {
+			c.add(protoFile, 0, "PACKAGE_DEFINED", "file should declare a package")
+			continue
+		}
+		segments := strings.Split(protoFile.Package, ".")
+		if versionMatcher.MatchString(segments[len(segments)-1]) {
+			segments = segments[:len(segments)-1]
+		}
+		expected := c.expectedPackageSuffix(protoFile)
+		if len(segments) < len(expected) || strings.Join(segments[len(segments)-len(expected):], ".") != strings.Join(expected, ".") {
+			c.add(protoFile, 0, "PACKAGE_DIRECTORY_MATCH", "package %s should end with %s, optionally followed by a version, to match the directory", protoFile.Package, strings.Join(expected, "."))
+		}
+		directory := path.Dir(protoFile.Name)
+		if previous, exists := packageByDirectory[directory]; exists && previous != protoFile.Package {
+			c.add(protoFile, 0, "PACKAGE_SAME_DIRECTORY", "package %s differs from package %s of the other files of the directory", protoFile.Package, previous)
+		} else if !exists {
+			packageByDirectory[directory] = protoFile.Package
+		}
+	}
+}
+
+// reservedNumber checks if a number is part of the reserved ranges.
+func reservedNumber(ranges []parser.Range, number int) bool {
+	for _, reserved := range ranges {
+		if reserved.Contains(number) {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedName checks if a name is part of the reserved names.
+func reservedName(names []string, name string) bool {
+	for _, reserved := range names {
+		if reserved == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCompatibility verifies that the fields and enum values removed from the previous protos are reserved, so
+// they cannot be reused with a different meaning.
+func (c *checker) checkCompatibility(previous []*parser.ProtoFile, current []*parser.ProtoFile) {
+	previousMessages := make(map[string]*parser.Message, 0)
+	previousEnums := make(map[string]*parser.Enum, 0)
+	for _, protoFile := range previous {
+		for _, msg := range protoFile.AllMessages() {
+			previousMessages[msg.FullName] = msg
+		}
+		for _, enum := range protoFile.AllEnums() {
+			previousEnums[enum.FullName] = enum
+		}
+	}
+	for _, protoFile := range current {
+		for _, msg := range protoFile.AllMessages() {
+			old, exists := previousMessages[msg.FullName]
+			if !exists {
+				continue
+			}
+			numbers := make(map[int]bool, 0)
+			for _, field := range msg.Fields {
+				numbers[field.Number] = true
+			}
+			for _, field := range old.Fields {
+				if numbers[field.Number] {
+					continue
+				}
+				if !reservedNumber(msg.ReservedNumbers, field.Number) || !reservedName(msg.ReservedNames, field.Name) {
+					c.add(protoFile, msg.Line, "FIELD_REMOVED_NOT_RESERVED", "removed field %s = %d of %s should be reserved by number and name", field.Name, field.Number, msg.FullName)
+				}
+			}
+		}
+		for _, enum := range protoFile.AllEnums() {
+			old, exists := previousEnums[enum.FullName]
+			if !exists {
+				continue
+			}
+			numbers := make(map[int]bool, 0)
+			for _, value := range enum.Values {
+				numbers[value.Number] = true
+			}
+			for _, value := range old.Values {
+				if numbers[value.Number] {
+					continue
+				}
+				if !reservedNumber(enum.ReservedNumbers, value.Number) || !reservedName(enum.ReservedNames, value.Name) {
+					c.add(protoFile, enum.Line, "ENUM_VALUE_REMOVED_NOT_RESERVED", "removed value %s = %d of %s should be reserved by number and name", value.Name, value.Number, enum.FullName)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/files"
+)
+
+// FileName with the name of the manifest stored on the target repositories.
+const FileName = ".gpm-manifest.json"
+
+// FormatVersion with the version of the manifest format. Changing it forces the generation of all the directories.
+const FormatVersion = 1
+
+// ProtoExtension with the extension of the imported files included on the manifest.
+const ProtoExtension = ".proto"
+
+// Manifest structure with the inputs used to generate the code published on a target repository.
+type Manifest struct {
+	// FormatVersion with the version of the manifest format.
+	FormatVersion int `json:"formatVersion"`
+	// Fingerprint summarizing all the inputs of the generation.
+	Fingerprint string `json:"fingerprint"`
+	// Generator with the identity of the proto generator, including its version.
+	Generator string `json:"generator"`
+	// Settings with the generation settings of the language.
+	Settings string `json:"settings"`
+	// Sources with the checksum of each source file indexed by its path relative to the proto directory.
+	Sources map[string]string `json:"sources"`
+	// Imports with the checksum of the protos of the dependencies indexed by their path relative to the project.
+	Imports map[string]string `json:"imports"`
+	// Files with the paths of the files written on the target repository by the generation.
+	Files []string `json:"files"`
+}
+
+// NewManifest creates an empty manifest for a given generator and settings.
+func NewManifest(generator string, settings string) *Manifest {
+	return &Manifest{
+		FormatVersion: FormatVersion,
+		Generator:     generator,
+		Settings:      settings,
+		Sources:       make(map[string]string, 0),
+		Imports:       make(map[string]string, 0),
+		Files:         make([]string, 0),
+	}
+}
+
+// checksum calculates the SHA256 of a given file.
+func checksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// checksums calculates the checksum of the files with a given extension of a directory and its subdirectories,
+// skipping the ones that are excluded.
+func checksums(dirPath string, extension string, exclude func(fileName string) bool) (map[string]string, error) {
+	found, err := files.ListFiles(extension, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(found))
+	for name := range found {
+		if exclude != nil && exclude(path.Base(name)) {
+			continue
+		}
+		sum, err := checksum(path.Join(dirPath, name))
+		if err != nil {
+			return nil, err
+		}
+		result[name] = sum
+	}
+	return result, nil
+}
+
+// AddSources registers all the files of the proto directory, except the excluded ones.
+func (m *Manifest) AddSources(dirPath string, exclude func(fileName string) bool) error {
+	sources, err := checksums(dirPath, "", exclude)
+	if err != nil {
+		return fmt.Errorf("cannot calculate checksum of sources: %w", err)
+	}
+	for name, sum := range sources {
+		m.Sources[name] = sum
+	}
+	return nil
+}
+
+// AddImports registers the protos of a dependency located on a given path of the project.
+func (m *Manifest) AddImports(dependency string, dirPath string) error {
+	imports, err := checksums(dirPath, ProtoExtension, nil)
+	if err != nil {
+		return fmt.Errorf("cannot calculate checksum of imports: %w", err)
+	}
+	for name, sum := range imports {
+		m.Imports[path.Join(dependency, name)] = sum
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys(entries map[string]string) []string {
+	result := make([]string, 0, len(entries))
+	for key := range entries {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Seal calculates the fingerprint of the manifest. It must be called once all the inputs are registered.
+func (m *Manifest) Seal() {
+	h := sha256.New()
+	fmt.Fprintf(h, "format:%d\ngenerator:%s\nsettings:%s\n", m.FormatVersion, m.Generator, m.Settings)
+	for _, name := range sortedKeys(m.Sources) {
+		fmt.Fprintf(h, "source:%s:%s\n", name, m.Sources[name])
+	}
+	for _, name := range sortedKeys(m.Imports) {
+		fmt.Fprintf(h, "import:%s:%s\n", name, m.Imports[name])
+	}
+	m.Fingerprint = fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// compareChecksums describes the differences among two sets of checksums.
+func compareChecksums(kind string, current map[string]string, previous map[string]string) []string {
+	result := make([]string, 0)
+	for _, name := range sortedKeys(current) {
+		previousSum, exists := previous[name]
+		if !exists {
+			result = append(result, fmt.Sprintf("%s %s added", kind, name))
+		} else if previousSum != current[name] {
+			result = append(result, fmt.Sprintf("%s %s modified", kind, name))
+		}
+	}
+	for _, name := range sortedKeys(previous) {
+		if _, exists := current[name]; !exists {
+			result = append(result, fmt.Sprintf("%s %s removed", kind, name))
+		}
+	}
+	return result
+}
+
+// Differences describes the inputs that changed since a previous manifest. An empty result means that the
+// generation would produce the same code.
+func (m *Manifest) Differences(previous *Manifest) []string {
+	if m.Fingerprint == previous.Fingerprint {
+		return []string{}
+	}
+	result := make([]string, 0)
+	if m.FormatVersion != previous.FormatVersion {
+		result = append(result, fmt.Sprintf("manifest format changed from %d to %d", previous.FormatVersion, m.FormatVersion))
+	}
+	if m.Generator != previous.Generator {
+		result = append(result, fmt.Sprintf("generator changed from %q to %q", previous.Generator, m.Generator))
+	}
+	if m.Settings != previous.Settings {
+		result = append(result, fmt.Sprintf("settings changed from %q to %q", previous.Settings, m.Settings))
+	}
+	result = append(result, compareChecksums("source", m.Sources, previous.Sources)...)
+	result = append(result, compareChecksums("import", m.Imports, previous.Imports)...)
+	if len(result) == 0 {
+		result = append(result, "fingerprint changed")
+	}
+	return result
+}
+
+// StaleFiles returns the files written by a previous generation that are not written by the current one.
+func (m *Manifest) StaleFiles(previous *Manifest) []string {
+	current := make(map[string]bool, len(m.Files))
+	for _, name := range m.Files {
+		current[name] = true
+	}
+	result := make([]string, 0)
+	for _, name := range previous.Files {
+		if !current[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// Read loads the manifest stored on a repository. If the repository does not contain a manifest, nil is returned.
+func Read(repoPath string) (*Manifest, error) {
+	content, err := ioutil.ReadFile(path.Join(repoPath, FileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read manifest: %w", err)
+	}
+	return Parse(content)
+}
+
+// Parse builds a manifest from its serialized content.
+func Parse(content []byte) (*Manifest, error) {
+	result := &Manifest{}
+	if err := json.Unmarshal(content, result); err != nil {
+		return nil, fmt.Errorf("cannot parse manifest: %w", err)
+	}
+	return result, nil
+}
+
+// Write stores the manifest on a repository.
+func (m *Manifest) Write(repoPath string) error {
+	sort.Strings(m.Files)
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot build manifest: %w", err)
+	}
+	if err := ioutil.WriteFile(path.Join(repoPath, FileName), append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("cannot write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// sources with the files of the proto directory used on the tests.
+var sources = map[string]string{
+	"agenda.proto":     "syntax = \"proto3\";\npackage agenda;\n",
+	"sub/types.proto":  "syntax = \"proto3\";\npackage agenda.sub;\n",
+	".protolangs":      "go\n",
+	"README.md":        "Agenda protos\n",
+	"sub/notes.txt":    "notes\n",
+	"sub/deep/x.proto": "syntax = \"proto3\";\npackage agenda.sub.deep;\n",
+}
+
+// imports with the files of a dependency used on the tests.
+var imports = map[string]string{
+	"common.proto": "syntax = \"proto3\";\npackage common;\n",
+	"README.md":    "Not a proto\n",
+}
+
+// writeFiles stores a set of files on a directory following the given order of names.
+func writeFiles(t *testing.T, dirPath string, content map[string]string, order []string) {
+	t.Helper()
+	for _, name := range order {
+		filePath := filepath.Join(dirPath, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// names returns the keys of a map in order, or in reverse order.
+func names(content map[string]string, reverse bool) []string {
+	result := sortedKeys(content)
+	if reverse {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+	return result
+}
+
+// excludeProtoLangs skips the language settings as the manager does.
+func excludeProtoLangs(fileName string) bool {
+	return fileName == ".protolangs"
+}
+
+// fixture with the directories used to build a manifest.
+type fixture struct {
+	sourcesPath string
+	importsPath string
+}
+
+// newFixture writes the sources and imports creating the files in the given order.
+func newFixture(t *testing.T, reverse bool) *fixture {
+	basePath := t.TempDir()
+	result := &fixture{sourcesPath: filepath.Join(basePath, "agenda"), importsPath: filepath.Join(basePath, "common")}
+	writeFiles(t, result.sourcesPath, sources, names(sources, reverse))
+	writeFiles(t, result.importsPath, imports, names(imports, reverse))
+	return result
+}
+
+// build creates the sealed manifest of the fixture.
+func (f *fixture) build(t *testing.T, generator string, settings string) *Manifest {
+	t.Helper()
+	result := NewManifest(generator, settings)
+	if err := result.AddSources(f.sourcesPath, excludeProtoLangs); err != nil {
+		t.Fatal(err)
+	}
+	if err := result.AddImports("common", f.importsPath); err != nil {
+		t.Fatal(err)
+	}
+	result.Seal()
+	return result
+}
+
+func TestManifestInputs(t *testing.T) {
+	built := newFixture(t, false).build(t, "docker:image@sha256:1", "go")
+	expectedSources := []string{"README.md", "agenda.proto", "sub/deep/x.proto", "sub/notes.txt", "sub/types.proto"}
+	if !reflect.DeepEqual(sortedKeys(built.Sources), expectedSources) {
+		t.Errorf("unexpected sources %v", sortedKeys(built.Sources))
+	}
+	if !reflect.DeepEqual(sortedKeys(built.Imports), []string{"common/common.proto"}) {
+		t.Errorf("unexpected imports %v", sortedKeys(built.Imports))
+	}
+	if built.Fingerprint == "" {
+		t.Errorf("fingerprint not calculated")
+	}
+}
+
+func TestFingerprintIsStable(t *testing.T) {
+	first := newFixture(t, false).build(t, "generator", "go")
+	second := newFixture(t, true).build(t, "generator", "go")
+	if first.Fingerprint != second.Fingerprint {
+		t.Errorf("fingerprint depends on the order of the files: %s != %s", first.Fingerprint, second.Fingerprint)
+	}
+	if differences := second.Differences(first); len(differences) != 0 {
+		t.Errorf("unexpected differences %v", differences)
+	}
+
+	// Registering the same inputs in a different order does not change the fingerprint either.
+	f := newFixture(t, false)
+	reordered := NewManifest("generator", "go")
+	if err := reordered.AddImports("common", f.importsPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := reordered.AddSources(f.sourcesPath, excludeProtoLangs); err != nil {
+		t.Fatal(err)
+	}
+	reordered.Seal()
+	if reordered.Fingerprint != first.Fingerprint {
+		t.Errorf("fingerprint depends on the registration order")
+	}
+}
+
+func TestFingerprintChanges(t *testing.T) {
+	testCases := []struct {
+		name       string
+		modify     func(t *testing.T, f *fixture)
+		generator  string
+		settings   string
+		difference string
+	}{
+		{"source edited", func(t *testing.T, f *fixture) {
+			writeFiles(t, f.sourcesPath, map[string]string{"agenda.proto": "syntax = \"proto3\";\npackage agenda.v2;\n"}, []string{"agenda.proto"})
+		}, "generator", "go", "source agenda.proto modified"},
+		{"nested source added", func(t *testing.T, f *fixture) {
+			writeFiles(t, f.sourcesPath, map[string]string{"sub/new.proto": "syntax = \"proto3\";\n"}, []string{"sub/new.proto"})
+		}, "generator", "go", "source sub/new.proto added"},
+		{"source removed", func(t *testing.T, f *fixture) {
+			if err := os.Remove(filepath.Join(f.sourcesPath, "sub", "types.proto")); err != nil {
+				t.Fatal(err)
+			}
+		}, "generator", "go", "source sub/types.proto removed"},
+		{"dependency import edited", func(t *testing.T, f *fixture) {
+			writeFiles(t, f.importsPath, map[string]string{"common.proto": "syntax = \"proto3\";\npackage common.v2;\n"}, []string{"common.proto"})
+		}, "generator", "go", "import common/common.proto modified"},
+		{"generator identity changed", nil, "generator:v2", "go", `generator changed from "generator" to "generator:v2"`},
+		{"settings changed", nil, "generator", "go{gateway}", `settings changed from "go" to "go{gateway}"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t, false)
+			previous := f.build(t, "generator", "go")
+			if tc.modify != nil {
+				tc.modify(t, f)
+			}
+			current := f.build(t, tc.generator, tc.settings)
+			if current.Fingerprint == previous.Fingerprint {
+				t.Fatalf("fingerprint did not change")
+			}
+			differences := current.Differences(previous)
+			if !reflect.DeepEqual(differences, []string{tc.difference}) {
+				t.Errorf("expected difference %q, got %v", tc.difference, differences)
+			}
+		})
+	}
+}
+
+func TestFingerprintIgnores(t *testing.T) {
+	f := newFixture(t, false)
+	previous := f.build(t, "generator", "go")
+	// Language settings are part of the settings, and non proto files of the dependencies are not imported.
+	writeFiles(t, f.sourcesPath, map[string]string{".protolangs": "go\npython\n"}, []string{".protolangs"})
+	writeFiles(t, f.importsPath, map[string]string{"README.md": "Changed\n"}, []string{"README.md"})
+	current := f.build(t, "generator", "go")
+	if current.Fingerprint != previous.Fingerprint {
+		t.Errorf("fingerprint changed for ignored files: %v", current.Differences(previous))
+	}
+}
+
+func TestManifestReadWrite(t *testing.T) {
+	repoPath := t.TempDir()
+	missing, err := Read(repoPath)
+	if err != nil || missing != nil {
+		t.Fatalf("expected no manifest, got %v, %v", missing, err)
+	}
+	built := newFixture(t, false).build(t, "generator", "go")
+	built.Files = []string{"b.go", "a.go"}
+	if err := built.Write(repoPath); err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, built) {
+		t.Errorf("manifest changed after write and read: %+v", read)
+	}
+	current := NewManifest("generator", "go")
+	current.Files = []string{"a.go", "c.go"}
+	if stale := current.StaleFiles(read); !reflect.DeepEqual(stale, []string{"b.go"}) {
+		t.Errorf("unexpected stale files %v", stale)
+	}
+	if _, err := Parse([]byte("not json")); err == nil {
+		t.Errorf("expected error parsing invalid manifest")
+	}
+}
@@ -0,0 +1,161 @@
+package packaging
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+)
+
+// InitFileName with the name of the files marking python packages.
+const InitFileName = "__init__.py"
+
+// localVersionChars matches the characters that are not allowed on the local segment of a python version.
+var localVersionChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// FileTemplate structure with a manifest written on the root of the target repository.
+type FileTemplate struct {
+	// Path of the manifest relative to the repository (e.g., package.json).
+	Path string
+	// Template with the content of the manifest using the text/template syntax with the fields of Data.
+	Template string
+}
+
+// Data structure with the information available to the manifest templates.
+type Data struct {
+	// Name of the package, which is the name of the target repository.
+	Name string
+	// Group with the namespace of the package (e.g., the maven groupId).
+	Group string
+	// Directory containing the protos relative to the base path.
+	Directory string
+	// Language of the generated code.
+	Language string
+	// Description of the package.
+	Description string
+	// Version formatted with the conventions of the package manager of the language (e.g., 1.2.0rc1 for python).
+	Version string
+	// Tag with the version tag of the repository (e.g., v1.2.0-rc.1).
+	Tag string
+	// Files with the paths of the generated files.
+	Files []string
+	// Modules with the python modules found on the root of the repository.
+	Modules []string
+	// Packages with the python packages, using dotted names.
+	Packages []string
+}
+
+// Templates returns the manifests of a language. The configured ones replace the default ones.
+func Templates(language string, configured map[string][]FileTemplate) []FileTemplate {
+	if templates, exists := configured[language]; exists {
+		return templates
+	}
+	return defaultTemplates[language]
+}
+
+// Version formats a version with the conventions of the package manager of a language. Python follows PEP 440, so
+// alpha, beta and rc pre-releases are mapped to their PEP 440 counterparts, and the rest are development releases
+// with the identifiers as local version (e.g., v1.2.0-feature-x.3 becomes 1.2.0.dev3+feature.x). The rest of the
+// languages use the semantic version without the v prefix.
+func Version(language string, version *repo.Version) string {
+	if language != "python" {
+		return strings.TrimPrefix(version.String(), "v")
+	}
+	result := fmt.Sprintf("%d.%d.%d", version.Major, version.Minor, version.Patch)
+	if !version.IsPreRelease() {
+		return result
+	}
+	identifiers := version.PreRelease
+	number := 0
+	if parsed, err := strconv.Atoi(identifiers[len(identifiers)-1]); err == nil {
+		number = parsed
+		identifiers = identifiers[:len(identifiers)-1]
+	}
+	if len(identifiers) == 1 {
+		switch strings.ToLower(identifiers[0]) {
+		case "a", "alpha":
+			return fmt.Sprintf("%sa%d", result, number)
+		case "b", "beta":
+			return fmt.Sprintf("%sb%d", result, number)
+		case "c", "rc":
+			return fmt.Sprintf("%src%d", result, number)
+		}
+	}
+	result = fmt.Sprintf("%s.dev%d", result, number)
+	if local := strings.Trim(localVersionChars.ReplaceAllString(strings.ToLower(strings.Join(identifiers, ".")), "."), "."); local != "" {
+		result = fmt.Sprintf("%s+%s", result, local)
+	}
+	return result
+}
+
+// Render builds the content of the manifests indexed by their path.
+func Render(templates []FileTemplate, data Data) (map[string]string, error) {
+	result := make(map[string]string, len(templates))
+	for _, fileTemplate := range templates {
+		tmpl, err := template.New(fileTemplate.Path).Parse(fileTemplate.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template of %s: %w", fileTemplate.Path, err)
+		}
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return nil, fmt.Errorf("cannot render %s: %w", fileTemplate.Path, err)
+		}
+		result[fileTemplate.Path] = sb.String()
+	}
+	return result, nil
+}
+
+// Write stores the manifests on a directory.
+func Write(dirPath string, manifests map[string]string) error {
+	for filePath, content := range manifests {
+		target := path.Join(dirPath, filePath)
+		if err := os.MkdirAll(path.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(target, []byte(content), 0644); err != nil {
+			return fmt.Errorf("cannot write %s: %w", filePath, err)
+		}
+	}
+	return nil
+}
+
+// PythonLayout creates the missing __init__.py files of the directories containing generated python files and
+// their parents, so they can be imported as packages. It returns the modules on the root of the directory and the
+// packages, both sorted.
+func PythonLayout(dirPath string, generated []string) ([]string, []string, error) {
+	modules := make([]string, 0)
+	packages := make(map[string]bool, 0)
+	for _, filePath := range generated {
+		if !strings.HasSuffix(filePath, ".py") || path.Base(filePath) == InitFileName {
+			continue
+		}
+		dir := path.Dir(filePath)
+		if dir == "." {
+			modules = append(modules, strings.TrimSuffix(filePath, ".py"))
+			continue
+		}
+		for ; dir != "."; dir = path.Dir(dir) {
+			packages[strings.ReplaceAll(dir, "/", ".")] = true
+			initPath := path.Join(dirPath, dir, InitFileName)
+			if _, err := os.Stat(initPath); os.IsNotExist(err) {
+				if err := ioutil.WriteFile(initPath, []byte{}, 0644); err != nil {
+					return nil, nil, fmt.Errorf("cannot create %s: %w", initPath, err)
+				}
+			}
+		}
+	}
+	result := make([]string, 0, len(packages))
+	for pkg := range packages {
+		result = append(result, pkg)
+	}
+	sort.Strings(modules)
+	sort.Strings(result)
+	return modules, result, nil
+}
@@ -0,0 +1,191 @@
+package packaging
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gpm-project/grpc-proto-manager/internal/pkg/repo"
+)
+
+// mustVersion parses a version tag or fails the test.
+func mustVersion(t *testing.T, tag string) *repo.Version {
+	t.Helper()
+	version, err := repo.FromTag(tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+// render builds the default manifest of a language, which is expected to be a single file.
+func render(t *testing.T, language string, data Data) string {
+	t.Helper()
+	templates := Templates(language, nil)
+	if len(templates) != 1 {
+		t.Fatalf("expected a single manifest for %s, got %d", language, len(templates))
+	}
+	manifests, err := Render(templates, data)
+	if err != nil {
+		t.Fatalf("cannot render %s manifest: %v", language, err)
+	}
+	return manifests[templates[0].Path]
+}
+
+func TestVersion(t *testing.T) {
+	testCases := []struct {
+		language string
+		tag      string
+		expected string
+	}{
+		{"node", "v1.2.3", "1.2.3"},
+		{"node", "v1.2.3-rc.1", "1.2.3-rc.1"},
+		{"java", "v2.0.0-feature-x.3", "2.0.0-feature-x.3"},
+		{"python", "v1.2.3", "1.2.3"},
+		{"python", "v1.2.0-alpha.2", "1.2.0a2"},
+		{"python", "v1.2.0-beta.1", "1.2.0b1"},
+		{"python", "v1.2.0-rc.1", "1.2.0rc1"},
+		{"python", "v1.2.0-feature-x.3", "1.2.0.dev3+feature.x"},
+		{"python", "v1.2.0-Feature-Y", "1.2.0.dev0+feature.y"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.language+" "+tc.tag, func(t *testing.T) {
+			if result := Version(tc.language, mustVersion(t, tc.tag)); result != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderNode(t *testing.T) {
+	content := render(t, "node", Data{
+		Name:        "grpc-agenda-node",
+		Description: "gRPC code of agenda",
+		Version:     Version("node", mustVersion(t, "v1.4.0-rc.2")),
+		Files:       []string{"agenda_pb.js", "agenda_grpc_pb.js"},
+	})
+	var manifest struct {
+		Name         string
+		Version      string
+		Description  string
+		Files        []string
+		Dependencies map[string]string
+	}
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		t.Fatalf("invalid package.json: %v\n%s", err, content)
+	}
+	if manifest.Name != "grpc-agenda-node" || manifest.Version != "1.4.0-rc.2" || manifest.Description != "gRPC code of agenda" {
+		t.Errorf("unexpected package.json fields: %+v", manifest)
+	}
+	if !reflect.DeepEqual(manifest.Files, []string{"agenda_pb.js", "agenda_grpc_pb.js"}) {
+		t.Errorf("unexpected files %v", manifest.Files)
+	}
+	if _, exists := manifest.Dependencies["@grpc/grpc-js"]; !exists {
+		t.Errorf("expected grpc dependency, got %v", manifest.Dependencies)
+	}
+}
+
+func TestRenderJava(t *testing.T) {
+	content := render(t, "java", Data{
+		Name:        "grpc-agenda-java",
+		Group:       "com.example",
+		Description: "gRPC code of agenda",
+		Version:     Version("java", mustVersion(t, "v0.3.1")),
+	})
+	var manifest struct {
+		GroupID     string `xml:"groupId"`
+		ArtifactID  string `xml:"artifactId"`
+		Version     string `xml:"version"`
+		Description string `xml:"description"`
+	}
+	if err := xml.Unmarshal([]byte(content), &manifest); err != nil {
+		t.Fatalf("invalid pom.xml: %v\n%s", err, content)
+	}
+	expected := struct {
+		GroupID     string `xml:"groupId"`
+		ArtifactID  string `xml:"artifactId"`
+		Version     string `xml:"version"`
+		Description string `xml:"description"`
+	}{"com.example", "grpc-agenda-java", "0.3.1", "gRPC code of agenda"}
+	if manifest != expected {
+		t.Errorf("expected %+v, got %+v", expected, manifest)
+	}
+}
+
+func TestRenderPython(t *testing.T) {
+	content := render(t, "python", Data{
+		Name:        "grpc-agenda-python",
+		Description: "gRPC code of agenda",
+		Version:     Version("python", mustVersion(t, "v1.2.0-rc.1")),
+		Modules:     []string{"agenda_pb2", "agenda_pb2_grpc"},
+		Packages:    []string{"agenda", "agenda.v1"},
+	})
+	expected := []string{
+		`name = "grpc-agenda-python"`,
+		`version = "1.2.0rc1"`,
+		`description = "gRPC code of agenda"`,
+		`py-modules = ["agenda_pb2", "agenda_pb2_grpc"]`,
+		`packages = ["agenda", "agenda.v1"]`,
+	}
+	lines := strings.Split(content, "\n")
+	for _, line := range expected {
+		found := false
+		for _, candidate := range lines {
+			found = found || candidate == line
+		}
+		if !found {
+			t.Errorf("expected line %s on pyproject.toml:\n%s", line, content)
+		}
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	configured := map[string][]FileTemplate{"node": {{Path: "custom.json", Template: `{"v": "{{.Tag}}"}`}}}
+	templates := Templates("node", configured)
+	manifests, err := Render(templates, Data{Tag: "v1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(manifests, map[string]string{"custom.json": `{"v": "v1.0.0"}`}) {
+		t.Errorf("configured templates must replace the default ones, got %v", manifests)
+	}
+	if templates := Templates("go", configured); len(templates) != 0 {
+		t.Errorf("unexpected templates for go: %v", templates)
+	}
+	if _, err := Render([]FileTemplate{{Path: "broken", Template: "{{.Missing"}}, Data{}); err == nil {
+		t.Errorf("expected error on invalid template")
+	}
+}
+
+func TestPythonLayout(t *testing.T) {
+	dirPath := t.TempDir()
+	generated := []string{"agenda_pb2.py", "agenda/v1/entry_pb2.py", "agenda/v1/entry_pb2_grpc.py", "README.md"}
+	for _, filePath := range generated {
+		if err := os.MkdirAll(filepath.Join(dirPath, filepath.Dir(filePath)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dirPath, filePath), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	modules, packages, err := PythonLayout(dirPath, generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(modules, []string{"agenda_pb2"}) {
+		t.Errorf("unexpected modules %v", modules)
+	}
+	if !reflect.DeepEqual(packages, []string{"agenda", "agenda.v1"}) {
+		t.Errorf("unexpected packages %v", packages)
+	}
+	for _, initPath := range []string{"agenda/__init__.py", "agenda/v1/__init__.py"} {
+		if _, err := os.Stat(filepath.Join(dirPath, initPath)); err != nil {
+			t.Errorf("expected %s to be created: %v", initPath, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package packaging
+
+// DefaultPythonTemplate with the template of the pyproject.toml file of the python code.
+const DefaultPythonTemplate = `[build-system]
+requires = ["setuptools>=61.0"]
+build-backend = "setuptools.build_meta"
+
+[project]
+name = "{{.Name}}"
+version = "{{.Version}}"
+description = "{{.Description}}"
+dependencies = ["grpcio", "protobuf"]
+
+[tool.setuptools]
+py-modules = [{{range $index, $module := .Modules}}{{if $index}}, {{end}}"{{$module}}"{{end}}]
+packages = [{{range $index, $pkg := .Packages}}{{if $index}}, {{end}}"{{$pkg}}"{{end}}]
+`
+
+// DefaultJavaTemplate with the template of the pom.xml file of the java code.
+const DefaultJavaTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0"
+         xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+         xsi:schemaLocation="http://maven.apache.org/POM/4.0.0 http://maven.apache.org/xsd/maven-4.0.0.xsd">
+  <modelVersion>4.0.0</modelVersion>
+  <groupId>{{.Group}}</groupId>
+  <artifactId>{{.Name}}</artifactId>
+  <version>{{.Version}}</version>
+  <description>{{.Description}}</description>
+  <properties>
+    <project.build.sourceEncoding>UTF-8</project.build.sourceEncoding>
+    <maven.compiler.source>1.8</maven.compiler.source>
+    <maven.compiler.target>1.8</maven.compiler.target>
+    <grpc.version>1.38.0</grpc.version>
+    <protobuf.version>3.17.2</protobuf.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>io.grpc</groupId>
+      <artifactId>grpc-protobuf</artifactId>
+      <version>${grpc.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>io.grpc</groupId>
+      <artifactId>grpc-stub</artifactId>
+      <version>${grpc.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>com.google.protobuf</groupId>
+      <artifactId>protobuf-java</artifactId>
+      <version>${protobuf.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>javax.annotation</groupId>
+      <artifactId>javax.annotation-api</artifactId>
+      <version>1.3.2</version>
+    </dependency>
+  </dependencies>
+  <build>
+    <sourceDirectory>.</sourceDirectory>
+  </build>
+</project>
+`
+
+// DefaultNodeTemplate with the template of the package.json file of the node code.
+const DefaultNodeTemplate = `{
+  "name": "{{.Name}}",
+  "version": "{{.Version}}",
+  "description": "{{.Description}}",
+  "files": [{{range $index, $file := .Files}}{{if $index}}, {{end}}"{{$file}}"{{end}}],
+  "dependencies": {
+    "@grpc/grpc-js": "^1.3.2",
+    "google-protobuf": "^3.17.2"
+  }
+}
+`
+
+// defaultTemplates with the manifests written for each language if none are configured.
+var defaultTemplates = map[string][]FileTemplate{
+	"python": {{Path: "pyproject.toml", Template: DefaultPythonTemplate}},
+	"java":   {{Path: "pom.xml", Template: DefaultJavaTemplate}},
+	"node":   {{Path: "package.json", Template: DefaultNodeTemplate}},
+}
@@ -0,0 +1,185 @@
+package parser
+
+// ProtoFile with the relevant elements of a parsed .proto file.
+type ProtoFile struct {
+	// Path of the file that has been parsed.
+	Path string
+	// Name of the file relative to the parsed directory (e.g., sub/types.proto). For files parsed individually, it
+	// contains the base name of the file.
+	Name string
+	// Syntax declared on the file (e.g., proto3).
+	Syntax string
+	// Package declared on the file.
+	Package string
+	// Imports with the list of files imported by this one.
+	Imports []*Import
+	// Options with the file level options.
+	Options map[string]string
+	// Messages defined at the top level of the file.
+	Messages []*Message
+	// Enums defined at the top level of the file.
+	Enums []*Enum
+	// Services defined on the file.
+	Services []*Service
+}
+
+// Import structure with an import statement.
+type Import struct {
+	// Path of the imported file.
+	Path string
+	// Modifier with the optional public/weak modifier.
+	Modifier string
+	// Line where the import is declared.
+	Line int
+}
+
+// Message structure with the definition of a proto message.
+type Message struct {
+	// Name of the message.
+	Name string
+	// FullName with the fully qualified name of the message including the package.
+	FullName string
+	// Comment with the leading comment of the message.
+	Comment string
+	// Line where the message is declared.
+	Line int
+	// Fields of the message, including those inside oneof blocks.
+	Fields []*Field
+	// Messages nested in this one.
+	Messages []*Message
+	// Enums nested in this message.
+	Enums []*Enum
+	// ReservedNumbers with the ranges of reserved field numbers.
+	ReservedNumbers []Range
+	// ReservedNames with the reserved field names.
+	ReservedNames []string
+	// Options with the message level options.
+	Options map[string]string
+}
+
+// Field structure with the definition of a message field.
+type Field struct {
+	// Name of the field.
+	Name string
+	// Type of the field. Map fields are represented as map<key,value>.
+	Type string
+	// Label with the optional repeated/optional/required modifier.
+	Label string
+	// Number assigned to the field.
+	Number int
+	// OneOf with the name of the oneof block containing the field, if any.
+	OneOf string
+	// Comment with the leading comment of the field.
+	Comment string
+	// Line where the field is declared.
+	Line int
+	// Options with the field options.
+	Options map[string]string
+}
+
+// Enum structure with the definition of a proto enum.
+type Enum struct {
+	// Name of the enum.
+	Name string
+	// FullName with the fully qualified name of the enum including the package.
+	FullName string
+	// Comment with the leading comment of the enum.
+	Comment string
+	// Line where the enum is declared.
+	Line int
+	// Values of the enum.
+	Values []*EnumValue
+	// ReservedNumbers with the ranges of reserved values.
+	ReservedNumbers []Range
+	// ReservedNames with the reserved value names.
+	ReservedNames []string
+	// Options with the enum level options.
+	Options map[string]string
+}
+
+// EnumValue structure with a single value of an enum.
+type EnumValue struct {
+	// Name of the value.
+	Name string
+	// Number associated with the value.
+	Number int
+	// Comment with the leading comment of the value.
+	Comment string
+	// Line where the value is declared.
+	Line int
+	// Options with the value options.
+	Options map[string]string
+}
+
+// Service structure with the definition of a gRPC service.
+type Service struct {
+	// Name of the service.
+	Name string
+	// FullName with the fully qualified name of the service including the package.
+	FullName string
+	// Comment with the leading comment of the service.
+	Comment string
+	// Line where the service is declared.
+	Line int
+	// RPCs defined on the service.
+	RPCs []*RPC
+	// Options with the service level options.
+	Options map[string]string
+}
+
+// RPC structure with the definition of a service method.
+type RPC struct {
+	// Name of the method.
+	Name string
+	// Comment with the leading comment of the method.
+	Comment string
+	// Line where the method is declared.
+	Line int
+	// RequestType with the name of the input message.
+	RequestType string
+	// ResponseType with the name of the output message.
+	ResponseType string
+	// ClientStreaming is true if the request is a stream.
+	ClientStreaming bool
+	// ServerStreaming is true if the response is a stream.
+	ServerStreaming bool
+	// Options with the method options.
+	Options map[string]string
+}
+
+// Range with an inclusive range of numbers.
+type Range struct {
+	// From with the first number of the range.
+	From int
+	// To with the last number of the range.
+	To int
+}
+
+// Contains checks if a number is part of the range.
+func (r Range) Contains(number int) bool {
+	return number >= r.From && number <= r.To
+}
+
+// AllMessages returns the messages of the file including the nested ones.
+func (pf *ProtoFile) AllMessages() []*Message {
+	result := make([]*Message, 0)
+	var collect func(messages []*Message)
+	collect = func(messages []*Message) {
+		for _, msg := range messages {
+			result = append(result, msg)
+			collect(msg.Messages)
+		}
+	}
+	collect(pf.Messages)
+	return result
+}
+
+// AllEnums returns the enums of the file including those nested inside messages.
+func (pf *ProtoFile) AllEnums() []*Enum {
+	result := make([]*Enum, 0)
+	result = append(result, pf.Enums...)
+	for _, msg := range pf.AllMessages() {
+		result = append(result, msg.Enums...)
+	}
+	return result
+}
@@ -0,0 +1,715 @@
+package parser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProtoExtension with the extension of the proto definition files.
+const ProtoExtension = ".proto"
+
+// maxFieldNumber with the value used to resolve the max keyword on reserved ranges.
+const maxFieldNumber = 536870911
+
+// protoParser structure with the state of the parsing of a single file.
+type protoParser struct {
+	fileName string
+	tokens   []token
+	pos      int
+	file     *ProtoFile
+}
+
+// ParseFile parses a .proto file from disk.
+func ParseFile(filePath string) (*ProtoFile, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(filePath, string(content))
+}
+
+// ParseDirectory parses all the .proto files found on a directory. Subdirectories are not inspected. The resulting
+// files are sorted by name.
+func ParseDirectory(dirPath string) ([]*ProtoFile, error) {
+	fileInfo, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ProtoFile, 0)
+	for _, info := range fileInfo {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ProtoExtension) {
+			parsed, err := ParseFile(path.Join(dirPath, info.Name()))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, parsed)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result, nil
+}
+
+// ParseTree parses all the .proto files found on a directory and its subdirectories, skipping hidden ones. The
+// name of each file is set relative to the directory, and the resulting files are sorted by name.
+func ParseTree(dirPath string) ([]*ProtoFile, error) {
+	result := make([]*ProtoFile, 0)
+	err := filepath.Walk(dirPath, func(currentPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if currentPath != dirPath && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(info.Name(), ProtoExtension) {
+			return nil
+		}
+		parsed, err := ParseFile(currentPath)
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(dirPath, currentPath)
+		if err != nil {
+			return err
+		}
+		parsed.Name = filepath.ToSlash(relative)
+		result = append(result, parsed)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// Parse builds the representation of a proto file from its content.
+func Parse(fileName string, content string) (*ProtoFile, error) {
+	tokens, err := newScanner(content).tokenize()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	p := &protoParser{
+		fileName: fileName,
+		tokens:   tokens,
+		file: &ProtoFile{
+			Path:     fileName,
+			Name:     path.Base(fileName),
+			Imports:  make([]*Import, 0),
+			Options:  make(map[string]string, 0),
+			Messages: make([]*Message, 0),
+			Enums:    make([]*Enum, 0),
+			Services: make([]*Service, 0),
+		},
+	}
+	if err := p.parseFile(); err != nil {
+		return nil, err
+	}
+	return p.file, nil
+}
+
+// current returns the token being processed.
+func (p *protoParser) current() token {
+	return p.tokens[p.pos]
+}
+
+// advance returns the current token and moves to the following one.
+func (p *protoParser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != eofToken {
+		p.pos++
+	}
+	return tok
+}
+
+// is checks if the current token has the given text.
+func (p *protoParser) is(text string) bool {
+	tok := p.current()
+	return tok.kind != stringToken && tok.kind != eofToken && tok.text == text
+}
+
+// errorf builds a parsing error with the location of the current token.
+func (p *protoParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d: %s", p.fileName, p.current().line, fmt.Sprintf(format, args...))
+}
+
+// expect consumes a token with the given text or fails.
+func (p *protoParser) expect(text string) error {
+	if !p.is(text) {
+		return p.errorf("expected %q, found %q", text, p.current().text)
+	}
+	p.advance()
+	return nil
+}
+
+// expectIdent consumes an identifier and returns its text.
+func (p *protoParser) expectIdent() (string, error) {
+	tok := p.current()
+	if tok.kind != identToken {
+		return "", p.errorf("expected identifier, found %q", tok.text)
+	}
+	p.advance()
+	return tok.text, nil
+}
+
+// expectString consumes a string literal, including adjacent ones, and returns its value.
+func (p *protoParser) expectString() (string, error) {
+	if p.current().kind != stringToken {
+		return "", p.errorf("expected string, found %q", p.current().text)
+	}
+	var sb strings.Builder
+	for p.current().kind == stringToken {
+		sb.WriteString(p.advance().text)
+	}
+	return sb.String(), nil
+}
+
+// expectNumber consumes an integer, including its sign.
+func (p *protoParser) expectNumber() (int, error) {
+	sign := ""
+	if p.is("-") || p.is("+") {
+		sign = p.advance().text
+	}
+	text, err := p.expectIdent()
+	if err != nil {
+		return 0, err
+	}
+	if text == "max" {
+		return maxFieldNumber, nil
+	}
+	number, err := strconv.ParseInt(sign+text, 0, 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %s%s", sign, text)
+	}
+	return int(number), nil
+}
+
+// skipStatement skips tokens until the end of the current statement or block.
+func (p *protoParser) skipStatement() error {
+	depth := 0
+	for {
+		tok := p.advance()
+		switch {
+		case tok.kind == eofToken:
+			return p.errorf("unexpected end of file")
+		case tok.kind == symbolToken && tok.text == "{":
+			depth++
+		case tok.kind == symbolToken && tok.text == "}":
+			depth--
+			if depth <= 0 {
+				if p.is(";") {
+					p.advance()
+				}
+				return nil
+			}
+		case tok.kind == symbolToken && tok.text == ";" && depth == 0:
+			return nil
+		}
+	}
+}
+
+// parseFile parses the top level statements.
+func (p *protoParser) parseFile() error {
+	for p.current().kind != eofToken {
+		tok := p.current()
+		var err error
+		switch {
+		case p.is(";"):
+			p.advance()
+		case p.is("syntax") || p.is("edition"):
+			p.advance()
+			if err = p.expect("="); err == nil {
+				p.file.Syntax, err = p.expectString()
+				if err == nil {
+					err = p.expect(";")
+				}
+			}
+		case p.is("package"):
+			p.advance()
+			if p.file.Package, err = p.expectIdent(); err == nil {
+				err = p.expect(";")
+			}
+		case p.is("import"):
+			err = p.parseImport()
+		case p.is("option"):
+			err = p.parseOption(p.file.Options)
+		case p.is("message"):
+			var msg *Message
+			if msg, err = p.parseMessage(p.file.Package, tok.comment); err == nil {
+				p.file.Messages = append(p.file.Messages, msg)
+			}
+		case p.is("enum"):
+			var enum *Enum
+			if enum, err = p.parseEnum(p.file.Package, tok.comment); err == nil {
+				p.file.Enums = append(p.file.Enums, enum)
+			}
+		case p.is("service"):
+			var service *Service
+			if service, err = p.parseService(tok.comment); err == nil {
+				p.file.Services = append(p.file.Services, service)
+			}
+		case p.is("extend"):
+			err = p.skipStatement()
+		default:
+			return p.errorf("unexpected %q", tok.text)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseImport parses an import statement.
+func (p *protoParser) parseImport() error {
+	line := p.advance().line
+	modifier := ""
+	if p.is("public") || p.is("weak") {
+		modifier = p.advance().text
+	}
+	importPath, err := p.expectString()
+	if err != nil {
+		return err
+	}
+	p.file.Imports = append(p.file.Imports, &Import{Path: importPath, Modifier: modifier, Line: line})
+	return p.expect(";")
+}
+
+// parseOptionName reads the name of an option, including custom options such as (google.api.http).get.
+func (p *protoParser) parseOptionName() (string, error) {
+	var sb strings.Builder
+	for !p.is("=") {
+		tok := p.current()
+		if tok.kind == eofToken || tok.kind == stringToken || p.is(";") {
+			return "", p.errorf("invalid option name")
+		}
+		sb.WriteString(p.advance().text)
+	}
+	return sb.String(), nil
+}
+
+// parseOptionValue reads the value of an option. Aggregated values are stored in a flattened form.
+func (p *protoParser) parseOptionValue() (string, error) {
+	if p.current().kind == stringToken {
+		return p.expectString()
+	}
+	if p.is("-") || p.is("+") {
+		sign := p.advance().text
+		value, err := p.expectIdent()
+		return sign + value, err
+	}
+	if !p.is("{") {
+		return p.expectIdent()
+	}
+	// Aggregated value, keep the tokens to be able to detect changes.
+	parts := make([]string, 0)
+	depth := 0
+	for {
+		tok := p.advance()
+		if tok.kind == eofToken {
+			return "", p.errorf("unexpected end of file")
+		}
+		if tok.kind == stringToken {
+			parts = append(parts, strconv.Quote(tok.text))
+		} else {
+			parts = append(parts, tok.text)
+		}
+		if tok.kind == symbolToken && tok.text == "{" {
+			depth++
+		} else if tok.kind == symbolToken && tok.text == "}" {
+			depth--
+			if depth == 0 {
+				return strings.Join(parts, " "), nil
+			}
+		}
+	}
+}
+
+// parseOption parses an option statement storing it on the given map.
+func (p *protoParser) parseOption(options map[string]string) error {
+	p.advance()
+	name, err := p.parseOptionName()
+	if err != nil {
+		return err
+	}
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	value, err := p.parseOptionValue()
+	if err != nil {
+		return err
+	}
+	options[name] = value
+	return p.expect(";")
+}
+
+// parseInlineOptions parses the options found between brackets after a field or an enum value.
+func (p *protoParser) parseInlineOptions() (map[string]string, error) {
+	options := make(map[string]string, 0)
+	if !p.is("[") {
+		return options, nil
+	}
+	p.advance()
+	for {
+		name, err := p.parseOptionName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		value, err := p.parseOptionValue()
+		if err != nil {
+			return nil, err
+		}
+		options[name] = value
+		if p.is(",") {
+			p.advance()
+			continue
+		}
+		return options, p.expect("]")
+	}
+}
+
+// qualify builds the fully qualified name of an element.
+func qualify(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// parseReserved parses a reserved statement.
+func (p *protoParser) parseReserved() ([]Range, []string, error) {
+	p.advance()
+	ranges := make([]Range, 0)
+	names := make([]string, 0)
+	for {
+		if p.current().kind == stringToken {
+			name, err := p.expectString()
+			if err != nil {
+				return nil, nil, err
+			}
+			names = append(names, name)
+		} else {
+			from, err := p.expectNumber()
+			if err != nil {
+				return nil, nil, err
+			}
+			to := from
+			if p.is("to") {
+				p.advance()
+				if to, err = p.expectNumber(); err != nil {
+					return nil, nil, err
+				}
+			}
+			ranges = append(ranges, Range{From: from, To: to})
+		}
+		if p.is(",") {
+			p.advance()
+			continue
+		}
+		return ranges, names, p.expect(";")
+	}
+}
+
+// parseMessage parses a message definition.
+func (p *protoParser) parseMessage(scope string, comment string) (*Message, error) {
+	line := p.advance().line
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	msg := &Message{
+		Name:            name,
+		FullName:        qualify(scope, name),
+		Comment:         comment,
+		Line:            line,
+		Fields:          make([]*Field, 0),
+		Messages:        make([]*Message, 0),
+		Enums:           make([]*Enum, 0),
+		ReservedNumbers: make([]Range, 0),
+		ReservedNames:   make([]string, 0),
+		Options:         make(map[string]string, 0),
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	return msg, p.parseMessageBody(msg, "")
+}
+
+// parseMessageBody parses the elements of a message or a oneof block until the closing bracket.
+func (p *protoParser) parseMessageBody(msg *Message, oneOf string) error {
+	for !p.is("}") {
+		tok := p.current()
+		var err error
+		switch {
+		case tok.kind == eofToken:
+			return p.errorf("unexpected end of file")
+		case p.is(";"):
+			p.advance()
+		case p.is("option"):
+			err = p.parseOption(msg.Options)
+		case p.is("message") && oneOf == "":
+			var nested *Message
+			if nested, err = p.parseMessage(msg.FullName, tok.comment); err == nil {
+				msg.Messages = append(msg.Messages, nested)
+			}
+		case p.is("enum") && oneOf == "":
+			var nested *Enum
+			if nested, err = p.parseEnum(msg.FullName, tok.comment); err == nil {
+				msg.Enums = append(msg.Enums, nested)
+			}
+		case p.is("reserved") && oneOf == "":
+			var ranges []Range
+			var names []string
+			if ranges, names, err = p.parseReserved(); err == nil {
+				msg.ReservedNumbers = append(msg.ReservedNumbers, ranges...)
+				msg.ReservedNames = append(msg.ReservedNames, names...)
+			}
+		case (p.is("extensions") || p.is("extend")) && oneOf == "":
+			err = p.skipStatement()
+		case p.is("oneof") && oneOf == "":
+			p.advance()
+			var name string
+			if name, err = p.expectIdent(); err == nil {
+				if err = p.expect("{"); err == nil {
+					err = p.parseMessageBody(msg, name)
+				}
+			}
+		default:
+			var field *Field
+			if field, err = p.parseField(oneOf); err == nil {
+				msg.Fields = append(msg.Fields, field)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return p.expect("}")
+}
+
+// parseField parses a field definition, including map fields.
+func (p *protoParser) parseField(oneOf string) (*Field, error) {
+	tok := p.current()
+	field := &Field{OneOf: oneOf, Comment: tok.comment, Line: tok.line}
+	if p.is("repeated") || p.is("optional") || p.is("required") {
+		field.Label = p.advance().text
+	}
+	if p.is("map") && p.tokens[p.pos+1].text == "<" {
+		p.advance()
+		p.advance()
+		key, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		value, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+		field.Type = fmt.Sprintf("map<%s,%s>", key, value)
+	} else {
+		fieldType, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		field.Type = fieldType
+	}
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	field.Name = name
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	if field.Number, err = p.expectNumber(); err != nil {
+		return nil, err
+	}
+	if field.Options, err = p.parseInlineOptions(); err != nil {
+		return nil, err
+	}
+	return field, p.expect(";")
+}
+
+// parseEnum parses an enum definition.
+func (p *protoParser) parseEnum(scope string, comment string) (*Enum, error) {
+	line := p.advance().line
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	enum := &Enum{
+		Name:            name,
+		FullName:        qualify(scope, name),
+		Comment:         comment,
+		Line:            line,
+		Values:          make([]*EnumValue, 0),
+		ReservedNumbers: make([]Range, 0),
+		ReservedNames:   make([]string, 0),
+		Options:         make(map[string]string, 0),
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.is("}") {
+		tok := p.current()
+		switch {
+		case tok.kind == eofToken:
+			return nil, p.errorf("unexpected end of file")
+		case p.is(";"):
+			p.advance()
+		case p.is("option"):
+			if err := p.parseOption(enum.Options); err != nil {
+				return nil, err
+			}
+		case p.is("reserved"):
+			ranges, names, err := p.parseReserved()
+			if err != nil {
+				return nil, err
+			}
+			enum.ReservedNumbers = append(enum.ReservedNumbers, ranges...)
+			enum.ReservedNames = append(enum.ReservedNames, names...)
+		default:
+			valueName, err := p.expectIdent()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			number, err := p.expectNumber()
+			if err != nil {
+				return nil, err
+			}
+			options, err := p.parseInlineOptions()
+			if err != nil {
+				return nil, err
+			}
+			enum.Values = append(enum.Values, &EnumValue{Name: valueName, Number: number, Comment: tok.comment, Line: tok.line, Options: options})
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return enum, p.expect("}")
+}
+
+// parseService parses a service definition.
+func (p *protoParser) parseService(comment string) (*Service, error) {
+	line := p.advance().line
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	service := &Service{
+		Name:     name,
+		FullName: qualify(p.file.Package, name),
+		Comment:  comment,
+		Line:     line,
+		RPCs:     make([]*RPC, 0),
+		Options:  make(map[string]string, 0),
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.is("}") {
+		tok := p.current()
+		switch {
+		case tok.kind == eofToken:
+			return nil, p.errorf("unexpected end of file")
+		case p.is(";"):
+			p.advance()
+		case p.is("option"):
+			if err := p.parseOption(service.Options); err != nil {
+				return nil, err
+			}
+		case p.is("rpc"):
+			rpc, err := p.parseRPC(tok.comment)
+			if err != nil {
+				return nil, err
+			}
+			service.RPCs = append(service.RPCs, rpc)
+		default:
+			return nil, p.errorf("unexpected %q in service %s", tok.text, name)
+		}
+	}
+	return service, p.expect("}")
+}
+
+// parseRPCType parses the type of a request or a response including the stream modifier.
+func (p *protoParser) parseRPCType() (string, bool, error) {
+	if err := p.expect("("); err != nil {
+		return "", false, err
+	}
+	streaming := false
+	if p.is("stream") && p.tokens[p.pos+1].text != ")" {
+		p.advance()
+		streaming = true
+	}
+	typeName, err := p.expectIdent()
+	if err != nil {
+		return "", false, err
+	}
+	return typeName, streaming, p.expect(")")
+}
+
+// parseRPC parses a method definition.
+func (p *protoParser) parseRPC(comment string) (*RPC, error) {
+	line := p.advance().line
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	rpc := &RPC{Name: name, Comment: comment, Line: line, Options: make(map[string]string, 0)}
+	if rpc.RequestType, rpc.ClientStreaming, err = p.parseRPCType(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("returns"); err != nil {
+		return nil, err
+	}
+	if rpc.ResponseType, rpc.ServerStreaming, err = p.parseRPCType(); err != nil {
+		return nil, err
+	}
+	if p.is(";") {
+		p.advance()
+		return rpc, nil
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.is("}") {
+		switch {
+		case p.current().kind == eofToken:
+			return nil, p.errorf("unexpected end of file")
+		case p.is(";"):
+			p.advance()
+		case p.is("option"):
+			if err := p.parseOption(rpc.Options); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.errorf("unexpected %q in rpc %s", p.current().text, name)
+		}
+	}
+	return rpc, p.expect("}")
+}
@@ -0,0 +1,261 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const sampleProto = `// Header comment that is not attached to any element.
+syntax = "proto3";
+
+package agenda.v1;
+
+import "google/protobuf/timestamp.proto";
+import public "agenda/v1/common.proto";
+
+option go_package = "github.com/org/agenda-go/v1";
+option java_multiple_files = true;
+
+// Entry of the agenda.
+message Entry {
+  // Nested message with the location.
+  message Location {
+    string address = 1;
+    double latitude = 2 [deprecated = true];
+  }
+  enum Kind {
+    option allow_alias = true;
+    KIND_UNSPECIFIED = 0;
+    MEETING = 1; // trailing comment is ignored
+    REMINDER = 2 [(custom.label) = "reminder"];
+  }
+  reserved 4, 10 to 12, 20 to max;
+  reserved "legacy";
+
+  string entry_id = 1;
+  Location location = 2;
+  repeated string tags = 3;
+  map<string, int64> counters = 5;
+  oneof target {
+    // Person receiving the entry.
+    string person = 6;
+    string team = 7;
+  }
+  optional Kind kind = 8 [json_name = "type", (validate.rules).enum = {defined_only: true}];
+}
+
+/* Service managing the entries. */
+service AgendaService {
+  option (custom.service) = "agenda";
+  rpc Add(Entry) returns (Entry);
+  // Watch the changes.
+  rpc Watch(stream Entry) returns (stream Entry) {
+    option (google.api.http) = { get: "/v1/entries" };
+  }
+}
+`
+
+func TestParse(t *testing.T) {
+	parsed, err := Parse("agenda/v1/entry.proto", sampleProto)
+	if err != nil {
+		t.Fatalf("unable to parse: %v", err)
+	}
+	if parsed.Name != "entry.proto" || parsed.Syntax != "proto3" || parsed.Package != "agenda.v1" {
+		t.Errorf("unexpected file header: name=%s syntax=%s package=%s", parsed.Name, parsed.Syntax, parsed.Package)
+	}
+	expectedImports := []*Import{
+		{Path: "google/protobuf/timestamp.proto", Line: 6},
+		{Path: "agenda/v1/common.proto", Modifier: "public", Line: 7},
+	}
+	if !reflect.DeepEqual(parsed.Imports, expectedImports) {
+		t.Errorf("unexpected imports: %+v", parsed.Imports)
+	}
+	expectedOptions := map[string]string{"go_package": "github.com/org/agenda-go/v1", "java_multiple_files": "true"}
+	if !reflect.DeepEqual(parsed.Options, expectedOptions) {
+		t.Errorf("unexpected file options: %v", parsed.Options)
+	}
+	if len(parsed.Messages) != 1 || len(parsed.Services) != 1 || len(parsed.Enums) != 0 {
+		t.Fatalf("unexpected number of top level elements: %d messages, %d services, %d enums", len(parsed.Messages), len(parsed.Services), len(parsed.Enums))
+	}
+}
+
+func TestParseNestedMessages(t *testing.T) {
+	parsed, err := Parse("entry.proto", sampleProto)
+	if err != nil {
+		t.Fatalf("unable to parse: %v", err)
+	}
+	entry := parsed.Messages[0]
+	if entry.FullName != "agenda.v1.Entry" || entry.Comment != "Entry of the agenda." {
+		t.Errorf("unexpected message: %s %q", entry.FullName, entry.Comment)
+	}
+	if len(entry.Messages) != 1 || entry.Messages[0].FullName != "agenda.v1.Entry.Location" {
+		t.Fatalf("nested message not found: %+v", entry.Messages)
+	}
+	if entry.Messages[0].Comment != "Nested message with the location." {
+		t.Errorf("unexpected nested comment %q", entry.Messages[0].Comment)
+	}
+	if len(entry.Enums) != 1 || entry.Enums[0].FullName != "agenda.v1.Entry.Kind" {
+		t.Fatalf("nested enum not found: %+v", entry.Enums)
+	}
+	names := make([]string, 0)
+	for _, msg := range parsed.AllMessages() {
+		names = append(names, msg.FullName)
+	}
+	if !reflect.DeepEqual(names, []string{"agenda.v1.Entry", "agenda.v1.Entry.Location"}) {
+		t.Errorf("unexpected messages %v", names)
+	}
+	if len(parsed.AllEnums()) != 1 {
+		t.Errorf("expected the nested enum to be returned")
+	}
+	expectedRanges := []Range{{From: 4, To: 4}, {From: 10, To: 12}, {From: 20, To: maxFieldNumber}}
+	if !reflect.DeepEqual(entry.ReservedNumbers, expectedRanges) || !reflect.DeepEqual(entry.ReservedNames, []string{"legacy"}) {
+		t.Errorf("unexpected reserved: %v %v", entry.ReservedNumbers, entry.ReservedNames)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	parsed, err := Parse("entry.proto", sampleProto)
+	if err != nil {
+		t.Fatalf("unable to parse: %v", err)
+	}
+	fields := make(map[string]*Field, 0)
+	for _, field := range parsed.Messages[0].Fields {
+		fields[field.Name] = field
+	}
+	testCases := []struct {
+		name   string
+		typ    string
+		label  string
+		number int
+		oneOf  string
+	}{
+		{"entry_id", "string", "", 1, ""},
+		{"location", "Location", "", 2, ""},
+		{"tags", "string", "repeated", 3, ""},
+		{"counters", "map<string,int64>", "", 5, ""},
+		{"person", "string", "", 6, "target"},
+		{"team", "string", "", 7, "target"},
+		{"kind", "Kind", "optional", 8, ""},
+	}
+	if len(fields) != len(testCases) {
+		t.Errorf("expected %d fields, got %d", len(testCases), len(fields))
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			field, exists := fields[tc.name]
+			if !exists {
+				t.Fatalf("field not found")
+			}
+			if field.Type != tc.typ || field.Label != tc.label || field.Number != tc.number || field.OneOf != tc.oneOf {
+				t.Errorf("unexpected field %+v", field)
+			}
+		})
+	}
+	if fields["person"].Comment != "Person receiving the entry." {
+		t.Errorf("unexpected oneof field comment %q", fields["person"].Comment)
+	}
+	expectedOptions := map[string]string{"json_name": "type", "(validate.rules).enum": "{ defined_only : true }"}
+	if !reflect.DeepEqual(fields["kind"].Options, expectedOptions) {
+		t.Errorf("unexpected field options %v", fields["kind"].Options)
+	}
+}
+
+func TestParseEnumsAndServices(t *testing.T) {
+	parsed, err := Parse("entry.proto", sampleProto)
+	if err != nil {
+		t.Fatalf("unable to parse: %v", err)
+	}
+	kind := parsed.AllEnums()[0]
+	if kind.Options["allow_alias"] != "true" || len(kind.Values) != 3 {
+		t.Fatalf("unexpected enum %+v", kind)
+	}
+	if kind.Values[1].Comment != "" {
+		t.Errorf("trailing comment attached to the value: %q", kind.Values[1].Comment)
+	}
+	if kind.Values[2].Options["(custom.label)"] != "reminder" {
+		t.Errorf("unexpected value options %v", kind.Values[2].Options)
+	}
+
+	service := parsed.Services[0]
+	if service.FullName != "agenda.v1.AgendaService" || service.Comment != "Service managing the entries." {
+		t.Errorf("unexpected service %s %q", service.FullName, service.Comment)
+	}
+	if service.Options["(custom.service)"] != "agenda" {
+		t.Errorf("unexpected service options %v", service.Options)
+	}
+	if len(service.RPCs) != 2 {
+		t.Fatalf("expected 2 rpcs, got %d", len(service.RPCs))
+	}
+	add, watch := service.RPCs[0], service.RPCs[1]
+	if add.RequestType != "Entry" || add.ResponseType != "Entry" || add.ClientStreaming || add.ServerStreaming {
+		t.Errorf("unexpected rpc %+v", add)
+	}
+	if !watch.ClientStreaming || !watch.ServerStreaming || watch.Comment != "Watch the changes." {
+		t.Errorf("unexpected rpc %+v", watch)
+	}
+	if watch.Options["(google.api.http)"] != `{ get : "/v1/entries" }` {
+		t.Errorf("unexpected rpc options %v", watch.Options)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{"unterminated comment", "syntax = \"proto3\";\n/* comment"},
+		{"unterminated string", "syntax = \"proto3;\n"},
+		{"missing semicolon", "syntax = \"proto3\"\npackage test;"},
+		{"unclosed message", "message Test {\n string name = 1;\n"},
+		{"invalid field number", "message Test {\n string name = one;\n}"},
+		{"unexpected statement", "syntax = \"proto3\";\nunknown Test {}"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Parse("test.proto", tc.content); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	basePath := t.TempDir()
+	files := map[string]string{
+		"b.proto":          "syntax = \"proto3\";\npackage test;\nmessage B {}\n",
+		"sub/a.proto":      "syntax = \"proto3\";\npackage test.sub;\nmessage A {}\n",
+		".hidden/c.proto":  "invalid content",
+		"sub/README.md":    "not a proto",
+		"sub/deep/d.proto": "syntax = \"proto3\";\npackage test.sub.deep;\n",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(basePath, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parsed, err := ParseTree(basePath)
+	if err != nil {
+		t.Fatalf("unable to parse tree: %v", err)
+	}
+	names := make([]string, 0)
+	for _, protoFile := range parsed {
+		names = append(names, protoFile.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"b.proto", "sub/a.proto", "sub/deep/d.proto"}) {
+		t.Errorf("unexpected files %v", names)
+	}
+	direct, err := ParseDirectory(basePath)
+	if err != nil {
+		t.Fatalf("unable to parse directory: %v", err)
+	}
+	if len(direct) != 1 || direct[0].Name != "b.proto" {
+		t.Errorf("unexpected files on directory %v", direct)
+	}
+}
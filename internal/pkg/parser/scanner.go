@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind defines the type of the tokens found on a proto file.
+type tokenKind int
+
+const (
+	// identToken for identifiers, keywords and numbers.
+	identToken tokenKind = iota
+	// stringToken for quoted literals.
+	stringToken
+	// symbolToken for punctuation.
+	symbolToken
+	// eofToken signals the end of the input.
+	eofToken
+)
+
+// token structure with a single lexical element.
+type token struct {
+	kind tokenKind
+	text string
+	line int
+	// comment with the comment lines found right before the token.
+	comment string
+}
+
+// scanner splits the content of a proto file into tokens.
+type scanner struct {
+	input []rune
+	pos   int
+	line  int
+	// lastLine with the line of the last emitted token, used to discard trailing comments.
+	lastLine int
+}
+
+// newScanner creates a scanner for a given content.
+func newScanner(content string) *scanner {
+	return &scanner{input: []rune(content), line: 1}
+}
+
+// tokenize returns all the tokens of the input.
+func (s *scanner) tokenize() ([]token, error) {
+	result := make([]token, 0)
+	for {
+		tok, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tok)
+		if tok.kind == eofToken {
+			return result, nil
+		}
+	}
+}
+
+// peekRune returns the rune at a given offset from the current position.
+func (s *scanner) peekRune(offset int) rune {
+	if s.pos+offset >= len(s.input) {
+		return 0
+	}
+	return s.input[s.pos+offset]
+}
+
+// next returns the following token.
+func (s *scanner) next() (token, error) {
+	comments := make([]string, 0)
+	for s.pos < len(s.input) {
+		current := s.input[s.pos]
+		switch {
+		case current == '\n':
+			s.line++
+			s.pos++
+		case current == ' ' || current == '\t' || current == '\r':
+			s.pos++
+		case current == '/' && s.peekRune(1) == '/':
+			startLine := s.line
+			start := s.pos + 2
+			for s.pos < len(s.input) && s.input[s.pos] != '\n' {
+				s.pos++
+			}
+			if startLine != s.lastLine || s.lastLine == 0 {
+				comments = append(comments, strings.TrimSpace(string(s.input[start:s.pos])))
+			}
+		case current == '/' && s.peekRune(1) == '*':
+			startLine := s.line
+			s.pos += 2
+			start := s.pos
+			for s.pos < len(s.input) && !(s.input[s.pos] == '*' && s.peekRune(1) == '/') {
+				if s.input[s.pos] == '\n' {
+					s.line++
+				}
+				s.pos++
+			}
+			if s.pos >= len(s.input) {
+				return token{}, fmt.Errorf("line %d: unterminated comment", startLine)
+			}
+			if startLine != s.lastLine || s.lastLine == 0 {
+				comments = append(comments, strings.TrimSpace(string(s.input[start:s.pos])))
+			}
+			s.pos += 2
+		default:
+			tok, err := s.scanToken()
+			if err != nil {
+				return token{}, err
+			}
+			tok.comment = strings.Join(comments, "\n")
+			s.lastLine = s.line
+			return tok, nil
+		}
+	}
+	return token{kind: eofToken, line: s.line}, nil
+}
+
+// scanToken reads a token starting at the current position.
+func (s *scanner) scanToken() (token, error) {
+	current := s.input[s.pos]
+	start := s.pos
+	line := s.line
+	switch {
+	case current == '"' || current == '\'':
+		s.pos++
+		var sb strings.Builder
+		for s.pos < len(s.input) && s.input[s.pos] != current {
+			if s.input[s.pos] == '\n' {
+				return token{}, fmt.Errorf("line %d: unterminated string", line)
+			}
+			if s.input[s.pos] == '\\' && s.pos+1 < len(s.input) {
+				s.pos++
+			}
+			sb.WriteRune(s.input[s.pos])
+			s.pos++
+		}
+		if s.pos >= len(s.input) {
+			return token{}, fmt.Errorf("line %d: unterminated string", line)
+		}
+		s.pos++
+		return token{kind: stringToken, text: sb.String(), line: line}, nil
+	case isIdentRune(current):
+		for s.pos < len(s.input) && isIdentRune(s.input[s.pos]) {
+			s.pos++
+		}
+		return token{kind: identToken, text: string(s.input[start:s.pos]), line: line}, nil
+	case strings.ContainsRune("{}()[]<>;=,:-+/", current):
+		s.pos++
+		return token{kind: symbolToken, text: string(current), line: line}, nil
+	}
+	return token{}, fmt.Errorf("line %d: unexpected character %q", line, current)
+}
+
+// isIdentRune checks if a rune may be part of an identifier, a fully qualified name or a number.
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '.' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -4,46 +4,219 @@ import (
 	"fmt"
 	"os/exec"
 	"path"
+	"strings"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 )
 
+// DefaultDockerImage with the namely/protoc-all image used to generate the code if none is configured.
+const DefaultDockerImage = "namely/protoc-all"
+
+// DefaultDockerTag with the tag of the default image.
+const DefaultDockerTag = "1.37_2"
+
+// PullPolicy defines a type for the strategies to obtain the generator image.
+type PullPolicy int
+
+const (
+	// PullMissing pulls the image only if it is not available locally, which is the default behavior of docker run.
+	PullMissing PullPolicy = iota
+	// PullAlways pulls the image once per run, so updates of a tag are retrieved.
+	PullAlways
+	// PullNever requires the image to be available locally.
+	PullNever
+)
+
+// PullPolicyToString map associating type to its string representation.
+var PullPolicyToString = map[PullPolicy]string{
+	PullMissing: "missing",
+	PullAlways:  "always",
+	PullNever:   "never",
+}
+
+// PullPolicyToEnum map associating string representation with type.
+var PullPolicyToEnum = map[string]PullPolicy{
+	"missing": PullMissing,
+	"always":  PullAlways,
+	"never":   PullNever,
+}
+
+// ImageOptions structure with the docker image used to generate the code.
+type ImageOptions struct {
+	// Image with the name of the image (e.g., namely/protoc-all).
+	Image string
+	// Tag of the image. It is ignored if a digest is set.
+	Tag string
+	// Digest pinning the content of the image (e.g., sha256:4f5...).
+	Digest string
+	// PullPolicy with the strategy to obtain the image: missing, always or never.
+	PullPolicy string
+	// RunArgs with extra flags passed to docker run (e.g., --network=host).
+	RunArgs []string
+}
+
+// IsValid checks the pull policy and the digest.
+func (imo *ImageOptions) IsValid() error {
+	if _, exists := PullPolicyToEnum[strings.ToLower(imo.PullPolicy)]; imo.PullPolicy != "" && !exists {
+		return fmt.Errorf("pullPolicy must be missing, always or never")
+	}
+	if imo.Digest != "" && !strings.Contains(imo.Digest, ":") {
+		return fmt.Errorf("digest %s must include the algorithm (e.g., sha256:...)", imo.Digest)
+	}
+	return nil
+}
+
+// merge returns the options with the fields set on an override replacing the current ones.
+func (imo ImageOptions) merge(override ImageOptions) ImageOptions {
+	if override.Image != "" {
+		// Tags and digests refer to the previous image.
+		imo = ImageOptions{Image: override.Image, PullPolicy: imo.PullPolicy, RunArgs: imo.RunArgs}
+	}
+	if override.Tag != "" {
+		imo.Tag, imo.Digest = override.Tag, ""
+	}
+	if override.Digest != "" {
+		imo.Digest = override.Digest
+	}
+	if override.PullPolicy != "" {
+		imo.PullPolicy = override.PullPolicy
+	}
+	if len(override.RunArgs) > 0 {
+		imo.RunArgs = override.RunArgs
+	}
+	return imo
+}
+
+// Reference returns the reference of the image used by docker: the image followed by the digest if set, or by
+// the tag otherwise.
+func (imo ImageOptions) Reference() string {
+	switch {
+	case imo.Digest != "":
+		return fmt.Sprintf("%s@%s", imo.Image, imo.Digest)
+	case imo.Tag != "":
+		return fmt.Sprintf("%s:%s", imo.Image, imo.Tag)
+	}
+	return imo.Image
+}
+
+// DockerOptions structure with the configuration of the docker generator.
+type DockerOptions struct {
+	// ImageOptions with the image used for all the languages.
+	ImageOptions `mapstructure:",squash"`
+	// Languages with per language images. The fields that are not set are taken from the global settings.
+	Languages map[string]ImageOptions
+}
+
+// IsValid checks the global and the per language settings.
+func (do *DockerOptions) IsValid() error {
+	if err := do.ImageOptions.IsValid(); err != nil {
+		return err
+	}
+	for language, options := range do.Languages {
+		if err := options.IsValid(); err != nil {
+			return fmt.Errorf("invalid image of %s: %w", language, err)
+		}
+	}
+	return nil
+}
+
+// ImageFor returns the image used to generate a language.
+func (do *DockerOptions) ImageFor(language string) ImageOptions {
+	defaults := ImageOptions{Image: DefaultDockerImage, Tag: DefaultDockerTag}
+	return defaults.merge(do.ImageOptions).merge(do.Languages[language])
+}
+
+// ImageResolver is implemented by the generators running docker images, so the exact image used to generate a
+// target can be recorded.
+type ImageResolver interface {
+	// ResolvedImage returns the reference of the image used for a target including its digest
+	// (e.g., namely/protoc-all@sha256:4f5...).
+	ResolvedImage(target Target) (string, error)
+}
+
 // DockerCmdProvider is a proto generator based on issuing docker commands. Future
 // implementations will rely on the docker library.
 type DockerCmdProvider struct {
 	Common
+	options DockerOptions
+	// mutex protecting the pulled and resolved images, as jobs may run concurrently.
+	mutex sync.Mutex
+	// pulled with the outcome of obtaining each image reference.
+	pulled map[string]error
+	// resolved with the digest reference of each image reference.
+	resolved map[string]string
 }
 
 // NewDockerCmdGenerator uses an external command to launch the docker container with the proto tools.
-func NewDockerCmdGenerator() (Generator, error) {
+func NewDockerCmdGenerator(options DockerOptions) (Generator, error) {
 	log.Debug().Msg("Using DockerCmd proto generator")
-	return &DockerCmdProvider{Common: Common{}}, nil
+	if err := options.IsValid(); err != nil {
+		return nil, err
+	}
+	return &DockerCmdProvider{
+		Common:   Common{},
+		options:  options,
+		pulled:   make(map[string]error, 0),
+		resolved: make(map[string]string, 0),
+	}, nil
+}
+
+// ensureImage obtains an image following its pull policy. Each reference is only processed once per run.
+func (dcp *DockerCmdProvider) ensureImage(image ImageOptions) error {
+	reference := image.Reference()
+	dcp.mutex.Lock()
+	defer dcp.mutex.Unlock()
+	if err, done := dcp.pulled[reference]; done {
+		return err
+	}
+	var err error
+	switch PullPolicyToEnum[strings.ToLower(image.PullPolicy)] {
+	case PullAlways:
+		log.Info().Str("image", reference).Msg("pulling generator image")
+		if output, pullErr := exec.Command("docker", "pull", reference).CombinedOutput(); pullErr != nil {
+			err = fmt.Errorf("unable to pull image %s due to %w: %s", reference, pullErr, string(output))
+		}
+	case PullNever:
+		if inspectErr := exec.Command("docker", "image", "inspect", reference).Run(); inspectErr != nil {
+			err = fmt.Errorf("image %s is not available locally and the pull policy is never", reference)
+		}
+	}
+	dcp.pulled[reference] = err
+	return err
 }
 
 // Generate a set of proto stubs in a given language.
-func (dcp *DockerCmdProvider) Generate(rootPath string, targetName string, generatedPath string, language string) error {
+func (dcp *DockerCmdProvider) Generate(rootPath string, targetName string, generatedPath string, target Target) error {
 	// Based on the documentation available at: https://github.com/namely/docker-protoc
 
-	log.Debug().Str("rootPath", rootPath).Str("targetName", targetName).Str("generatedPath", generatedPath).Str("language", language).Msg("generating protos")
+	log.Debug().Str("rootPath", rootPath).Str("targetName", targetName).Str("generatedPath", generatedPath).Str("target", target.String()).Msg("generating protos")
 
+	language := target.Language
+	extraArgs, err := dcp.namelyArgs(rootPath, targetName, target)
+	if err != nil {
+		return err
+	}
+	image := dcp.options.ImageFor(language)
+	if err := dcp.ensureImage(image); err != nil {
+		return err
+	}
+	outputDir := dcp.outputDir(targetName, language)
 	cmdArgs := []string{
 		"run",
 		"-v", fmt.Sprintf("%s:/defs", rootPath), // source proto definition. This should be the root so imports work :)
-		"namely/protoc-all:1.37_2", // Image, maybe move this as a constant or config value.
-		"-l", language,             // Target language
+	}
+	cmdArgs = append(cmdArgs, image.RunArgs...)
+	cmdArgs = append(cmdArgs,
+		image.Reference(), // Image
+		"-l", language,    // Target language
 		"-d", targetName, // Directory to take protos from
 		"-i", ".", // Include local path
-		"-o", "generated", // Path where the resulting code is stored.
-	}
+		"-o", outputDir, // Path where the resulting code is stored.
+	)
 
-	// Extra options from the available arguments
-	if language == "go" {
-		cmdArgs = append(cmdArgs, "--with-gateway") //Generate grpc-gateway files (experimental)
-	}
-
-	if language == "go" || language == "gogo" || language == "cpp" || language == "java" || language == "python" {
-		cmdArgs = append(cmdArgs, "--with-validator") // Generate validations for (go gogo cpp java python)
-	}
+	// Extra options from the directory settings
+	cmdArgs = append(cmdArgs, extraArgs...)
 
 	cmd := exec.Command("docker", cmdArgs...)
 	log.Debug().Interface("cmd", cmd).Msg("docker generation cmd")
@@ -57,5 +230,30 @@ func (dcp *DockerCmdProvider) Generate(rootPath string, targetName string, gener
 	if err != nil {
 		return fmt.Errorf("unable to copy source files: %w", err)
 	}
-	return dcp.moveGeneratedFiles(path.Join(rootPath, "generated"), generatedPath)
+	return dcp.moveGeneratedFiles(path.Join(rootPath, outputDir), path.Join(rootPath, targetName), generatedPath)
+}
+
+// Identity returns the docker image used to generate the code. The configured reference is used instead of the
+// resolved digest so tags are only upgraded deliberately by changing the configuration.
+func (dcp *DockerCmdProvider) Identity(target Target) (string, error) {
+	image := dcp.options.ImageFor(target.Language)
+	return fmt.Sprintf("docker %s", image.Reference()), nil
+}
+
+// ResolvedImage returns the digest reference of the image used for a target as reported by docker. Images built
+// locally, which have no digest, are identified by their id.
+func (dcp *DockerCmdProvider) ResolvedImage(target Target) (string, error) {
+	reference := dcp.options.ImageFor(target.Language).Reference()
+	dcp.mutex.Lock()
+	defer dcp.mutex.Unlock()
+	if resolved, exists := dcp.resolved[reference]; exists {
+		return resolved, nil
+	}
+	output, err := exec.Command("docker", "image", "inspect", "--format", "{{if .RepoDigests}}{{index .RepoDigests 0}}{{else}}{{.Id}}{{end}}", reference).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("unable to inspect image %s due to %w: %s", reference, err, string(output))
+	}
+	resolved := strings.TrimSpace(string(output))
+	dcp.resolved[reference] = resolved
+	return resolved, nil
 }
@@ -0,0 +1,79 @@
+package protos
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeDocker places a docker script on the PATH that answers image inspect requests with the given output per
+// image reference, and records the calls on a log file whose path is returned.
+func fakeDocker(t *testing.T, outputs map[string]string) string {
+	t.Helper()
+	binDir := t.TempDir()
+	callsPath := filepath.Join(binDir, "calls.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + callsPath + "\neval ref=\\${$#}\ncase \"$ref\" in\n"
+	for reference, output := range outputs {
+		script += "  " + reference + ") printf '%s' '" + output + "' ;;\n"
+	}
+	script += "  *) echo \"Error: No such image: $ref\" >&2; exit 1 ;;\nesac\n"
+	if err := ioutil.WriteFile(filepath.Join(binDir, "docker"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	previous := os.Getenv("PATH")
+	os.Setenv("PATH", binDir+string(os.PathListSeparator)+previous)
+	t.Cleanup(func() { os.Setenv("PATH", previous) })
+	return callsPath
+}
+
+func TestResolvedImage(t *testing.T) {
+	digest := "namely/protoc-all@sha256:4f5b6c7d8e9f"
+	callsPath := fakeDocker(t, map[string]string{
+		"namely/protoc-all:1.37_2": digest + "\n",
+		"local/protoc:dev":         "sha256:0a1b2c3d\n",
+	})
+	options := DockerOptions{
+		Languages: map[string]ImageOptions{
+			"python": {Image: "local/protoc", Tag: "dev"},
+			"java":   {Image: "missing/protoc", Tag: "1.0"},
+		},
+	}
+	generator, err := NewDockerCmdGenerator(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := generator.(ImageResolver)
+
+	testCases := []struct {
+		name     string
+		language string
+		expected string
+	}{
+		{"repository digest", "go", digest},
+		{"cached", "go", digest},
+		{"local image id", "python", "sha256:0a1b2c3d"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := resolver.ResolvedImage(NewTarget(tc.language))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resolved != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, resolved)
+			}
+		})
+	}
+	if _, err := resolver.ResolvedImage(NewTarget("java")); err == nil || !strings.Contains(err.Error(), "No such image") {
+		t.Errorf("expected inspect error, got %v", err)
+	}
+	calls, err := ioutil.ReadFile(callsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inspected := strings.Count(string(calls), "namely/protoc-all:1.37_2"); inspected != 1 {
+		t.Errorf("expected the image to be inspected once, got %d calls", inspected)
+	}
+}
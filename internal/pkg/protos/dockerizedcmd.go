@@ -21,25 +21,25 @@ func NewDockerizedCmdGenerator() (Generator, error) {
 }
 
 // Generate a set of proto stubs in a given language.
-func (dcp *DockerizedCmdProvider) Generate(rootPath string, targetName string, generatedPath string, language string) error {
+func (dcp *DockerizedCmdProvider) Generate(rootPath string, targetName string, generatedPath string, target Target) error {
 	// Based on the documentation available at: https://github.com/namely/docker-protoc
-	log.Debug().Str("rootPath", rootPath).Str("targetName", targetName).Str("generatedPath", generatedPath).Str("language", language).Msg("generating protos")
+	log.Debug().Str("rootPath", rootPath).Str("targetName", targetName).Str("generatedPath", generatedPath).Str("target", target.String()).Msg("generating protos")
 
+	language := target.Language
+	extraArgs, err := dcp.namelyArgs(rootPath, targetName, target)
+	if err != nil {
+		return err
+	}
+	outputDir := dcp.outputDir(targetName, language)
 	cmdArgs := []string{
 		"-l", language, // Target language
 		"-d", targetName, // Directory to take protos from
 		"-i", ".", // Include local path
-		"-o", "generated", // Path where the resulting code is stored.
+		"-o", outputDir, // Path where the resulting code is stored.
 	}
 
-	// Extra options from the available arguments
-	if language == "go" {
-		cmdArgs = append(cmdArgs, "--with-gateway") //Generate grpc-gateway files (experimental)
-	}
-
-	if language == "go" || language == "gogo" || language == "cpp" || language == "java" || language == "python" {
-		cmdArgs = append(cmdArgs, "--with-validator") // Generate validations for (go gogo cpp java python)
-	}
+	// Extra options from the directory settings
+	cmdArgs = append(cmdArgs, extraArgs...)
 
 	cmd := exec.Command("entrypoint.sh", cmdArgs...)
 	log.Debug().Interface("cmd", cmd).Msg("dockerized generation cmd")
@@ -53,5 +53,11 @@ func (dcp *DockerizedCmdProvider) Generate(rootPath string, targetName string, g
 	if err != nil {
 		return fmt.Errorf("unable to copy source files: %w", err)
 	}
-	return dcp.moveGeneratedFiles(path.Join(rootPath, "generated"), generatedPath)
+	return dcp.moveGeneratedFiles(path.Join(rootPath, outputDir), path.Join(rootPath, targetName), generatedPath)
+}
+
+// Identity returns the image whose entrypoint generates the code. The version of the image is not available from
+// inside the container.
+func (dcp *DockerizedCmdProvider) Identity(target Target) (string, error) {
+	return "dockerized namely/protoc-all", nil
 }
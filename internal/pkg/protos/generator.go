@@ -10,37 +10,54 @@ const (
 	DockerCmd GeneratorType = iota
 	// DockerizedCmd to use the embeeded proto generator.
 	DockerizedCmd
+	// LocalCmd to use protoc and the plugins available on the system.
+	LocalCmd
 )
 
 // GeneratorTypeToString map associating type an string representation.
 var GeneratorTypeToString = map[GeneratorType]string{
 	DockerCmd:     "docker",
 	DockerizedCmd: "dockerized",
+	LocalCmd:      "local",
 }
 
 // GeneratorTypeToEnum map associating string representation with enum type.
 var GeneratorTypeToEnum = map[string]GeneratorType{
 	"docker":     DockerCmd,
 	"dockerized": DockerizedCmd,
+	"local":      LocalCmd,
 }
 
 // Generator interface for all implementations.
 type Generator interface {
-	// Generate a set of proto stubs in a given language.
-	Generate(rootPath string, targetName string, generatedPath string, language string) error
+	// Generate a set of proto stubs in a given language with the settings of the directory.
+	Generate(rootPath string, targetName string, generatedPath string, target Target) error
+	// Identity returns a description of the tools used to generate a language, including their versions, so
+	// upgrades of the toolchain are detected.
+	Identity(target Target) (string, error)
+}
+
+// GeneratorOptions structure with the settings that may be used by the different generators.
+type GeneratorOptions struct {
+	// Local with the configuration of the local generator.
+	Local LocalOptions
+	// Docker with the images used by the docker generator.
+	Docker DockerOptions
 }
 
 // NewGenerator builds a new generator.
-func NewGenerator(generatorName string) (Generator, error) {
+func NewGenerator(generatorName string, options GeneratorOptions) (Generator, error) {
 	gen, exists := GeneratorTypeToEnum[generatorName]
 	if !exists {
 		return nil, fmt.Errorf("generator %s not found", generatorName)
 	}
 	switch gen {
 	case DockerCmd:
-		return NewDockerCmdGenerator()
+		return NewDockerCmdGenerator(options.Docker)
 	case DockerizedCmd:
 		return NewDockerizedCmdGenerator()
+	case LocalCmd:
+		return NewLocalCmdGenerator(options.Local)
 	}
 	return nil, fmt.Errorf("no implementation found for %s generator", generatorName)
 }
@@ -0,0 +1,312 @@
+package protos
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultProtocPath with the protoc binary used if none is configured.
+const DefaultProtocPath = "protoc"
+
+// builtinGenerators with the generators embedded in protoc that do not require an external plugin.
+var builtinGenerators = map[string]bool{
+	"cpp": true, "csharp": true, "java": true, "js": true, "kotlin": true,
+	"objc": true, "php": true, "python": true, "pyi": true, "ruby": true,
+}
+
+// defaultLanguagePlugins with the plugins used for each language if none are configured. The set mimics the
+// behavior of the namely/protoc-all image.
+var defaultLanguagePlugins = map[string][]PluginOptions{
+	"go":     {{Name: "go"}, {Name: "go-grpc"}},
+	"python": {{Name: "python"}, {Name: "grpc_python", Path: "grpc_python_plugin"}},
+	"java":   {{Name: "java"}, {Name: "grpc-java"}},
+	"node":   {{Name: "js", Options: "import_style=commonjs,binary"}, {Name: "grpc", Path: "grpc_tools_node_protoc_plugin", Options: "grpc_js"}},
+	"cpp":    {{Name: "cpp"}, {Name: "grpc", Path: "grpc_cpp_plugin"}},
+	"csharp": {{Name: "csharp"}, {Name: "grpc", Path: "grpc_csharp_plugin"}},
+	"ruby":   {{Name: "ruby"}, {Name: "grpc", Path: "grpc_ruby_plugin"}},
+	"php":    {{Name: "php"}, {Name: "grpc", Path: "grpc_php_plugin"}},
+	"objc":   {{Name: "objc"}, {Name: "grpc", Path: "grpc_objective_c_plugin"}},
+}
+
+// validatorLanguages with the name used by the validation plugin for each supported language.
+var validatorLanguages = map[string]string{
+	"go":   "go",
+	"java": "java",
+	"cpp":  "cc",
+}
+
+// PluginOptions structure with the configuration of a protoc plugin.
+type PluginOptions struct {
+	// Name of the plugin as used on the --<name>_out flag.
+	Name string
+	// Path of the plugin binary. If empty, protoc-gen-<name> is searched on the PATH for non builtin generators.
+	// Relative names are also searched on the PATH.
+	Path string
+	// Options passed to the plugin.
+	Options string
+}
+
+// LanguageOptions structure with the configuration of the generation of a given language.
+type LanguageOptions struct {
+	// Plugins to be executed to generate the language code.
+	Plugins []PluginOptions
+}
+
+// LocalOptions structure with the configuration of the local generator.
+type LocalOptions struct {
+	// ProtocPath with the path of the protoc binary.
+	ProtocPath string
+	// Includes with extra include paths passed to protoc.
+	Includes []string
+	// Languages with per language plugin configuration. Languages not present use the default plugins.
+	Languages map[string]LanguageOptions
+}
+
+// LocalCmdProvider is a proto generator that invokes protoc and the language plugins available on the system,
+// without requiring docker.
+type LocalCmdProvider struct {
+	Common
+	options LocalOptions
+	// protocVersion with the version of protoc, obtained once.
+	protocVersion     string
+	protocVersionErr  error
+	protocVersionOnce sync.Once
+	// pluginChecksums with the checksum of each plugin binary indexed by its path.
+	pluginChecksums sync.Map
+}
+
+// NewLocalCmdGenerator uses the protoc binary and plugins found on the system.
+func NewLocalCmdGenerator(options LocalOptions) (Generator, error) {
+	log.Debug().Msg("Using LocalCmd proto generator")
+	if options.ProtocPath == "" {
+		options.ProtocPath = DefaultProtocPath
+	}
+	return &LocalCmdProvider{Common: Common{}, options: options}, nil
+}
+
+// getPlugins returns the plugins to be used for a given language.
+func (lcp *LocalCmdProvider) getPlugins(language string) ([]PluginOptions, error) {
+	if langOptions, exists := lcp.options.Languages[language]; exists && len(langOptions.Plugins) > 0 {
+		return langOptions.Plugins, nil
+	}
+	plugins, exists := defaultLanguagePlugins[language]
+	if !exists {
+		return nil, fmt.Errorf("no plugins configured for language %s", language)
+	}
+	return plugins, nil
+}
+
+// getTargetPlugins returns the plugins to be used for the settings of a directory, including the optional ones and
+// the extra plugin options. As on the docker generators, the gateway is generated for go and the validation code for
+// the languages supported by the validation plugin by default.
+func (lcp *LocalCmdProvider) getTargetPlugins(target Target, protoFiles []string) ([]PluginOptions, error) {
+	configured, err := lcp.getPlugins(target.Language)
+	if err != nil {
+		return nil, err
+	}
+	// Copy the plugins so the configured ones are not modified.
+	plugins := append(make([]PluginOptions, 0, len(configured)+3), configured...)
+	if target.WithGateway(defaultGateway(target.Language)) {
+		if target.Language != "go" {
+			return nil, fmt.Errorf("gateway generation is only supported for go")
+		}
+		plugins = append(plugins, PluginOptions{Name: "grpc-gateway"})
+	}
+	_, defaultValidator := validatorLanguages[target.Language]
+	if target.WithValidator(defaultValidator) {
+		validatorLanguage, supported := validatorLanguages[target.Language]
+		if !supported {
+			return nil, fmt.Errorf("validator generation is not supported for %s", target.Language)
+		}
+		plugins = append(plugins, PluginOptions{Name: "validate", Options: fmt.Sprintf("lang=%s", validatorLanguage)})
+	}
+	if target.WithDocs(false) {
+		plugins = append(plugins, PluginOptions{Name: "doc", Options: "html,index.html"})
+	}
+
+	used := make(map[string]bool, 0)
+	for index, plugin := range plugins {
+		options := make([]string, 0)
+		if plugin.Options != "" {
+			options = append(options, plugin.Options)
+		}
+		if extra, exists := target.Options[plugin.Name]; exists {
+			options = append(options, extra)
+			used[plugin.Name] = true
+		}
+		if target.Package != "" && target.Language == "go" && plugin.Name != "doc" {
+			options = append(options, packageMappings(protoFiles, target.Package)...)
+		}
+		plugins[index].Options = strings.Join(options, ",")
+	}
+	for plugin := range target.Options {
+		if !used[plugin] {
+			return nil, fmt.Errorf("options found for plugin %s that is not used to generate %s", plugin, target.Language)
+		}
+	}
+	return plugins, nil
+}
+
+// resolvePlugin returns the path of the binary of a plugin, or an empty string for the generators embedded in protoc.
+func (lcp *LocalCmdProvider) resolvePlugin(plugin PluginOptions) (string, error) {
+	pluginPath := plugin.Path
+	if pluginPath == "" && !builtinGenerators[plugin.Name] {
+		pluginPath = fmt.Sprintf("protoc-gen-%s", plugin.Name)
+	}
+	if pluginPath == "" {
+		return "", nil
+	}
+	resolved, err := exec.LookPath(pluginPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot find plugin %s: %w", plugin.Name, err)
+	}
+	return resolved, nil
+}
+
+// pluginArgs builds the protoc arguments for a given plugin.
+func (lcp *LocalCmdProvider) pluginArgs(plugin PluginOptions, outputPath string) ([]string, error) {
+	args := make([]string, 0)
+	resolved, err := lcp.resolvePlugin(plugin)
+	if err != nil {
+		return nil, err
+	}
+	if resolved != "" {
+		args = append(args, fmt.Sprintf("--plugin=protoc-gen-%s=%s", plugin.Name, resolved))
+	}
+	if plugin.Options != "" {
+		args = append(args, fmt.Sprintf("--%s_out=%s:%s", plugin.Name, plugin.Options, outputPath))
+	} else {
+		args = append(args, fmt.Sprintf("--%s_out=%s", plugin.Name, outputPath))
+	}
+	return args, nil
+}
+
+// Generate a set of proto stubs in a given language.
+func (lcp *LocalCmdProvider) Generate(rootPath string, targetName string, generatedPath string, target Target) error {
+	language := target.Language
+	log.Debug().Str("rootPath", rootPath).Str("targetName", targetName).Str("generatedPath", generatedPath).Str("target", target.String()).Msg("generating protos")
+
+	protoFiles, err := lcp.findProtoFiles(rootPath, targetName, language)
+	if err != nil {
+		return err
+	}
+	if len(protoFiles) == 0 {
+		return fmt.Errorf("no proto files found on %s", targetName)
+	}
+	plugins, err := lcp.getTargetPlugins(target, protoFiles)
+	if err != nil {
+		return err
+	}
+
+	outputPath := lcp.outputDir(targetName, language)
+	if err := os.MkdirAll(path.Join(rootPath, outputPath), 0755); err != nil {
+		return fmt.Errorf("unable to create output directory: %w", err)
+	}
+
+	cmdArgs := []string{"-I", "."}
+	for _, include := range lcp.options.Includes {
+		cmdArgs = append(cmdArgs, "-I", include)
+	}
+	for _, plugin := range plugins {
+		args, err := lcp.pluginArgs(plugin, outputPath)
+		if err != nil {
+			return err
+		}
+		cmdArgs = append(cmdArgs, args...)
+	}
+	cmdArgs = append(cmdArgs, target.Args...)
+	cmdArgs = append(cmdArgs, protoFiles...)
+
+	cmd := exec.Command(lcp.options.ProtocPath, cmdArgs...)
+	cmd.Dir = rootPath
+	log.Debug().Interface("cmd", cmd).Msg("local generation cmd")
+	stdoutStderr, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unable to generate protos for %s due to %w: %s", targetName, err, string(stdoutStderr))
+	}
+	log.Debug().Str("output", string(stdoutStderr)).Msg("protos successfully generated")
+
+	// Python also needs an __init__.py file to import the generated code.
+	if language == "python" {
+		if err := ioutil.WriteFile(path.Join(rootPath, outputPath, "__init__.py"), []byte{}, 0644); err != nil {
+			return err
+		}
+	}
+
+	err = lcp.copyAllSourceFiles(path.Join(rootPath, targetName), generatedPath)
+	if err != nil {
+		return fmt.Errorf("unable to copy source files: %w", err)
+	}
+	return lcp.moveGeneratedFiles(path.Join(rootPath, outputPath), path.Join(rootPath, targetName), generatedPath)
+}
+
+// getProtocVersion returns the version reported by protoc.
+func (lcp *LocalCmdProvider) getProtocVersion() (string, error) {
+	lcp.protocVersionOnce.Do(func() {
+		output, err := exec.Command(lcp.options.ProtocPath, "--version").CombinedOutput()
+		if err != nil {
+			lcp.protocVersionErr = fmt.Errorf("cannot obtain protoc version due to %w: %s", err, string(output))
+			return
+		}
+		lcp.protocVersion = strings.TrimSpace(string(output))
+	})
+	return lcp.protocVersion, lcp.protocVersionErr
+}
+
+// getPluginChecksum returns the checksum of the binary of a plugin. Plugins do not report their versions in a
+// standard way, so the checksum identifies them.
+func (lcp *LocalCmdProvider) getPluginChecksum(pluginPath string) (string, error) {
+	if cached, exists := lcp.pluginChecksums.Load(pluginPath); exists {
+		return cached.(string), nil
+	}
+	f, err := os.Open(pluginPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	result := fmt.Sprintf("%x", h.Sum(nil))[:12]
+	lcp.pluginChecksums.Store(pluginPath, result)
+	return result, nil
+}
+
+// Identity returns the version of protoc and the checksum of the plugins used to generate a language.
+func (lcp *LocalCmdProvider) Identity(target Target) (string, error) {
+	version, err := lcp.getProtocVersion()
+	if err != nil {
+		return "", err
+	}
+	plugins, err := lcp.getTargetPlugins(target, []string{})
+	if err != nil {
+		return "", err
+	}
+	result := []string{"local", version}
+	for _, plugin := range plugins {
+		resolved, err := lcp.resolvePlugin(plugin)
+		if err != nil {
+			return "", err
+		}
+		if resolved == "" {
+			result = append(result, plugin.Name)
+			continue
+		}
+		sum, err := lcp.getPluginChecksum(resolved)
+		if err != nil {
+			return "", fmt.Errorf("cannot calculate checksum of plugin %s: %w", plugin.Name, err)
+		}
+		result = append(result, fmt.Sprintf("%s@%s", plugin.Name, sum))
+	}
+	return strings.Join(result, " "), nil
+}
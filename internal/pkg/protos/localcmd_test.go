@@ -0,0 +1,36 @@
+package protos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLocalTargetPluginDefaults(t *testing.T) {
+	disabled := false
+	testCases := []struct {
+		name     string
+		target   Target
+		expected []string
+	}{
+		{"go", NewTarget("go"), []string{"go", "go-grpc", "grpc-gateway", "validate"}},
+		{"go without gateway and validator", Target{Language: "go", Gateway: &disabled, Validator: &disabled}, []string{"go", "go-grpc"}},
+		{"java", NewTarget("java"), []string{"java", "grpc-java", "validate"}},
+		{"python", NewTarget("python"), []string{"python", "grpc_python"}},
+	}
+	lcp := &LocalCmdProvider{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugins, err := lcp.getTargetPlugins(tc.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := make([]string, 0, len(plugins))
+			for _, plugin := range plugins {
+				names = append(names, plugin.Name)
+			}
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("expected plugins %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
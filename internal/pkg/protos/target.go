@@ -0,0 +1,98 @@
+package protos
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Target structure with the settings of the generation of a given language for a proto directory.
+type Target struct {
+	// Language to be generated.
+	Language string
+	// Gateway determines if the grpc-gateway code is generated. If not set, the default of the generator is used.
+	Gateway *bool
+	// Validator determines if the validation code is generated. If not set, the default of the generator is used.
+	Validator *bool
+	// Docs determines if the documentation of the protos is generated. If not set, the default of the generator is used.
+	Docs *bool
+	// Repository with the name of the target repository overriding the repository naming, if any.
+	Repository string
+	// Package with the import path of the generated go code overriding the go_package option of the protos, if any.
+	Package string
+	// Options with extra options for each plugin indexed by the plugin name.
+	Options map[string]string
+	// Args with extra arguments passed to the generator command.
+	Args []string
+}
+
+// NewTarget creates the settings of a language relying on the generator defaults.
+func NewTarget(language string) Target {
+	return Target{Language: language, Options: make(map[string]string, 0), Args: make([]string, 0)}
+}
+
+// enabled returns the value of a toggle, or the default one if it is not set.
+func (t Target) enabled(toggle *bool, defaultValue bool) bool {
+	if toggle == nil {
+		return defaultValue
+	}
+	return *toggle
+}
+
+// WithGateway determines if the grpc-gateway code must be generated.
+func (t Target) WithGateway(defaultValue bool) bool {
+	return t.enabled(t.Gateway, defaultValue)
+}
+
+// WithValidator determines if the validation code must be generated.
+func (t Target) WithValidator(defaultValue bool) bool {
+	return t.enabled(t.Validator, defaultValue)
+}
+
+// WithDocs determines if the documentation must be generated.
+func (t Target) WithDocs(defaultValue bool) bool {
+	return t.enabled(t.Docs, defaultValue)
+}
+
+// IsValid checks that the settings can be used to generate code.
+func (t Target) IsValid() error {
+	if t.Language == "" {
+		return fmt.Errorf("language must be specified")
+	}
+	if t.Package != "" && t.Language != "go" {
+		return fmt.Errorf("package can only be set for go, found on %s", t.Language)
+	}
+	return nil
+}
+
+// String representation of the settings that differ from the defaults.
+func (t Target) String() string {
+	settings := []string{t.Language}
+	toggles := []struct {
+		name  string
+		value *bool
+	}{{"gateway", t.Gateway}, {"validator", t.Validator}, {"docs", t.Docs}}
+	for _, toggle := range toggles {
+		if toggle.value != nil {
+			settings = append(settings, fmt.Sprintf("%s=%t", toggle.name, *toggle.value))
+		}
+	}
+	if t.Repository != "" {
+		settings = append(settings, fmt.Sprintf("repository=%s", t.Repository))
+	}
+	if t.Package != "" {
+		settings = append(settings, fmt.Sprintf("package=%s", t.Package))
+	}
+	plugins := make([]string, 0, len(t.Options))
+	for plugin := range t.Options {
+		plugins = append(plugins, plugin)
+	}
+	sort.Strings(plugins)
+	for _, plugin := range plugins {
+		settings = append(settings, fmt.Sprintf("option.%s=%s", plugin, t.Options[plugin]))
+	}
+	for _, arg := range t.Args {
+		settings = append(settings, fmt.Sprintf("arg=%s", arg))
+	}
+	return strings.Join(settings, " ")
+}
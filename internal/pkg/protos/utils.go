@@ -1,9 +1,11 @@
 package protos
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/gpm-project/grpc-proto-manager/internal/pkg/files"
 	"github.com/rs/zerolog/log"
@@ -13,22 +15,113 @@ import (
 type Common struct {
 }
 
-// copyAllSourceFiles copies all source files into the generated path so it contains everything that will be uploaded
+// GeneratedDir with the name of the directory, relative to the root path, where the generated code is stored temporarily.
+const GeneratedDir = "generated"
+
+// outputDir returns the directory, relative to the root path, where the code of a given target and language is
+// generated. Each combination uses its own directory so several generations may run concurrently.
+func (c *Common) outputDir(targetName string, language string) string {
+	return path.Join(GeneratedDir, fmt.Sprintf("%s-%s", targetName, language))
+}
+
+// findProtoFiles returns the proto files of the target directory relative to the root path. As with the
+// namely/protoc-all image, subdirectories are not included for go.
+func (c *Common) findProtoFiles(rootPath string, targetName string, language string) ([]string, error) {
+	result := make([]string, 0)
+	targetPath := path.Join(rootPath, targetName)
+	err := filepath.Walk(targetPath, func(currentPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && currentPath != targetPath && language == "go" {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".proto") {
+			relative, err := filepath.Rel(rootPath, currentPath)
+			if err != nil {
+				return err
+			}
+			result = append(result, relative)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// packageMappings returns the options of the go plugins that set the import path of the code generated from a set
+// of proto files.
+func packageMappings(protoFiles []string, goPackage string) []string {
+	result := make([]string, 0, len(protoFiles))
+	for _, protoFile := range protoFiles {
+		result = append(result, fmt.Sprintf("M%s=%s", filepath.ToSlash(protoFile), goPackage))
+	}
+	return result
+}
+
+// defaultGateway determines if the grpc-gateway code is generated for a language when it is not set explicitly.
+func defaultGateway(language string) bool {
+	return language == "go"
+}
+
+// namelyArgs returns the arguments of the namely/protoc-all entrypoint that apply the settings of a directory. By
+// default, the gateway code is generated for go, and the validation code for the languages that support it.
+func (c *Common) namelyArgs(rootPath string, targetName string, target Target) ([]string, error) {
+	if len(target.Options) > 0 {
+		return nil, fmt.Errorf("plugin options are only supported by the local generator, use args instead")
+	}
+	language := target.Language
+	cmdArgs := make([]string, 0)
+	if target.WithGateway(defaultGateway(language)) {
+		cmdArgs = append(cmdArgs, "--with-gateway") //Generate grpc-gateway files (experimental)
+	}
+	defaultValidator := language == "go" || language == "gogo" || language == "cpp" || language == "java" || language == "python"
+	if target.WithValidator(defaultValidator) {
+		cmdArgs = append(cmdArgs, "--with-validator") // Generate validations for (go gogo cpp java python)
+	}
+	if target.Package != "" {
+		protoFiles, err := c.findProtoFiles(rootPath, targetName, language)
+		if err != nil {
+			return nil, err
+		}
+		// The mappings are prepended to the go plugin options, so they must end with a separator.
+		cmdArgs = append(cmdArgs, "--go-package-map", strings.Join(packageMappings(protoFiles, target.Package), ",")+",")
+	}
+	cmdArgs = append(cmdArgs, target.Args...)
+	if target.WithDocs(false) {
+		// Set as the last argument, as the flag accepts an optional format.
+		cmdArgs = append(cmdArgs, "--with-docs")
+	}
+	return cmdArgs, nil
+}
+
+// copyAllSourceFiles copies all source files into the generated path so it contains everything that will be uploaded.
+// The structure of subdirectories is preserved so subpackages keep their relative paths.
 func (c *Common) copyAllSourceFiles(source string, generatedPath string) error {
 	toCopy := make(map[string]string, 0)
-	filepath.Walk(source, func(currentPath string, info os.FileInfo, err error) error {
+	err := filepath.Walk(source, func(currentPath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		if !info.IsDir() {
-			toCopy[currentPath] = info.Name()
+			relative, err := filepath.Rel(source, currentPath)
+			if err != nil {
+				return err
+			}
+			toCopy[currentPath] = relative
 		}
 
 		return nil
 	})
-	for filePath, fileName := range toCopy {
-		log.Debug().Str("toCopy", filePath).Str("fileName", fileName).Msg("moving file")
-		err := files.CopyFile(filePath, path.Join(generatedPath, fileName))
+	if err != nil {
+		return err
+	}
+	for filePath, relativePath := range toCopy {
+		log.Debug().Str("toCopy", filePath).Str("relativePath", relativePath).Msg("moving file")
+		targetPath := path.Join(generatedPath, relativePath)
+		if err := os.MkdirAll(path.Dir(targetPath), 0755); err != nil {
+			return err
+		}
+		err := files.CopyFile(filePath, targetPath)
 		if err != nil {
 			return err
 		}
@@ -36,40 +129,97 @@ func (c *Common) copyAllSourceFiles(source string, generatedPath string) error {
 	return nil
 }
 
-// moveGenerateFiles moves the generated files into the temp directory.
-func (c *Common) moveGeneratedFiles(rootPath string, generatedPath string) error {
+// protoDirs returns the directories, relative to the source path, that contain proto files.
+func protoDirs(sourcePath string) ([]string, error) {
+	dirs := make([]string, 0)
+	seen := make(map[string]bool, 0)
+	err := filepath.Walk(sourcePath, func(currentPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(currentPath) != ".proto" {
+			return nil
+		}
+		relative, err := filepath.Rel(sourcePath, filepath.Dir(currentPath))
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+		if !seen[relative] {
+			seen[relative] = true
+			dirs = append(dirs, relative)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// relativeGeneratedPath returns the path a generated file must have on the target repository. Generators write
+// the files relative to the proto directory (e.g., python) or to the import path of the package (e.g., go), so the
+// prefix before the longest proto directory matching the end of the file directory is removed.
+func relativeGeneratedPath(relativePath string, sourceDirs []string) string {
+	dir := path.Dir(relativePath)
+	if dir == "." {
+		return relativePath
+	}
+	prefix := dir
+	for _, sourceDir := range sourceDirs {
+		if sourceDir == "." {
+			continue
+		}
+		if dir == sourceDir {
+			return relativePath
+		}
+		if strings.HasSuffix(dir, "/"+sourceDir) && len(dir)-len(sourceDir)-1 < len(prefix) {
+			prefix = strings.TrimSuffix(dir, "/"+sourceDir)
+		}
+	}
+	return strings.TrimPrefix(relativePath, prefix+"/")
+}
+
+// moveGeneratedFiles moves the generated files into the temp directory. The subdirectories of the proto files are
+// preserved so files with the same name on different subpackages do not overwrite each other.
+func (c *Common) moveGeneratedFiles(rootPath string, sourcePath string, generatedPath string) error {
 	log.Debug().Str("rootPath", rootPath).Str("generatedPath", generatedPath).Msg("moving generated content")
 
-	// Find the generated files. This is a bit of a hack since the generated structure depends on language specs.
-	// So we find all the files
+	sourceDirs, err := protoDirs(sourcePath)
+	if err != nil {
+		return fmt.Errorf("unable to find proto directories: %w", err)
+	}
+
+	// Find the generated files. The generated structure depends on language specs, so all the files are collected.
 	toCopy := make(map[string]string, 0)
-	filepath.Walk(rootPath, func(currentPath string, info os.FileInfo, err error) error {
+	err = filepath.Walk(rootPath, func(currentPath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		if !info.IsDir() {
-			toCopy[currentPath] = info.Name()
+			relative, err := filepath.Rel(rootPath, currentPath)
+			if err != nil {
+				return err
+			}
+			toCopy[currentPath] = relativeGeneratedPath(filepath.ToSlash(relative), sourceDirs)
 		}
 
 		return nil
 	})
+	if err != nil {
+		return fmt.Errorf("unable to find generated files: %w", err)
+	}
 
-	for filePath, fileName := range toCopy {
-		log.Debug().Str("toCopy", filePath).Str("fileName", fileName).Msg("moving file")
-
-		err := c.moveFile(filePath, path.Join(generatedPath, fileName))
-		if err != nil {
+	for filePath, relativePath := range toCopy {
+		log.Debug().Str("toCopy", filePath).Str("relativePath", relativePath).Msg("moving file")
+		targetPath := path.Join(generatedPath, relativePath)
+		if err := os.MkdirAll(path.Dir(targetPath), 0755); err != nil {
+			return err
+		}
+		if err := c.moveFile(filePath, targetPath); err != nil {
 			return err
 		}
 	}
 
 	// Cleanup the temporal generated directory.
-	err := os.RemoveAll(rootPath)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return os.RemoveAll(rootPath)
 }
 
 // moveFile implements moving the contents of a file to a new path deleting the old one.
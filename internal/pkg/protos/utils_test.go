@@ -0,0 +1,112 @@
+package protos
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles stores a set of files on a directory.
+func writeFiles(t *testing.T, dirPath string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		filePath := filepath.Join(dirPath, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readFiles returns the files of a directory, relative to it, with their content.
+func readFiles(t *testing.T, dirPath string) map[string]string {
+	t.Helper()
+	result := make(map[string]string, 0)
+	err := filepath.Walk(dirPath, func(currentPath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := ioutil.ReadFile(currentPath)
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(dirPath, currentPath)
+		if err != nil {
+			return err
+		}
+		result[filepath.ToSlash(relative)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestRelativeGeneratedPath(t *testing.T) {
+	sourceDirs := []string{".", "sub/a", "sub/b"}
+	testCases := []struct {
+		name         string
+		relativePath string
+		expected     string
+	}{
+		{"root file", "__init__.py", "__init__.py"},
+		{"go root package", "github.com/acme/agenda/agenda.pb.go", "agenda.pb.go"},
+		{"go subpackage", "github.com/acme/agenda/sub/a/types.pb.go", "sub/a/types.pb.go"},
+		{"python root package", "agenda/agenda_pb2.py", "agenda_pb2.py"},
+		{"python subpackage", "agenda/sub/b/types_pb2.py", "sub/b/types_pb2.py"},
+		{"source relative", "sub/a/types.pb.go", "sub/a/types.pb.go"},
+		{"documentation", "doc/index.html", "index.html"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := relativeGeneratedPath(tc.relativePath, sourceDirs); result != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestMoveGeneratedFilesPreservesSubpackages(t *testing.T) {
+	basePath := t.TempDir()
+	sourcePath := filepath.Join(basePath, "agenda")
+	outputPath := filepath.Join(basePath, GeneratedDir, "agenda-go")
+	generatedPath := filepath.Join(basePath, "target")
+	writeFiles(t, sourcePath, "agenda.proto", "sub/a/types.proto", "sub/b/types.proto")
+	writeFiles(t, outputPath,
+		"github.com/acme/agenda/agenda.pb.go",
+		"github.com/acme/agenda/sub/a/types.pb.go",
+		"github.com/acme/agenda/sub/b/types.pb.go")
+
+	c := &Common{}
+	if err := c.moveGeneratedFiles(outputPath, sourcePath, generatedPath); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"agenda.pb.go":      "github.com/acme/agenda/agenda.pb.go",
+		"sub/a/types.pb.go": "github.com/acme/agenda/sub/a/types.pb.go",
+		"sub/b/types.pb.go": "github.com/acme/agenda/sub/b/types.pb.go",
+	}
+	if result := readFiles(t, generatedPath); !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected generated files %v", result)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Errorf("output directory not removed")
+	}
+}
+
+func TestMoveGeneratedFilesMissingOutput(t *testing.T) {
+	basePath := t.TempDir()
+	sourcePath := filepath.Join(basePath, "agenda")
+	writeFiles(t, sourcePath, "agenda.proto")
+
+	c := &Common{}
+	err := c.moveGeneratedFiles(filepath.Join(basePath, "missing"), sourcePath, filepath.Join(basePath, "target"))
+	if err == nil {
+		t.Errorf("expected error walking a missing output directory")
+	}
+}
@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GitBackendType defines a type for all supported git implementations.
+type GitBackendType int
+
+const (
+	// GitCLI relies on the git binary installed on the system.
+	GitCLI GitBackendType = iota
+	// GoGit uses an in-process git implementation that does not require the git binary.
+	GoGit
+)
+
+// GitBackendTypeToString map associating type to its string representation.
+var GitBackendTypeToString = map[GitBackendType]string{
+	GitCLI: "cli",
+	GoGit:  "gogit",
+}
+
+// GitBackendTypeToEnum map associating string representation with type.
+var GitBackendTypeToEnum = map[string]GitBackendType{
+	"cli":   GitCLI,
+	"gogit": GoGit,
+}
+
+// Signature structure with the identity used to author commits and tags. Empty fields are taken from the git
+// configuration of the system.
+type Signature struct {
+	// Name of the author.
+	Name string
+	// Email of the author.
+	Email string
+	// SigningKey used to sign the commits and tags. If nil, they are not signed.
+	SigningKey *SigningKey
+}
+
+// GitBackend defines the git operations required by the repository providers.
+type GitBackend interface {
+	// Clone a given repository to a path. If depth is greater than zero, a shallow clone with the given number of
+	// commits is performed and the tags are fetched separately. The current branch of empty repositories is the one
+	// advertised by the remote, or the given default branch if the remote does not advertise it.
+	Clone(repoURL string, outputPath string, depth int, defaultBranch string) error
+	// Update fetches the latest changes and tags of a previously cloned repository from the given URL, and resets the
+	// working tree to the remote state of the current branch removing any local change.
+	Update(repoURL string, repoPath string, depth int) error
+	// LatestTag obtains the most recent tag reachable from the current commit. An empty string is returned if
+	// the repository has no tags.
+	LatestTag(repoPath string) (string, error)
+	// Tags returns the names of all the tags of the repository.
+	Tags(repoPath string) ([]string, error)
+	// ReadFile returns the content of a file on a given revision (e.g., a tag). A nil content is returned if the file
+	// does not exist on that revision.
+	ReadFile(repoPath string, revision string, filePath string) ([]byte, error)
+	// AddAll stages all the changes of the working tree, including removed files.
+	AddAll(repoPath string) error
+	// HasChanges checks if there are staged or unstaged changes on the working tree.
+	HasChanges(repoPath string) (bool, error)
+	// Commit the staged changes.
+	Commit(repoPath string, message string, author Signature) error
+	// HasCommit checks if a commit is available on the local repository.
+	HasCommit(repoPath string, commit string) (bool, error)
+	// FetchBranch fetches the complete history of a branch of the origin remote, deepening shallow clones.
+	FetchBranch(repoPath string, branch string) error
+	// HeadCommit returns the hash of the current commit.
+	HeadCommit(repoPath string) (string, error)
+	// CurrentBranch returns the name of the current branch.
+	CurrentBranch(repoPath string) (string, error)
+	// Tag a given commit with an annotated tag.
+	Tag(repoPath string, commit string, name string, message string, tagger Signature) error
+	// Push the current branch to the origin remote.
+	Push(repoPath string) error
+	// PushBranch pushes the current commit to a given branch of the origin remote, overwriting its previous content.
+	PushBranch(repoPath string, branch string) error
+	// PushTags pushes all the tags to the origin remote.
+	PushTags(repoPath string) error
+	// InitBare creates an empty bare repository whose HEAD points to the given branch, if any.
+	InitBare(repoPath string, defaultBranch string) error
+}
+
+// NewGitBackend factory method to instantiate a git backend. The git binary is used by default.
+func NewGitBackend(backendName string) (GitBackend, error) {
+	if backendName == "" {
+		return NewCmdGitBackend(), nil
+	}
+	backend, exists := GitBackendTypeToEnum[strings.ToLower(backendName)]
+	if !exists {
+		return nil, fmt.Errorf("git backend not found for %s", backendName)
+	}
+	switch backend {
+	case GitCLI:
+		return NewCmdGitBackend(), nil
+	case GoGit:
+		return NewGoGitBackend(), nil
+	}
+	return nil, fmt.Errorf("no git backend implementation found for %s", backendName)
+}
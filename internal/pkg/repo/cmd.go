@@ -1,12 +1,18 @@
 package repo
 
 import (
+	"bytes"
 	"fmt"
 	"os/exec"
+	"strconv"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 )
 
+// NoTagsFoundErrorMsg with the error to be reported if no previous tags are found.
+const NoTagsFoundErrorMsg = "No names found, cannot describe anything"
+
 // CmdUtils structure with helper methods to execute commands.
 type CmdUtils struct {
 }
@@ -22,3 +28,337 @@ func (cu *CmdUtils) execCmd(cmd string, args []string, workingDir string) (strin
 	log.Debug().Str("output", string(stdoutStderr)).Msg("execution finished")
 	return string(stdoutStderr), nil
 }
+
+// execCmdOutput executes a given command and returns only its standard output, so messages written to the standard
+// error (e.g., warnings) are not mixed with the result.
+func (cu *CmdUtils) execCmdOutput(cmd string, args []string, workingDir string) ([]byte, error) {
+	toExecute := exec.Command("git", args...)
+	toExecute.Dir = workingDir
+	var stderr bytes.Buffer
+	toExecute.Stderr = &stderr
+	stdout, err := toExecute.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to execute command %s due to %w, %s", cmd, err, stderr.String())
+	}
+	log.Debug().Int("bytes", len(stdout)).Str("stderr", stderr.String()).Msg("execution finished")
+	return stdout, nil
+}
+
+// CmdGitBackend structure with the implementation of the git operations relying on the git binary. The credentials
+// of HTTPS remotes are passed on each command as an HTTP header, so they are not stored on the cloned repositories.
+type CmdGitBackend struct {
+	CmdUtils
+	remoteCredentials
+}
+
+// NewCmdGitBackend creates a backend that executes the git binary found on the PATH.
+func NewCmdGitBackend() GitBackend {
+	return &CmdGitBackend{}
+}
+
+// depthArgs returns the arguments that limit the history to be fetched, if any.
+func (cgb *CmdGitBackend) depthArgs(depth int) []string {
+	if depth > 0 {
+		return []string{"--depth", strconv.Itoa(depth)}
+	}
+	return []string{}
+}
+
+// remoteArgs prepends the credentials of the origin remote of a repository, if any, to the arguments of a command
+// that contacts it.
+// git -c http.extraHeader="Authorization: Basic <credentials>" <args>
+func (cgb *CmdGitBackend) remoteArgs(repoPath string, args ...string) []string {
+	header := cgb.authorizationHeader(repoPath)
+	if header == "" {
+		return args
+	}
+	return append([]string{"-c", fmt.Sprintf("http.extraHeader=%s", header)}, args...)
+}
+
+// fetchTags fetches all the tags of the origin remote, overwriting the local ones. On shallow clones, only the
+// tagged commits up to the given depth are retrieved. An explicit tags refspec is not used, as shallow fetches fail
+// when it does not match any reference of a remote without tags.
+// git fetch --tags --force [--depth <depth>] origin
+func (cgb *CmdGitBackend) fetchTags(repoPath string, depth int) error {
+	cmdArgs := append([]string{"fetch", "--tags", "--force"}, cgb.depthArgs(depth)...)
+	cmdArgs = append(cmdArgs, "origin")
+	_, err := cgb.execCmd("git", cgb.remoteArgs(repoPath, cmdArgs...), repoPath)
+	return err
+}
+
+// Clone a given repository to a path. The current branch of empty repositories is the one advertised by the
+// remote, or the given default branch if the remote does not advertise it.
+func (cgb *CmdGitBackend) Clone(repoURL string, outputPath string, depth int, defaultBranch string) error {
+	// TODO Check output path exists.
+	remoteURL := cgb.register(repoURL, outputPath)
+	cmdArgs := append([]string{"clone"}, cgb.depthArgs(depth)...)
+	cmdArgs = append(cmdArgs, remoteURL, outputPath)
+
+	cmd := exec.Command("git", cgb.remoteArgs(outputPath, cmdArgs...)...)
+	stdoutStderr, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unable to clone repo %s due to %w", remoteURL, err)
+	}
+	log.Debug().Str("output", string(stdoutStderr)).Msg("repo successfully cloned")
+
+	if _, err := cgb.execCmd("git", []string{"rev-parse", "--verify", "--quiet", "HEAD"}, outputPath); err != nil {
+		return cgb.setUnbornBranch(outputPath, defaultBranch)
+	}
+	// Shallow clones do not include the tags of previous commits.
+	if depth > 0 {
+		return cgb.fetchTags(outputPath, depth)
+	}
+	return nil
+}
+
+// setUnbornBranch points the current branch of an empty clone to the given default branch if the remote does not
+// advertise its HEAD, which depends on the version of the git server.
+// git ls-remote --symref origin HEAD
+func (cgb *CmdGitBackend) setUnbornBranch(repoPath string, defaultBranch string) error {
+	if defaultBranch == "" {
+		return nil
+	}
+	output, err := cgb.execCmd("git", cgb.remoteArgs(repoPath, "ls-remote", "--symref", "origin", "HEAD"), repoPath)
+	if err == nil && strings.HasPrefix(strings.TrimSpace(output), "ref:") {
+		return nil
+	}
+	branch := fmt.Sprintf("refs/heads/%s", defaultBranch)
+	settings := [][]string{
+		{"symbolic-ref", "HEAD", branch},
+		{"config", fmt.Sprintf("branch.%s.remote", defaultBranch), "origin"},
+		{"config", fmt.Sprintf("branch.%s.merge", defaultBranch), branch},
+	}
+	for _, args := range settings {
+		if _, err := cgb.execCmd("git", args, repoPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update fetches the latest changes and tags of a previously cloned repository from the given URL, and resets the
+// working tree to the remote state of the current branch removing any local change.
+func (cgb *CmdGitBackend) Update(repoURL string, repoPath string, depth int) error {
+	// The URL may change across runs, and previous versions stored the access token on it.
+	if _, err := cgb.execCmd("git", []string{"remote", "set-url", "origin", cgb.register(repoURL, repoPath)}, repoPath); err != nil {
+		return err
+	}
+	fetchArgs := append([]string{"fetch", "--prune"}, cgb.depthArgs(depth)...)
+	if _, err := cgb.execCmd("git", cgb.remoteArgs(repoPath, append(fetchArgs, "origin")...), repoPath); err != nil {
+		return err
+	}
+	if err := cgb.fetchTags(repoPath, depth); err != nil {
+		return err
+	}
+	if _, err := cgb.execCmd("git", []string{"rev-parse", "--verify", "--quiet", "@{upstream}"}, repoPath); err != nil {
+		return fmt.Errorf("current branch of %s does not track a remote branch", repoPath)
+	}
+	if _, err := cgb.execCmd("git", []string{"reset", "--hard", "@{upstream}"}, repoPath); err != nil {
+		return err
+	}
+	_, err := cgb.execCmd("git", []string{"clean", "-ffdx"}, repoPath)
+	return err
+}
+
+// LatestTag obtains the most recent tag reachable from the current commit.
+// git describe --abbrev=0 --tags
+func (cgb *CmdGitBackend) LatestTag(repoPath string) (string, error) {
+	cmdArgs := []string{"describe", "--abbrev=0", "--tags"}
+
+	cmd := exec.Command("git", cmdArgs...)
+	cmd.Dir = repoPath
+	stdoutStderr, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(stdoutStderr), NoTagsFoundErrorMsg) {
+			return "", nil
+		}
+		return "", fmt.Errorf("unable to obtain latest tag from repo %s due to %w, %s", repoPath, err, string(stdoutStderr))
+	}
+	return strings.TrimSpace(string(stdoutStderr)), nil
+}
+
+// Tags returns the names of all the tags of the repository.
+func (cgb *CmdGitBackend) Tags(repoPath string) ([]string, error) {
+	output, err := cgb.execCmd("git", []string{"tag", "--list"}, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+// ReadFile returns the content of a file on a given revision. A nil content is returned if the file does not exist
+// on that revision.
+// git show <revision>:<path>
+func (cgb *CmdGitBackend) ReadFile(repoPath string, revision string, filePath string) ([]byte, error) {
+	object := fmt.Sprintf("%s:%s", revision, filePath)
+	if _, err := cgb.execCmd("git", []string{"cat-file", "-e", object}, repoPath); err != nil {
+		if _, err := cgb.execCmd("git", []string{"rev-parse", "--verify", "--quiet", revision}, repoPath); err != nil {
+			return nil, fmt.Errorf("revision %s not found on %s", revision, repoPath)
+		}
+		return nil, nil
+	}
+	return cgb.execCmdOutput("git", []string{"show", object}, repoPath)
+}
+
+// setIdentity sets the author information on the local repository. Given that this is executed from a local
+// temporal copy, there should not be any collateral impact in configuring the local repo.
+func (cgb *CmdGitBackend) setIdentity(repoPath string, author Signature) error {
+	if author.Name != "" {
+		// git config user.name "Your Name"
+		if _, err := cgb.execCmd("git", []string{"config", "user.name", author.Name}, repoPath); err != nil {
+			return err
+		}
+	}
+	if author.Email != "" {
+		// git config user.email "my.name@server.com"
+		if _, err := cgb.execCmd("git", []string{"config", "user.email", author.Email}, repoPath); err != nil {
+			return err
+		}
+	}
+	return cgb.setSigning(repoPath, author.SigningKey)
+}
+
+// setSigning configures the key used to sign commits and tags on the local repository.
+func (cgb *CmdGitBackend) setSigning(repoPath string, signingKey *SigningKey) error {
+	if signingKey == nil {
+		return nil
+	}
+	// git config gpg.format ssh
+	// git config user.signingkey ~/.ssh/id_ed25519.pub
+	settings := [][]string{
+		{"gpg.format", gitSigningFormat[signingKey.Format]},
+		{"user.signingkey", signingKey.Key},
+	}
+	if signingKey.Program != "" {
+		settings = append(settings, []string{signingKey.programSetting(), signingKey.Program})
+	}
+	for _, setting := range settings {
+		if _, err := cgb.execCmd("git", []string{"config", setting[0], setting[1]}, repoPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddAll stages all the changes of the working tree, including removed files.
+func (cgb *CmdGitBackend) AddAll(repoPath string) error {
+	_, err := cgb.execCmd("git", []string{"add", "-A"}, repoPath)
+	return err
+}
+
+// HasChanges checks if there are staged or unstaged changes on the working tree.
+func (cgb *CmdGitBackend) HasChanges(repoPath string) (bool, error) {
+	status, err := cgb.execCmd("git", []string{"status", "--porcelain"}, repoPath)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(status) != "", nil
+}
+
+// Commit the staged changes.
+func (cgb *CmdGitBackend) Commit(repoPath string, message string, author Signature) error {
+	if err := cgb.setIdentity(repoPath, author); err != nil {
+		return err
+	}
+	args := []string{"commit", "-a", "-m", message}
+	if author.SigningKey != nil {
+		args = append(args, "-S")
+	}
+	_, err := cgb.execCmd("git", args, repoPath)
+	if err != nil && author.SigningKey != nil {
+		return fmt.Errorf("unable to create signed commit with %s key %s: %w", SigningFormatToString[author.SigningKey.Format], author.SigningKey.Key, err)
+	}
+	return err
+}
+
+// HasCommit checks if a commit is available on the local repository.
+// git cat-file -e <commit>^{commit}
+func (cgb *CmdGitBackend) HasCommit(repoPath string, commit string) (bool, error) {
+	_, err := cgb.execCmd("git", []string{"cat-file", "-e", fmt.Sprintf("%s^{commit}", commit)}, repoPath)
+	return err == nil, nil
+}
+
+// FetchBranch fetches the complete history of a branch of the origin remote, deepening shallow clones.
+// git fetch [--unshallow] origin +refs/heads/<branch>:refs/remotes/origin/<branch>
+func (cgb *CmdGitBackend) FetchBranch(repoPath string, branch string) error {
+	output, err := cgb.execCmd("git", []string{"rev-parse", "--is-shallow-repository"}, repoPath)
+	if err != nil {
+		return err
+	}
+	cmdArgs := []string{"fetch"}
+	if strings.TrimSpace(output) == "true" {
+		cmdArgs = append(cmdArgs, "--unshallow")
+	}
+	refSpec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", branch, branch)
+	_, err = cgb.execCmd("git", cgb.remoteArgs(repoPath, append(cmdArgs, "origin", refSpec)...), repoPath)
+	return err
+}
+
+// HeadCommit returns the hash of the current commit.
+func (cgb *CmdGitBackend) HeadCommit(repoPath string) (string, error) {
+	output, err := cgb.execCmd("git", []string{"rev-parse", "HEAD"}, repoPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// CurrentBranch returns the name of the current branch.
+func (cgb *CmdGitBackend) CurrentBranch(repoPath string) (string, error) {
+	output, err := cgb.execCmd("git", []string{"symbolic-ref", "--short", "HEAD"}, repoPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Tag a given commit with an annotated tag.
+// git tag -a v1.4 -m "my version 1.4" <commit>
+func (cgb *CmdGitBackend) Tag(repoPath string, commit string, name string, message string, tagger Signature) error {
+	if err := cgb.setIdentity(repoPath, tagger); err != nil {
+		return err
+	}
+	// Signed tags are annotated as well.
+	mode := "-a"
+	if tagger.SigningKey != nil {
+		mode = "-s"
+	}
+	_, err := cgb.execCmd("git", []string{"tag", mode, name, "-m", message, commit}, repoPath)
+	if err != nil && tagger.SigningKey != nil {
+		return fmt.Errorf("unable to create signed tag with %s key %s: %w", SigningFormatToString[tagger.SigningKey.Format], tagger.SigningKey.Key, err)
+	}
+	return err
+}
+
+// Push the current branch to the origin remote.
+func (cgb *CmdGitBackend) Push(repoPath string) error {
+	_, err := cgb.execCmd("git", cgb.remoteArgs(repoPath, "push"), repoPath)
+	return err
+}
+
+// PushBranch pushes the current commit to a given branch of the origin remote, overwriting its previous content.
+// git push --force origin HEAD:refs/heads/<branch>
+func (cgb *CmdGitBackend) PushBranch(repoPath string, branch string) error {
+	_, err := cgb.execCmd("git", cgb.remoteArgs(repoPath, "push", "--force", "origin", fmt.Sprintf("HEAD:refs/heads/%s", branch)), repoPath)
+	return err
+}
+
+// PushTags pushes all the tags to the origin remote.
+// git push origin --tags
+func (cgb *CmdGitBackend) PushTags(repoPath string) error {
+	_, err := cgb.execCmd("git", cgb.remoteArgs(repoPath, "push", "origin", "--tags"), repoPath)
+	return err
+}
+
+// InitBare creates an empty bare repository whose HEAD points to the given branch, if any.
+func (cgb *CmdGitBackend) InitBare(repoPath string, defaultBranch string) error {
+	if _, err := cgb.execCmd("git", []string{"init", "--bare"}, repoPath); err != nil {
+		return err
+	}
+	if defaultBranch != "" {
+		if _, err := cgb.execCmd("git", []string{"symbolic-ref", "HEAD", fmt.Sprintf("refs/heads/%s", defaultBranch)}, repoPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCmdGitBackendReadFileIgnoresStderr(t *testing.T) {
+	repoPath := t.TempDir()
+	backend := NewCmdGitBackend()
+	cmd := &CmdUtils{}
+	if _, err := cmd.execCmd("git", []string{"init", "-q"}, repoPath); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("{\"version\": 1}\n")
+	if err := ioutil.WriteFile(filepath.Join(repoPath, "manifest.json"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.AddAll(repoPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Commit(repoPath, "first", Signature{Name: "gpm", Email: "gpm@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tracing makes git write to the standard error on every command.
+	os.Setenv("GIT_TRACE", "1")
+	defer os.Unsetenv("GIT_TRACE")
+
+	read, err := backend.ReadFile(repoPath, "HEAD", "manifest.json")
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+	if string(read) != string(content) {
+		t.Errorf("expected %q, got %q", content, read)
+	}
+	missing, err := backend.ReadFile(repoPath, "HEAD", "missing.json")
+	if err != nil || missing != nil {
+		t.Errorf("expected nil content for missing file, got %q, %v", missing, err)
+	}
+	if _, err := backend.ReadFile(repoPath, "v9.9.9", "manifest.json"); err == nil {
+		t.Errorf("expected error for unknown revision")
+	}
+}
+
+func TestCmdGitBackendShallowCloneWithoutTags(t *testing.T) {
+	baseDir := t.TempDir()
+	remotePath := filepath.Join(baseDir, "remote.git")
+	writerPath := filepath.Join(baseDir, "writer")
+	backend := NewCmdGitBackend()
+	if err := os.MkdirAll(remotePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.InitBare(remotePath, "main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Clone(FileURLPrefix+remotePath, writerPath, 0, "main"); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, backend, writerPath, "README.md")
+	if err := backend.Push(writerPath); err != nil {
+		t.Fatal(err)
+	}
+
+	shallowPath := filepath.Join(baseDir, "shallow")
+	if err := backend.Clone(FileURLPrefix+remotePath, shallowPath, ShallowCloneDepth, "main"); err != nil {
+		t.Fatalf("unable to clone repository without tags: %v", err)
+	}
+	if err := backend.Update(FileURLPrefix+remotePath, shallowPath, ShallowCloneDepth); err != nil {
+		t.Fatalf("unable to update repository without tags: %v", err)
+	}
+	tag, err := backend.LatestTag(shallowPath)
+	if err != nil || tag != "" {
+		t.Errorf("expected no tags, got %q, %v", tag, err)
+	}
+}
+
+// commitFile writes a file on a repository and commits it, returning the hash of the new commit.
+func commitFile(t *testing.T, backend GitBackend, repoPath string, fileName string) string {
+	t.Helper()
+	if err := ioutil.WriteFile(filepath.Join(repoPath, fileName), []byte(fileName), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.AddAll(repoPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Commit(repoPath, fileName, Signature{Name: "gpm", Email: "gpm@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	commit, err := backend.HeadCommit(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return commit
+}
+
+func TestCmdGitBackendFetchCommitOnShallowClone(t *testing.T) {
+	baseDir := t.TempDir()
+	remotePath := filepath.Join(baseDir, "remote.git")
+	writerPath := filepath.Join(baseDir, "writer")
+	backend := NewCmdGitBackend()
+	if err := os.MkdirAll(remotePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.InitBare(remotePath, "main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Clone(FileURLPrefix+remotePath, writerPath, 0, "main"); err != nil {
+		t.Fatal(err)
+	}
+	merged := commitFile(t, backend, writerPath, "first.txt")
+	commitFile(t, backend, writerPath, "second.txt")
+	if err := backend.Push(writerPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// The merged commit is not the latest one, so a shallow clone does not contain it.
+	provider := &GHCommon{Backend: backend, CloneDepth: ShallowCloneDepth}
+	shallowPath := filepath.Join(baseDir, "shallow")
+	if err := provider.Clone(FileURLPrefix+remotePath, shallowPath); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := backend.HasCommit(shallowPath, merged); err != nil || exists {
+		t.Fatalf("expected commit missing on shallow clone, got %v, %v", exists, err)
+	}
+	if err := provider.FetchCommit(shallowPath, merged, "main"); err != nil {
+		t.Fatalf("unable to fetch commit: %v", err)
+	}
+	if exists, err := backend.HasCommit(shallowPath, merged); err != nil || !exists {
+		t.Errorf("expected commit fetched, got %v, %v", exists, err)
+	}
+	if err := provider.FetchCommit(shallowPath, "0123456789012345678901234567890123456789", "main"); err == nil {
+		t.Errorf("expected error for unknown commit")
+	}
+}
@@ -0,0 +1,88 @@
+package repo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// apiTimeout with the maximum duration of a request to the API of a repository provider.
+const apiTimeout = 30 * time.Second
+
+// RepoSettings structure with the settings applied to newly created repositories.
+type RepoSettings struct {
+	// Public determines if the repository is visible to everyone. Repositories are private by default.
+	Public bool
+	// Description of the repository.
+	Description string
+	// DefaultBranch with the name of the default branch. If empty, the provider default is used.
+	DefaultBranch string
+	// Topics associated with the repository.
+	Topics []string
+}
+
+// Creator defines the interface for the repository providers that are able to create the target repositories.
+type Creator interface {
+	// CreateRepoIfNotExists checks if a repository exists and creates it otherwise.
+	CreateRepoIfNotExists(organization string, repoName string, settings RepoSettings) error
+}
+
+// apiClient structure with helper methods to interact with the REST API of a repository provider.
+type apiClient struct {
+	client  *http.Client
+	headers map[string]string
+}
+
+// newAPIClient creates a client that sends the given headers on each request.
+func newAPIClient(headers map[string]string) *apiClient {
+	return &apiClient{
+		client:  &http.Client{Timeout: apiTimeout},
+		headers: headers,
+	}
+}
+
+// do sends a request to the API. The response body is decoded into result if not nil. The status code is returned
+// so callers may react to specific values such as 404.
+func (ac *apiClient) do(method string, url string, body interface{}, result interface{}) (int, error) {
+	var payload io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		payload = bytes.NewReader(encoded)
+	}
+	request, err := http.NewRequest(method, url, payload)
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range ac.headers {
+		request.Header.Set(key, value)
+	}
+	log.Debug().Str("method", method).Str("url", url).Msg("sending API request")
+	response, err := ac.client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("unable to send request to %s: %w", url, err)
+	}
+	defer response.Body.Close()
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return response.StatusCode, err
+	}
+	if response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("request %s %s failed with status %d: %s", method, url, response.StatusCode, string(content))
+	}
+	if result != nil && len(content) > 0 {
+		if err := json.Unmarshal(content, result); err != nil {
+			return response.StatusCode, fmt.Errorf("unable to decode response from %s: %w", url, err)
+		}
+	}
+	return response.StatusCode, nil
+}
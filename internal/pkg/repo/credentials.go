@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sync"
+)
+
+// remoteCredentials structure with the credentials of the remotes of the cloned repositories. Access tokens are
+// kept in memory and passed on each remote operation, so they are not stored on the git configuration of the
+// cloned repositories (e.g., on the cache directory).
+type remoteCredentials struct {
+	users map[string]*url.Userinfo
+	mutex sync.Mutex
+}
+
+// stripCredentials removes the user information of HTTP(S) repository URLs, returning the URL without it and
+// the removed credentials, if any. The user of SSH URLs is not a secret, so they are returned unchanged.
+func stripCredentials(repoURL string) (string, *url.Userinfo) {
+	parsed, err := url.Parse(repoURL)
+	if err != nil || parsed.User == nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return repoURL, nil
+	}
+	user := parsed.User
+	parsed.User = nil
+	return parsed.String(), user
+}
+
+// register removes the credentials of the URL of the remote of a repository cloned on a given path, keeping them for
+// the next remote operations. The URL to be stored on the repository is returned.
+func (rc *remoteCredentials) register(repoURL string, repoPath string) string {
+	remoteURL, user := stripCredentials(repoURL)
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	if rc.users == nil {
+		rc.users = make(map[string]*url.Userinfo, 0)
+	}
+	if user == nil {
+		delete(rc.users, filepath.Clean(repoPath))
+	} else {
+		rc.users[filepath.Clean(repoPath)] = user
+	}
+	return remoteURL
+}
+
+// credentials returns the username and password of the remote of a repository. The last return value is false if
+// the remote has no credentials.
+func (rc *remoteCredentials) credentials(repoPath string) (string, string, bool) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	user, exists := rc.users[filepath.Clean(repoPath)]
+	if !exists {
+		return "", "", false
+	}
+	password, _ := user.Password()
+	return user.Username(), password, true
+}
+
+// authorizationHeader returns the HTTP header with the basic authentication of the remote of a repository, or an
+// empty string if the remote has no credentials.
+func (rc *remoteCredentials) authorizationHeader(repoPath string) string {
+	username, password, exists := rc.credentials(repoPath)
+	if !exists {
+		return ""
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, password)))
+	return fmt.Sprintf("Authorization: Basic %s", encoded)
+}
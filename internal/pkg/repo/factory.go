@@ -13,18 +13,26 @@ const (
 	GitHub RepositoryType = iota
 	// GitHubAction corresponds to a git provider being executed from within a GitHub Action.
 	GitHubAction
+	// GitLab cloud or self-hosted instance.
+	GitLab
+	// Git corresponds to a generic git server whose URLs are built from a template.
+	Git
 )
 
 // RepositoryTypeToString map associating type to its string representation.
 var RepositoryTypeToString = map[RepositoryType]string{
 	GitHub:       "github",
 	GitHubAction: "githubaction",
+	GitLab:       "gitlab",
+	Git:          "git",
 }
 
 // RepositoryTypeToEnum map associating string representation with type.
 var RepositoryTypeToEnum = map[string]RepositoryType{
 	"github":       GitHub,
 	"githubaction": GitHubAction,
+	"gitlab":       GitLab,
+	"git":          Git,
 }
 
 // Provider defines the common interface for different repository managers (e.g., GitHub)
@@ -35,23 +43,83 @@ type Provider interface {
 	GetRepoURL(organization string, repoName string) (string, error)
 	// Clone a given repository to a path
 	Clone(repoURL string, outputPath string) error
-	// GetLastVersion obtains the latest version of the repo.
+	// Update a previously cloned repository discarding any local change.
+	Update(repoURL string, repoPath string) error
+	// GetLastVersion obtains the latest released version of the repo.
 	GetLastVersion(repoPath string) (*Version, error)
-	// Publish the changes and create a new version tag.
-	Publish(repoPath string, newVersion *Version) error
+	// PreReleases returns the pre-release versions of the repo whose first identifier matches the given one, sorted
+	// by precedence.
+	PreReleases(repoPath string, identifier string) ([]*Version, error)
+	// ReadFile returns the content of a file on a given revision of the repo, or nil if it does not exist.
+	ReadFile(repoPath string, revision string, filePath string) ([]byte, error)
+	// Publish the changes and create a new version tag. The hash of the pushed commit is returned, or an empty
+	// string if there was nothing to publish.
+	Publish(repoPath string, newVersion *Version, messages PublishMessages) (string, error)
+	// PublishBranch commits the changes and pushes them to a given branch instead of the current one. The hash of
+	// the pushed commit is returned, or an empty string if there was nothing to publish.
+	PublishBranch(repoPath string, branch string, commitMessage string) (string, error)
+	// CurrentBranch returns the name of the current branch of the repo.
+	CurrentBranch(repoPath string) (string, error)
+	// FetchCommit makes a commit of a given branch available on the local copy of the repo, which may not contain
+	// it if it is shallow or outdated.
+	FetchCommit(repoPath string, commit string, branch string) error
+	// TagVersion creates the tag of a version on a given commit and pushes it.
+	TagVersion(repoPath string, commit string, version *Version, tagMessage string) error
+}
+
+// PublishMessages structure with the messages used to publish a new version.
+type PublishMessages struct {
+	// Commit with the message of the commit containing the generated code.
+	Commit string
+	// Tag with the annotation of the version tag.
+	Tag string
+}
+
+// ProviderOptions structure with the settings that may be used by the different repository providers.
+type ProviderOptions struct {
+	// Host of the repository server. If empty, the default host of the provider is used.
+	Host string
+	// DeployTokenUser with the username associated with a deploy token. Only used by providers supporting deploy tokens.
+	DeployTokenUser string
+	// URLTemplate with the template used to build the repository URLs. Only used by the generic git provider.
+	URLTemplate string
+	// GitBackend with the name of the git implementation (cli or gogit). If empty, the git binary is used.
+	GitBackend string
+	// ShallowClone determines if only the latest commit and the tags of the repositories are retrieved.
+	ShallowClone bool
+	// DefaultBranch with the branch used by empty repositories that do not advertise their HEAD. If empty, the
+	// default of the git backend is used.
+	DefaultBranch string
+	// SigningKey used to sign the commits and tags. If nil, they are not signed.
+	SigningKey *SigningKey
+}
+
+// ShallowCloneDepth with the number of commits retrieved on shallow clones.
+const ShallowCloneDepth = 1
+
+// cloneDepth returns the number of commits to be retrieved attending to the options.
+func (po *ProviderOptions) cloneDepth() int {
+	if po.ShallowClone {
+		return ShallowCloneDepth
+	}
+	return 0
 }
 
 // NewRepoProvider factory method to instantiate a repository provider for a given system.
-func NewRepoProvider(repoProviderName string) (Provider, error) {
+func NewRepoProvider(repoProviderName string, options ProviderOptions) (Provider, error) {
 	provider, exists := RepositoryTypeToEnum[strings.ToLower(repoProviderName)]
 	if !exists {
 		return nil, fmt.Errorf("Provider not found for %s", repoProviderName)
 	}
 	switch provider {
 	case GitHub:
-		return NewGitHubCmdProvider()
+		return NewGitHubCmdProvider(options)
 	case GitHubAction:
-		return NewGitHubActionProvider()
+		return NewGitHubActionProvider(options)
+	case GitLab:
+		return NewGitLabCmdProvider(options)
+	case Git:
+		return NewGitCmdProvider(options)
 	}
 	return nil, fmt.Errorf("No provider implementation found for %s", repoProviderName)
 }
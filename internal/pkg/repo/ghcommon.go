@@ -2,19 +2,26 @@ package repo
 
 import (
 	"fmt"
-	"os/exec"
+	"sort"
 	"strings"
 
 	"github.com/rs/zerolog/log"
 )
 
-// NoTagsFoundErrorMsg with the error to be reported if no previous tags are found.
-const NoTagsFoundErrorMsg = "No names found, cannot describe anything"
+// GitHubDefaultHost with the host used to reach GitHub if none is configured.
+const GitHubDefaultHost = "github.com"
 
 // GHCommon structure with common operation over GitHub. Notice that depending on the environment.
 // some options may apply.
 type GHCommon struct {
-	CmdUtils
+	// Backend with the implementation of the git operations.
+	Backend GitBackend
+	// CloneDepth with the number of commits retrieved on clone and update operations. Zero retrieves the whole history.
+	CloneDepth int
+	// DefaultBranch with the branch used by empty repositories that do not advertise their HEAD.
+	DefaultBranch string
+	// Host with the name of the git server (e.g., github.com).
+	Host string
 	// UseSSH determines if clone operations will use SSH credentials.
 	UseSSH bool
 	// UseHTTPS determines if clone operations will use HTTPS credentials.
@@ -29,6 +36,8 @@ type GHCommon struct {
 	SetPusherEmail bool
 	// PusherEmail with the email of the commiter.
 	PusherEmail string
+	// SigningKey used to sign the commits and tags. If nil, they are not signed.
+	SigningKey *SigningKey
 }
 
 // ConfigurePusher prepares the system to use a particular username/email to appear as the pusher of the commits.
@@ -60,14 +69,14 @@ func (ghc *GHCommon) ConfigurePusher(username string, email string, accessToken
 func (ghc *GHCommon) GetRepoURL(organization string, repoName string) (string, error) {
 	if ghc.UseSSH {
 		// git@github.com:dhiguero/go-template.git
-		return fmt.Sprintf("git@github.com:%s/%s.git", organization, repoName), nil
+		return fmt.Sprintf("git@%s:%s/%s.git", ghc.Host, organization, repoName), nil
 	} else if ghc.UseHTTPS {
 		if ghc.PersonalAccessToken == "" {
 			// https://github.com/dhiguero/go-template.git
-			return fmt.Sprintf("https://github.com/%s/%s.git", organization, repoName), nil
+			return fmt.Sprintf("https://%s/%s/%s.git", ghc.Host, organization, repoName), nil
 		}
 		// https://{TOKEN}@github.com/dhiguero/go-template.git
-		return fmt.Sprintf("https://%s@github.com/%s/%s.git", ghc.PersonalAccessToken, organization, repoName), nil
+		return fmt.Sprintf("https://%s@%s/%s/%s.git", ghc.PersonalAccessToken, ghc.Host, organization, repoName), nil
 	}
 	return "", fmt.Errorf("cannot obtain target repo URL. Set useSSH or UseHTTPS")
 }
@@ -75,108 +84,198 @@ func (ghc *GHCommon) GetRepoURL(organization string, repoName string) (string, e
 // Clone a given repository to a path
 func (ghc *GHCommon) Clone(repoURL string, outputPath string) error {
 	log.Debug().Str("repoURL", repoURL).Str("outputPath", outputPath).Msg("cloning repository")
-	// TODO Check output path exists.
-	cmdArgs := []string{"clone", repoURL, outputPath}
+	return ghc.Backend.Clone(repoURL, outputPath, ghc.CloneDepth, ghc.DefaultBranch)
+}
+
+// Update a previously cloned repository discarding any local change.
+func (ghc *GHCommon) Update(repoURL string, repoPath string) error {
+	log.Debug().Str("repoPath", repoPath).Msg("updating repository")
+	return ghc.Backend.Update(repoURL, repoPath, ghc.CloneDepth)
+}
 
-	cmd := exec.Command("git", cmdArgs...)
-	stdoutStderr, err := cmd.CombinedOutput()
+// highestVersion returns the highest released version among the tags of the repo. Tags that are not versions and
+// pre-releases are ignored.
+func (ghc *GHCommon) highestVersion(repoPath string) (*Version, error) {
+	tags, err := ghc.Backend.Tags(repoPath)
 	if err != nil {
-		return fmt.Errorf("unable to clone repo %s due to %w", repoURL, err)
+		return nil, err
 	}
-
-	log.Debug().Str("output", string(stdoutStderr)).Msg("repo successfully cloned")
-	return nil
+	result := EmptyVersion()
+	for _, tag := range tags {
+		version, err := FromTag(tag)
+		if err != nil {
+			log.Debug().Str("tag", tag).Msg("ignoring tag")
+			continue
+		}
+		if !version.IsPreRelease() && result.LessThan(version) {
+			result = version
+		}
+	}
+	return result, nil
 }
 
-// GetLastVersion obtains the latest version of the repo.
-// git describe --abbrev=0 --tags
+// GetLastVersion obtains the latest released version of the repo. As shallow clones do not contain the whole history,
+// the highest version among the tags is used instead of the most recent reachable tag. The same applies if the most
+// recent tag is a pre-release.
 func (ghc *GHCommon) GetLastVersion(repoPath string) (*Version, error) {
 	log.Debug().Str("repoPath", repoPath).Msg("obtaining latest tag")
-	// TODO Check output path exists.
-	cmdArgs := []string{"describe", "--abbrev=0", "--tags"}
+	if ghc.CloneDepth > 0 {
+		return ghc.highestVersion(repoPath)
+	}
+	tag, err := ghc.Backend.LatestTag(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if tag == "" {
+		return EmptyVersion(), nil
+	}
+	log.Debug().Str("tag", tag).Msg("latest tag found")
+	version, err := FromTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	if version.IsPreRelease() {
+		return ghc.highestVersion(repoPath)
+	}
+	return version, nil
+}
 
-	cmd := exec.Command("git", cmdArgs...)
-	cmd.Dir = repoPath
-	stdoutStderr, err := cmd.CombinedOutput()
+// PreReleases returns the pre-release versions of the repo whose first identifier matches the given one, sorted by
+// precedence.
+func (ghc *GHCommon) PreReleases(repoPath string, identifier string) ([]*Version, error) {
+	tags, err := ghc.Backend.Tags(repoPath)
 	if err != nil {
-		if strings.Contains(string(stdoutStderr), NoTagsFoundErrorMsg) {
-			return EmptyVersion(), nil
+		return nil, err
+	}
+	result := make([]*Version, 0)
+	for _, tag := range tags {
+		version, err := FromTag(tag)
+		if err != nil || !version.IsPreRelease() || version.PreRelease[0] != identifier {
+			continue
 		}
-		return nil, fmt.Errorf("unable to obtain latest tag from repo %s due to %w, %s", repoPath, err, string(stdoutStderr))
+		result = append(result, version)
 	}
-
-	log.Debug().Str("output", string(stdoutStderr)).Msg("repo successfully cloned")
-	return FromTag(string(stdoutStderr))
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LessThan(result[j])
+	})
+	return result, nil
 }
 
-// SetPusherInfo sets the pusher information of the local repository.
-func (ghc *GHCommon) SetPusherInfo(repoPath string) error {
+// ReadFile returns the content of a file on a given revision of the repo, or nil if it does not exist.
+func (ghc *GHCommon) ReadFile(repoPath string, revision string, filePath string) ([]byte, error) {
+	return ghc.Backend.ReadFile(repoPath, revision, filePath)
+}
 
+// pusher returns the identity used to author commits and tags. Empty fields are taken from the git configuration.
+func (ghc *GHCommon) pusher() Signature {
+	result := Signature{SigningKey: ghc.SigningKey}
 	if ghc.SetPusherUserName {
-		// Set the name
-		// git config -f /tmp/grpc-internal-agenda-go/.git/config user.name \"Your Name\"
-		userNameArgs := []string{"config", "user.name", ghc.PusherUserName}
-		_, err := ghc.execCmd("git", userNameArgs, repoPath)
-		if err != nil {
-			return err
-		}
+		result.Name = ghc.PusherUserName
 	}
-
 	if ghc.SetPusherEmail {
-		// Set the email
-		// git config -f /tmp/grpc-internal-agenda-go/.git/config user.email "my.name@server.com"
-		userEmailArgs := []string{"config", "user.email", ghc.PusherEmail}
-		_, err := ghc.execCmd("git", userEmailArgs, repoPath)
-		if err != nil {
-			return err
-		}
+		result.Email = ghc.PusherEmail
 	}
+	return result
+}
 
-	return nil
+// commit stages and commits all the changes of the working tree. The hash of the commit is returned, or an empty
+// string if there was nothing to commit.
+func (ghc *GHCommon) commit(repoPath string, message string) (string, error) {
+	// Add all new files
+	if err := ghc.Backend.AddAll(repoPath); err != nil {
+		return "", err
+	}
+	// Regenerating the code may produce the same files, for example if only a dependency has changed.
+	changed, err := ghc.Backend.HasChanges(repoPath)
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		log.Warn().Str("repoPath", repoPath).Msg("generated code has not changed, skipping publication")
+		return "", nil
+	}
+	// Commit changes
+	if err := ghc.Backend.Commit(repoPath, message, ghc.pusher()); err != nil {
+		return "", err
+	}
+	return ghc.Backend.HeadCommit(repoPath)
 }
 
-// Publish the changes and create a new version tag.
-func (ghc *GHCommon) Publish(repoPath string, newVersion *Version) error {
+// Publish the changes and create a new version tag. The hash of the pushed commit is returned, or an empty
+// string if there was nothing to publish.
+func (ghc *GHCommon) Publish(repoPath string, newVersion *Version, messages PublishMessages) (string, error) {
 	log.Debug().Str("repoPath", repoPath).Str("version", newVersion.String()).Msg("publishing version")
+	commit, err := ghc.commit(repoPath, messages.Commit)
+	if err != nil || commit == "" {
+		return "", err
+	}
+	// Push changes
+	if err := ghc.Backend.Push(repoPath); err != nil {
+		return "", err
+	}
+	if err := ghc.TagVersion(repoPath, commit, newVersion, messages.Tag); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
 
-	err := ghc.SetPusherInfo(repoPath)
-	if err != nil {
-		return err
+// PublishBranch commits the changes and pushes them to a given branch instead of the current one. The hash of the
+// pushed commit is returned, or an empty string if there was nothing to publish.
+func (ghc *GHCommon) PublishBranch(repoPath string, branch string, commitMessage string) (string, error) {
+	log.Debug().Str("repoPath", repoPath).Str("branch", branch).Msg("publishing branch")
+	commit, err := ghc.commit(repoPath, commitMessage)
+	if err != nil || commit == "" {
+		return "", err
 	}
+	if err := ghc.Backend.PushBranch(repoPath, branch); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
 
-	// TODO improve commit message
-	// Add all new files
-	addCmdArgs := []string{"add", "-A"}
-	_, err = ghc.execCmd("git", addCmdArgs, repoPath)
-	if err != nil {
+// CurrentBranch returns the name of the current branch of the repo.
+func (ghc *GHCommon) CurrentBranch(repoPath string) (string, error) {
+	return ghc.Backend.CurrentBranch(repoPath)
+}
+
+// FetchCommit makes a commit of a given branch available on the local copy of the repo. Shallow or outdated copies
+// may not contain it, so the complete history of the branch is fetched from the origin in that case.
+func (ghc *GHCommon) FetchCommit(repoPath string, commit string, branch string) error {
+	exists, err := ghc.Backend.HasCommit(repoPath, commit)
+	if err != nil || exists {
 		return err
 	}
-	// Commit changes
-	commitCmdArgs := []string{"commit", "-a", "-m", fmt.Sprintf("gpm automatic publish")}
-	_, err = ghc.execCmd("git", commitCmdArgs, repoPath)
-	if err != nil {
+	log.Debug().Str("repoPath", repoPath).Str("commit", commit).Str("branch", branch).Msg("fetching branch history")
+	if err := ghc.Backend.FetchBranch(repoPath, branch); err != nil {
 		return err
 	}
-
-	// Push changes
-	pushCmdArgs := []string{"push"}
-	_, err = ghc.execCmd("git", pushCmdArgs, repoPath)
+	exists, err = ghc.Backend.HasCommit(repoPath, commit)
 	if err != nil {
 		return err
 	}
+	if !exists {
+		return fmt.Errorf("commit %s not found on branch %s", commit, branch)
+	}
+	return nil
+}
+
+// TagVersion creates the tag of a version on a given commit and pushes it.
+func (ghc *GHCommon) TagVersion(repoPath string, commit string, version *Version, tagMessage string) error {
 	// Create new tag
-	// tag -a v1.4 -m "my version 1.4"
-	tagCmdArgs := []string{"tag", "-a", newVersion.String(), "-m", fmt.Sprintf("new version %s generated by GPM", newVersion.String())}
-	_, err = ghc.execCmd("git", tagCmdArgs, repoPath)
-	if err != nil {
+	if tagMessage == "" {
+		tagMessage = fmt.Sprintf("new version %s generated by GPM", version.String())
+	}
+	if err := ghc.Backend.Tag(repoPath, commit, version.String(), tagMessage, ghc.pusher()); err != nil {
 		return err
 	}
 	// Push the tags
-	// git push origin --tags
-	pushTagCmdArgs := []string{"push", "origin", "--tags"}
-	_, err = ghc.execCmd("git", pushTagCmdArgs, repoPath)
-	if err != nil {
-		return err
+	return ghc.Backend.PushTags(repoPath)
+}
+
+// hostOrDefault returns the configured host or the default one for the provider if none is set.
+func hostOrDefault(host string, defaultHost string) string {
+	if host == "" {
+		return defaultHost
 	}
-	return nil
+	return host
 }
@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FileURLPrefix with the prefix of the URLs pointing to local repositories.
+const FileURLPrefix = "file://"
+
+// Placeholders supported on the URL templates of the generic git provider.
+const (
+	// OrgPlaceholder is replaced with the repository organization.
+	OrgPlaceholder = "{{org}}"
+	// RepoPlaceholder is replaced with the name of the target repository.
+	RepoPlaceholder = "{{repo}}"
+	// HostPlaceholder is replaced with the configured host.
+	HostPlaceholder = "{{host}}"
+	// TokenPlaceholder is replaced with the access token.
+	TokenPlaceholder = "{{token}}"
+)
+
+// GitCmdProvider structure with the implementation to manage any git server (e.g., Gitea, Bitbucket Server, or
+// bare repositories) relying on the git command. The URL of the repositories is built from a user supplied template.
+type GitCmdProvider struct {
+	GHCommon
+	// URLTemplate with the template used to build the repository URLs.
+	URLTemplate string
+}
+
+// NewGitCmdProvider creates a new provider for a generic git server.
+func NewGitCmdProvider(options ProviderOptions) (Provider, error) {
+	if options.URLTemplate == "" {
+		return nil, fmt.Errorf("repositoryURLTemplate is required for the git provider")
+	}
+	if !strings.Contains(options.URLTemplate, RepoPlaceholder) {
+		return nil, fmt.Errorf("repositoryURLTemplate %s must contain %s", options.URLTemplate, RepoPlaceholder)
+	}
+	log.Debug().Str("template", options.URLTemplate).Msg("Using GitCmdProvider")
+	backend, err := NewGitBackend(options.GitBackend)
+	if err != nil {
+		return nil, err
+	}
+	return &GitCmdProvider{
+		GHCommon: GHCommon{
+			Backend:           backend,
+			CloneDepth:        options.cloneDepth(),
+			DefaultBranch:     options.DefaultBranch,
+			SigningKey:        options.SigningKey,
+			Host:              options.Host,
+			SetPusherUserName: false,
+			SetPusherEmail:    false,
+		},
+		URLTemplate: options.URLTemplate,
+	}, nil
+}
+
+// GetRepoURL builds the URL require for clone and commit operations by expanding the URL template.
+func (gcp *GitCmdProvider) GetRepoURL(organization string, repoName string) (string, error) {
+	if strings.Contains(gcp.URLTemplate, TokenPlaceholder) && gcp.PersonalAccessToken == "" {
+		return "", fmt.Errorf("repositoryURLTemplate requires an access token")
+	}
+	replacer := strings.NewReplacer(
+		OrgPlaceholder, organization,
+		RepoPlaceholder, repoName,
+		HostPlaceholder, gcp.Host,
+		TokenPlaceholder, gcp.PersonalAccessToken,
+	)
+	// ssh://git@host:2222/{{org}}/{{repo}}.git
+	return replacer.Replace(gcp.URLTemplate), nil
+}
+
+// CreateRepoIfNotExists creates the target repository if it does not exist. Only local bare repositories
+// (file:// URLs) are supported as there is no common API among generic git servers.
+func (gcp *GitCmdProvider) CreateRepoIfNotExists(organization string, repoName string, settings RepoSettings) error {
+	repoURL, err := gcp.GetRepoURL(organization, repoName)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(repoURL, FileURLPrefix) {
+		return fmt.Errorf("repository creation is only supported for %s URLs", FileURLPrefix)
+	}
+	repoPath := strings.TrimPrefix(repoURL, FileURLPrefix)
+	if _, err := os.Stat(repoPath); err == nil {
+		log.Debug().Str("repo", repoName).Msg("repository already exists")
+		return nil
+	}
+	if err := os.MkdirAll(repoPath, 0755); err != nil {
+		return fmt.Errorf("unable to create repository directory: %w", err)
+	}
+	if err := gcp.Backend.InitBare(repoPath, settings.DefaultBranch); err != nil {
+		return fmt.Errorf("unable to initialize repository: %w", err)
+	}
+	if settings.Description != "" {
+		if err := ioutil.WriteFile(path.Join(repoPath, "description"), []byte(settings.Description+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	log.Info().Str("organization", organization).Str("repo", repoName).Str("path", repoPath).Msg("repository created")
+	return nil
+}
@@ -1,6 +1,12 @@
 package repo
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
 	"github.com/rs/zerolog/log"
 )
 
@@ -12,13 +18,223 @@ type GitHubCmdProvider struct {
 }
 
 // NewGitHubCmdProvider creates a new provider connecting to GitHub.
-func NewGitHubCmdProvider() (Provider, error) {
+func NewGitHubCmdProvider(options ProviderOptions) (Provider, error) {
 	log.Debug().Msg("Using GitHubCmdProvider")
+	backend, err := NewGitBackend(options.GitBackend)
+	if err != nil {
+		return nil, err
+	}
 	return &GitHubCmdProvider{
 		GHCommon: GHCommon{
+			Backend:           backend,
+			CloneDepth:        options.cloneDepth(),
+			DefaultBranch:     options.DefaultBranch,
+			SigningKey:        options.SigningKey,
+			Host:              hostOrDefault(options.Host, GitHubDefaultHost),
 			UseSSH:            true,
 			SetPusherUserName: false,
 			SetPusherEmail:    false,
 		},
 	}, nil
 }
+
+// apiURL returns the base URL of the GitHub REST API attending to the configured host. GitHub Enterprise
+// instances expose the API under /api/v3.
+func (ghp *GitHubCmdProvider) apiURL() string {
+	if ghp.Host == GitHubDefaultHost {
+		return "https://api.github.com"
+	}
+	return fmt.Sprintf("https://%s/api/v3", ghp.Host)
+}
+
+// apiClient returns a client authenticated with the access token.
+func (ghp *GitHubCmdProvider) apiClient() *apiClient {
+	return newAPIClient(map[string]string{
+		"Authorization": fmt.Sprintf("token %s", ghp.PersonalAccessToken),
+		"Accept":        "application/vnd.github.v3+json",
+	})
+}
+
+// CreateRepoIfNotExists checks if a repository exists and creates it otherwise using the GitHub API.
+func (ghp *GitHubCmdProvider) CreateRepoIfNotExists(organization string, repoName string, settings RepoSettings) error {
+	if ghp.PersonalAccessToken == "" {
+		return fmt.Errorf("an access token is required to create repository %s", repoName)
+	}
+	baseURL := ghp.apiURL()
+	client := ghp.apiClient()
+	status, err := client.do(http.MethodGet, fmt.Sprintf("%s/repos/%s/%s", baseURL, organization, repoName), nil, nil)
+	if err == nil {
+		log.Debug().Str("repo", repoName).Msg("repository already exists")
+		return nil
+	}
+	if status != http.StatusNotFound {
+		return err
+	}
+
+	// Repositories owned by the authenticated user are created through a different endpoint.
+	createURL := fmt.Sprintf("%s/orgs/%s/repos", baseURL, organization)
+	user := struct {
+		Login string `json:"login"`
+	}{}
+	if _, err := client.do(http.MethodGet, fmt.Sprintf("%s/user", baseURL), nil, &user); err != nil {
+		return err
+	}
+	if strings.EqualFold(user.Login, organization) {
+		createURL = fmt.Sprintf("%s/user/repos", baseURL)
+	}
+
+	request := map[string]interface{}{
+		"name":        repoName,
+		"description": settings.Description,
+		"private":     !settings.Public,
+		// Initialize the repo so the default branch exists.
+		"auto_init": true,
+	}
+	created := struct {
+		DefaultBranch string `json:"default_branch"`
+	}{}
+	if _, err := client.do(http.MethodPost, createURL, request, &created); err != nil {
+		return fmt.Errorf("unable to create repository %s: %w", repoName, err)
+	}
+	log.Info().Str("organization", organization).Str("repo", repoName).Bool("public", settings.Public).Msg("repository created")
+
+	if len(settings.Topics) > 0 {
+		topicsURL := fmt.Sprintf("%s/repos/%s/%s/topics", baseURL, organization, repoName)
+		if _, err := client.do(http.MethodPut, topicsURL, map[string]interface{}{"names": settings.Topics}, nil); err != nil {
+			return fmt.Errorf("unable to set topics of repository %s: %w", repoName, err)
+		}
+	}
+	if settings.DefaultBranch != "" && created.DefaultBranch != "" && settings.DefaultBranch != created.DefaultBranch {
+		renameURL := fmt.Sprintf("%s/repos/%s/%s/branches/%s/rename", baseURL, organization, repoName, created.DefaultBranch)
+		if _, err := client.do(http.MethodPost, renameURL, map[string]interface{}{"new_name": settings.DefaultBranch}, nil); err != nil {
+			return fmt.Errorf("unable to set default branch of repository %s: %w", repoName, err)
+		}
+	}
+	return nil
+}
+
+// githubPageSize with the number of elements requested on each page of the list operations.
+const githubPageSize = 100
+
+// githubPullRequest structure with the fields of the pull requests returned by the GitHub API.
+type githubPullRequest struct {
+	Number         int     `json:"number"`
+	HTMLURL        string  `json:"html_url"`
+	Title          string  `json:"title"`
+	Body           string  `json:"body"`
+	State          string  `json:"state"`
+	MergedAt       *string `json:"merged_at"`
+	MergeCommitSHA string  `json:"merge_commit_sha"`
+	Head           struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// toPullRequest converts the pull request returned by the API. The merge commit is only considered once merged,
+// as GitHub also reports a test merge commit for open pull requests.
+func (gpr *githubPullRequest) toPullRequest() PullRequest {
+	result := PullRequest{
+		Number:       gpr.Number,
+		URL:          gpr.HTMLURL,
+		SourceBranch: gpr.Head.Ref,
+		TargetBranch: gpr.Base.Ref,
+		Title:        gpr.Title,
+		Description:  gpr.Body,
+		State:        PullRequestOpen,
+	}
+	if gpr.MergedAt != nil {
+		result.State = PullRequestMerged
+		result.MergeCommit = gpr.MergeCommitSHA
+	} else if gpr.State == "closed" {
+		result.State = PullRequestClosed
+	}
+	return result
+}
+
+// OpenPullRequest opens a pull request using the GitHub API. If there is already an open one for the same source
+// branch, its title and description are updated instead.
+func (ghp *GitHubCmdProvider) OpenPullRequest(organization string, repoName string, request PullRequest) (*PullRequest, error) {
+	if ghp.PersonalAccessToken == "" {
+		return nil, fmt.Errorf("an access token is required to open pull requests on repository %s", repoName)
+	}
+	client := ghp.apiClient()
+	pullsURL := fmt.Sprintf("%s/repos/%s/%s/pulls", ghp.apiURL(), organization, repoName)
+	existing := make([]githubPullRequest, 0)
+	query := url.Values{"state": {"open"}, "head": {fmt.Sprintf("%s:%s", organization, request.SourceBranch)}}
+	if _, err := client.do(http.MethodGet, fmt.Sprintf("%s?%s", pullsURL, query.Encode()), nil, &existing); err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{
+		"title": request.Title,
+		"body":  request.Description,
+		"base":  request.TargetBranch,
+	}
+	result := githubPullRequest{}
+	if len(existing) > 0 {
+		log.Debug().Int("number", existing[0].Number).Msg("updating existing pull request")
+		if _, err := client.do(http.MethodPatch, fmt.Sprintf("%s/%d", pullsURL, existing[0].Number), body, &result); err != nil {
+			return nil, fmt.Errorf("unable to update pull request on repository %s: %w", repoName, err)
+		}
+	} else {
+		body["head"] = request.SourceBranch
+		if _, err := client.do(http.MethodPost, pullsURL, body, &result); err != nil {
+			return nil, fmt.Errorf("unable to open pull request on repository %s: %w", repoName, err)
+		}
+	}
+	pullRequest := result.toPullRequest()
+	return &pullRequest, nil
+}
+
+// ListPullRequests returns the pull requests whose source branch starts with a given prefix using the GitHub API.
+func (ghp *GitHubCmdProvider) ListPullRequests(organization string, repoName string, branchPrefix string) ([]PullRequest, error) {
+	if ghp.PersonalAccessToken == "" {
+		return nil, fmt.Errorf("an access token is required to list pull requests of repository %s", repoName)
+	}
+	client := ghp.apiClient()
+	result := make([]PullRequest, 0)
+	for page := 1; ; page++ {
+		query := url.Values{"state": {"all"}, "per_page": {strconv.Itoa(githubPageSize)}, "page": {strconv.Itoa(page)}}
+		pullRequests := make([]githubPullRequest, 0)
+		pullsURL := fmt.Sprintf("%s/repos/%s/%s/pulls?%s", ghp.apiURL(), organization, repoName, query.Encode())
+		if _, err := client.do(http.MethodGet, pullsURL, nil, &pullRequests); err != nil {
+			return nil, err
+		}
+		for _, pullRequest := range pullRequests {
+			if strings.HasPrefix(pullRequest.Head.Ref, branchPrefix) {
+				result = append(result, pullRequest.toPullRequest())
+			}
+		}
+		if len(pullRequests) < githubPageSize {
+			return result, nil
+		}
+	}
+}
+
+// ClosePullRequest closes a pull request using the GitHub API, adding a comment with the reason, and removes its
+// source branch.
+func (ghp *GitHubCmdProvider) ClosePullRequest(organization string, repoName string, pullRequest PullRequest, comment string) error {
+	if ghp.PersonalAccessToken == "" {
+		return fmt.Errorf("an access token is required to close pull requests of repository %s", repoName)
+	}
+	client := ghp.apiClient()
+	repoURL := fmt.Sprintf("%s/repos/%s/%s", ghp.apiURL(), organization, repoName)
+	if comment != "" {
+		commentsURL := fmt.Sprintf("%s/issues/%d/comments", repoURL, pullRequest.Number)
+		if _, err := client.do(http.MethodPost, commentsURL, map[string]interface{}{"body": comment}, nil); err != nil {
+			return fmt.Errorf("unable to comment pull request %d on repository %s: %w", pullRequest.Number, repoName, err)
+		}
+	}
+	pullURL := fmt.Sprintf("%s/pulls/%d", repoURL, pullRequest.Number)
+	if _, err := client.do(http.MethodPatch, pullURL, map[string]interface{}{"state": "closed"}, nil); err != nil {
+		return fmt.Errorf("unable to close pull request %d on repository %s: %w", pullRequest.Number, repoName, err)
+	}
+	// The branch may have been removed already.
+	refURL := fmt.Sprintf("%s/git/refs/heads/%s", repoURL, pullRequest.SourceBranch)
+	if status, err := client.do(http.MethodDelete, refURL, nil, nil); err != nil && status != http.StatusNotFound && status != http.StatusUnprocessableEntity {
+		return fmt.Errorf("unable to remove branch %s of repository %s: %w", pullRequest.SourceBranch, repoName, err)
+	}
+	return nil
+}
@@ -10,10 +10,19 @@ type GitHubActionProvider struct {
 }
 
 // NewGitHubActionProvider creates a new provider for GitHub when executed from within a GitHub action docker environment.
-func NewGitHubActionProvider() (Provider, error) {
+func NewGitHubActionProvider(options ProviderOptions) (Provider, error) {
 	log.Debug().Msg("Using GitHubActionProvider")
+	backend, err := NewGitBackend(options.GitBackend)
+	if err != nil {
+		return nil, err
+	}
 	return &GitHubCmdProvider{
 		GHCommon: GHCommon{
+			Backend:           backend,
+			CloneDepth:        options.cloneDepth(),
+			DefaultBranch:     options.DefaultBranch,
+			SigningKey:        options.SigningKey,
+			Host:              hostOrDefault(options.Host, GitHubDefaultHost),
 			UseHTTPS:          true,
 			SetPusherUserName: false,
 			SetPusherEmail:    false,
@@ -0,0 +1,555 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/rs/zerolog/log"
+)
+
+// originRemote with the name of the remote the repositories are cloned from.
+const originRemote = "origin"
+
+// installFileTransport guarantees that the in-process file transport is installed only once.
+var installFileTransport sync.Once
+
+// GoGitBackend structure with the implementation of the git operations using an in-process git library, so
+// the git binary is not required. Credentials embedded on HTTPS URLs are used for authentication without being
+// stored on the cloned repositories, and SSH URLs rely on the SSH agent.
+type GoGitBackend struct {
+	remoteCredentials
+}
+
+// NewGoGitBackend creates a backend that does not require the git binary. The default transport of local
+// repositories executes the git binary, so it is replaced with an in-process one.
+func NewGoGitBackend() GitBackend {
+	installFileTransport.Do(func() {
+		client.InstallProtocol("file", server.NewClient(server.DefaultLoader))
+	})
+	return &GoGitBackend{}
+}
+
+// tagsRefSpec with the refspec used to fetch all the tags of the origin remote overwriting the local ones.
+const tagsRefSpec = config.RefSpec("+refs/tags/*:refs/tags/*")
+
+// auth returns the basic authentication of the origin remote of a repository, or nil if it has no credentials.
+func (ggb *GoGitBackend) auth(repoPath string) transport.AuthMethod {
+	username, password, exists := ggb.credentials(repoPath)
+	if !exists {
+		return nil
+	}
+	return &githttp.BasicAuth{Username: username, Password: password}
+}
+
+// fetch retrieves a set of references from the origin remote. An up to date repository is not considered an error.
+// Shallow fetches of up to date repositories over HTTP report an empty request instead.
+func (ggb *GoGitBackend) fetch(repository *git.Repository, auth transport.AuthMethod, depth int, refSpecs ...config.RefSpec) error {
+	err := repository.Fetch(&git.FetchOptions{RemoteName: originRemote, RefSpecs: refSpecs, Depth: depth, Force: true, Auth: auth})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) && !errors.Is(err, transport.ErrEmptyRemoteRepository) && !errors.Is(err, transport.ErrEmptyUploadPackRequest) {
+		return fmt.Errorf("unable to fetch from %s due to %w", originRemote, err)
+	}
+	return nil
+}
+
+// supportedDepth returns the depth to be used with a given repository. The in-process transport of local
+// repositories does not support shallow operations, which are not needed either as no data is transferred.
+func (ggb *GoGitBackend) supportedDepth(repoURL string, depth int) int {
+	if strings.HasPrefix(repoURL, FileURLPrefix) {
+		return 0
+	}
+	return depth
+}
+
+// Clone a given repository to a path. Empty repositories are initialized locally with the origin remote as
+// git clone does, with the current branch pointing to the HEAD of the remote or to the given default branch.
+func (ggb *GoGitBackend) Clone(repoURL string, outputPath string, depth int, defaultBranch string) error {
+	depth = ggb.supportedDepth(repoURL, depth)
+	remoteURL := ggb.register(repoURL, outputPath)
+	repository, err := git.PlainClone(outputPath, false, &git.CloneOptions{URL: remoteURL, Depth: depth, Auth: ggb.auth(outputPath)})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		log.Debug().Str("outputPath", outputPath).Msg("empty repository, initializing local copy")
+		return ggb.initEmptyClone(remoteURL, outputPath, defaultBranch)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to clone repo %s due to %w", remoteURL, err)
+	}
+	log.Debug().Str("outputPath", outputPath).Msg("repo successfully cloned")
+
+	// Shallow clones do not include the tags of previous commits.
+	if depth > 0 {
+		return ggb.fetch(repository, ggb.auth(outputPath), depth, tagsRefSpec)
+	}
+	return nil
+}
+
+// initEmptyClone initializes the local copy of an empty repository. The library defaults to master as the
+// current branch, so HEAD is pointed to the branch the remote expects and that branch is set to track it.
+func (ggb *GoGitBackend) initEmptyClone(repoURL string, outputPath string, defaultBranch string) error {
+	repository, err := git.PlainInit(outputPath, false)
+	if err != nil {
+		return fmt.Errorf("unable to initialize local copy of repo %s due to %w", repoURL, err)
+	}
+	if _, err := repository.CreateRemote(&config.RemoteConfig{Name: originRemote, URLs: []string{repoURL}}); err != nil {
+		return err
+	}
+	branch := ggb.remoteHead(repoURL, ggb.auth(outputPath))
+	if branch == "" && defaultBranch != "" {
+		branch = plumbing.NewBranchReferenceName(defaultBranch)
+	}
+	if branch == "" {
+		return nil
+	}
+	if err := repository.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)); err != nil {
+		return err
+	}
+	cfg, err := repository.Config()
+	if err != nil {
+		return err
+	}
+	cfg.Branches[branch.Short()] = &config.Branch{Name: branch.Short(), Remote: originRemote, Merge: branch}
+	log.Debug().Str("outputPath", outputPath).Str("branch", branch.Short()).Msg("current branch set")
+	return repository.SetConfig(cfg)
+}
+
+// remoteHead returns the branch the HEAD of a remote repository points to. An empty name is returned if the remote
+// does not advertise it, which is common for empty repositories.
+func (ggb *GoGitBackend) remoteHead(repoURL string, auth transport.AuthMethod) plumbing.ReferenceName {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return ""
+	}
+	transportClient, err := client.NewClient(endpoint)
+	if err != nil {
+		return ""
+	}
+	// Empty repositories are only advertised by receive-pack sessions.
+	session, err := transportClient.NewReceivePackSession(endpoint, auth)
+	if err != nil {
+		log.Debug().Err(err).Msg("unable to open session to read remote HEAD")
+		return ""
+	}
+	defer session.Close()
+	advertised, err := session.AdvertisedReferences()
+	if err != nil {
+		log.Debug().Err(err).Msg("unable to read remote HEAD")
+		return ""
+	}
+	for _, symRef := range advertised.Capabilities.Get(capability.SymRef) {
+		parts := strings.SplitN(symRef, ":", 2)
+		if len(parts) == 2 && parts[0] == plumbing.HEAD.String() {
+			return plumbing.ReferenceName(parts[1])
+		}
+	}
+	return ""
+}
+
+// Update fetches the latest changes and tags of a previously cloned repository from the given URL, and resets the
+// working tree to the remote state of the current branch removing any local change.
+func (ggb *GoGitBackend) Update(repoURL string, repoPath string, depth int) error {
+	depth = ggb.supportedDepth(repoURL, depth)
+	repository, worktree, err := ggb.worktree(repoPath)
+	if err != nil {
+		return err
+	}
+	// The URL may change across runs, and previous versions stored the access token on it.
+	cfg, err := repository.Config()
+	if err != nil {
+		return err
+	}
+	remote, exists := cfg.Remotes[originRemote]
+	if !exists {
+		return fmt.Errorf("repository %s has no %s remote", repoPath, originRemote)
+	}
+	remote.URLs = []string{ggb.register(repoURL, repoPath)}
+	if err := repository.SetConfig(cfg); err != nil {
+		return err
+	}
+	if err := ggb.fetch(repository, ggb.auth(repoPath), depth, remote.Fetch...); err != nil {
+		return err
+	}
+	if err := ggb.fetch(repository, ggb.auth(repoPath), depth, tagsRefSpec); err != nil {
+		return err
+	}
+
+	head, err := repository.Head()
+	if err != nil {
+		return fmt.Errorf("current branch of %s cannot be resolved: %w", repoPath, err)
+	}
+	upstream, err := repository.Reference(plumbing.NewRemoteReferenceName(originRemote, head.Name().Short()), true)
+	if err != nil {
+		return fmt.Errorf("current branch of %s does not track a remote branch", repoPath)
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: upstream.Hash(), Mode: git.HardReset}); err != nil {
+		return err
+	}
+	return worktree.Clean(&git.CleanOptions{Dir: true})
+}
+
+// LatestTag obtains the most recent tag reachable from the current commit. If several tags point to the same
+// commit, the last one in alphabetical order is returned.
+func (ggb *GoGitBackend) LatestTag(repoPath string) (string, error) {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", err
+	}
+	head, err := repository.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		// No commits yet.
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Index the tags by the commit they point to, resolving annotated tags.
+	tagsByCommit := make(map[plumbing.Hash][]string, 0)
+	tags, err := repository.Tags()
+	if err != nil {
+		return "", err
+	}
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		target := ref.Hash()
+		if tagObject, err := repository.TagObject(target); err == nil {
+			commit, err := tagObject.Commit()
+			if err != nil {
+				return nil
+			}
+			target = commit.Hash
+		}
+		tagsByCommit[target] = append(tagsByCommit[target], ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(tagsByCommit) == 0 {
+		return "", nil
+	}
+
+	commits, err := repository.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return "", err
+	}
+	latest := ""
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if names, exists := tagsByCommit[commit.Hash]; exists {
+			sort.Strings(names)
+			latest = names[len(names)-1]
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to obtain latest tag from repo %s due to %w", repoPath, err)
+	}
+	return latest, nil
+}
+
+// Tags returns the names of all the tags of the repository.
+func (ggb *GoGitBackend) Tags(repoPath string) ([]string, error) {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := repository.Tags()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0)
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		result = append(result, ref.Name().Short())
+		return nil
+	})
+	return result, err
+}
+
+// ReadFile returns the content of a file on a given revision. A nil content is returned if the file does not exist
+// on that revision.
+func (ggb *GoGitBackend) ReadFile(repoPath string, revision string, filePath string) ([]byte, error) {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := repository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("revision %s not found due to %w", revision, err)
+	}
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find commit %s due to %w", hash, err)
+	}
+	file, err := commit.File(filePath)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// worktree opens the working tree of a local repository.
+func (ggb *GoGitBackend) worktree(repoPath string) (*git.Repository, *git.Worktree, error) {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return nil, nil, err
+	}
+	return repository, worktree, nil
+}
+
+// signature builds the identity of a commit or tag. Missing fields are taken from the git configuration, and nil
+// is returned if none is set so the library resolves the identity by itself.
+func (ggb *GoGitBackend) signature(repository *git.Repository, identity Signature) (*object.Signature, error) {
+	if identity.Name == "" && identity.Email == "" {
+		return nil, nil
+	}
+	result := &object.Signature{Name: identity.Name, Email: identity.Email, When: time.Now()}
+	if result.Name == "" || result.Email == "" {
+		cfg, err := repository.ConfigScoped(config.SystemScope)
+		if err != nil {
+			return nil, err
+		}
+		if result.Name == "" {
+			result.Name = cfg.User.Name
+		}
+		if result.Email == "" {
+			result.Email = cfg.User.Email
+		}
+	}
+	return result, nil
+}
+
+// AddAll stages all the changes of the working tree, including removed files.
+func (ggb *GoGitBackend) AddAll(repoPath string) error {
+	_, worktree, err := ggb.worktree(repoPath)
+	if err != nil {
+		return err
+	}
+	return worktree.AddWithOptions(&git.AddOptions{All: true})
+}
+
+// HasChanges checks if there are staged or unstaged changes on the working tree.
+func (ggb *GoGitBackend) HasChanges(repoPath string) (bool, error) {
+	_, worktree, err := ggb.worktree(repoPath)
+	if err != nil {
+		return false, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return false, err
+	}
+	return !status.IsClean(), nil
+}
+
+// Commit the staged changes.
+func (ggb *GoGitBackend) Commit(repoPath string, message string, author Signature) error {
+	repository, worktree, err := ggb.worktree(repoPath)
+	if err != nil {
+		return err
+	}
+	signature, err := ggb.signature(repository, author)
+	if err != nil {
+		return err
+	}
+	options := &git.CommitOptions{All: true, Author: signature}
+	if author.SigningKey != nil {
+		if options.SignKey, err = author.SigningKey.openPGPEntity(); err != nil {
+			return err
+		}
+	}
+	_, err = worktree.Commit(message, options)
+	if err != nil {
+		return fmt.Errorf("unable to commit changes due to %w", err)
+	}
+	return nil
+}
+
+// HasCommit checks if a commit is available on the local repository.
+func (ggb *GoGitBackend) HasCommit(repoPath string, commit string) (bool, error) {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return false, err
+	}
+	_, err = repository.CommitObject(plumbing.NewHash(commit))
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// unshallowDepth with the depth requested to retrieve the complete history of shallow clones, as git does.
+const unshallowDepth = math.MaxInt32
+
+// FetchBranch fetches the complete history of a branch of the origin remote, deepening shallow clones.
+func (ggb *GoGitBackend) FetchBranch(repoPath string, branch string) error {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	shallows, err := repository.Storer.Shallow()
+	if err != nil {
+		return err
+	}
+	depth := 0
+	if len(shallows) > 0 {
+		depth = unshallowDepth
+	}
+	refSpec := config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), plumbing.NewRemoteReferenceName(originRemote, branch)))
+	return ggb.fetch(repository, ggb.auth(repoPath), depth, refSpec)
+}
+
+// HeadCommit returns the hash of the current commit. The path may be a subdirectory of the repository.
+func (ggb *GoGitBackend) HeadCommit(repoPath string) (string, error) {
+	repository, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", err
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+// CurrentBranch returns the name of the current branch.
+func (ggb *GoGitBackend) CurrentBranch(repoPath string) (string, error) {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", err
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return "", err
+	}
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("repository %s is not on a branch", repoPath)
+	}
+	return head.Name().Short(), nil
+}
+
+// Tag a given commit with an annotated tag.
+func (ggb *GoGitBackend) Tag(repoPath string, commit string, name string, message string, tagger Signature) error {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	hash := plumbing.NewHash(commit)
+	if _, err := repository.CommitObject(hash); err != nil {
+		return fmt.Errorf("unable to find commit %s due to %w", commit, err)
+	}
+	signature, err := ggb.signature(repository, tagger)
+	if err != nil {
+		return err
+	}
+	options := &git.CreateTagOptions{Message: message, Tagger: signature}
+	if tagger.SigningKey != nil {
+		if options.SignKey, err = tagger.SigningKey.openPGPEntity(); err != nil {
+			return err
+		}
+	}
+	_, err = repository.CreateTag(name, hash, options)
+	if err != nil {
+		return fmt.Errorf("unable to create tag %s due to %w", name, err)
+	}
+	return nil
+}
+
+// push sends a set of references to the origin remote.
+func (ggb *GoGitBackend) push(repoPath string, refSpec config.RefSpec) error {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	err = repository.Push(&git.PushOptions{RemoteName: originRemote, RefSpecs: []config.RefSpec{refSpec}, Auth: ggb.auth(repoPath)})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("unable to push %s due to %w", refSpec, err)
+	}
+	return nil
+}
+
+// Push the current branch to the origin remote. The branch it tracks is used as destination, if any.
+func (ggb *GoGitBackend) Push(repoPath string) error {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	head, err := repository.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return err
+	}
+	// HEAD is symbolic even before the first commit, in which case it cannot be resolved yet.
+	branch := head.Name()
+	if head.Type() == plumbing.SymbolicReference {
+		branch = head.Target()
+	}
+	if !branch.IsBranch() {
+		return fmt.Errorf("repository %s is not on a branch", repoPath)
+	}
+	destination := branch
+	cfg, err := repository.Config()
+	if err != nil {
+		return err
+	}
+	if tracking, exists := cfg.Branches[branch.Short()]; exists && tracking.Remote == originRemote && tracking.Merge != "" {
+		destination = tracking.Merge
+	}
+	return ggb.push(repoPath, config.RefSpec(fmt.Sprintf("%s:%s", branch, destination)))
+}
+
+// PushBranch pushes the current commit to a given branch of the origin remote, overwriting its previous content.
+func (ggb *GoGitBackend) PushBranch(repoPath string, branch string) error {
+	repository, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return err
+	}
+	return ggb.push(repoPath, config.RefSpec(fmt.Sprintf("+%s:%s", head.Name().String(), plumbing.NewBranchReferenceName(branch))))
+}
+
+// PushTags pushes all the tags to the origin remote.
+func (ggb *GoGitBackend) PushTags(repoPath string) error {
+	return ggb.push(repoPath, config.RefSpec("refs/tags/*:refs/tags/*"))
+}
+
+// InitBare creates an empty bare repository whose HEAD points to the given branch, if any.
+func (ggb *GoGitBackend) InitBare(repoPath string, defaultBranch string) error {
+	if err := os.MkdirAll(repoPath, 0755); err != nil {
+		return err
+	}
+	repository, err := git.PlainInit(repoPath, true)
+	if err != nil {
+		return err
+	}
+	if defaultBranch != "" {
+		head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(defaultBranch))
+		return repository.Storer.SetReference(head)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestGoGitBackendFirstPushUsesRemoteHead(t *testing.T) {
+	for _, defaultBranch := range []string{"main", "trunk"} {
+		t.Run(defaultBranch, func(t *testing.T) {
+			baseDir := t.TempDir()
+			remotePath := filepath.Join(baseDir, "remote.git")
+			localPath := filepath.Join(baseDir, "local")
+			backend := NewGoGitBackend()
+			if err := backend.InitBare(remotePath, defaultBranch); err != nil {
+				t.Fatalf("unable to create remote: %v", err)
+			}
+			// The configured default branch must not take precedence over the one advertised by the remote.
+			if err := backend.Clone(FileURLPrefix+remotePath, localPath, 0, "other"); err != nil {
+				t.Fatalf("unable to clone: %v", err)
+			}
+			local, err := git.PlainOpen(localPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if head, err := local.Head(); err == nil {
+				t.Fatalf("expected unborn branch, got %s", head.Name())
+			}
+			if err := ioutil.WriteFile(filepath.Join(localPath, "README.md"), []byte("content\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := backend.AddAll(localPath); err != nil {
+				t.Fatal(err)
+			}
+			if err := backend.Commit(localPath, "first", Signature{Name: "gpm", Email: "gpm@example.com"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := backend.Push(localPath); err != nil {
+				t.Fatalf("unable to push: %v", err)
+			}
+
+			remote, err := git.PlainOpen(remotePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := remote.Reference(plumbing.NewBranchReferenceName(defaultBranch), false); err != nil {
+				t.Errorf("branch %s not pushed: %v", defaultBranch, err)
+			}
+			if _, err := remote.Reference(plumbing.Master, false); err == nil {
+				t.Errorf("unexpected master branch on remote")
+			}
+			if err := backend.Update(FileURLPrefix+remotePath, localPath, 0); err != nil {
+				t.Errorf("unable to update clone: %v", err)
+			}
+			// The published branch must be usable by the other backend as well.
+			cliPath := filepath.Join(baseDir, "cli")
+			if err := NewCmdGitBackend().Clone(FileURLPrefix+remotePath, cliPath, 0, ""); err != nil {
+				t.Fatalf("unable to clone with the git binary: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(cliPath, "README.md")); err != nil {
+				t.Errorf("clone has no content: %v", err)
+			}
+		})
+	}
+}
+
+func TestGoGitBackendEmptyCloneUsesDefaultBranch(t *testing.T) {
+	baseDir := t.TempDir()
+	remotePath := filepath.Join(baseDir, "remote.git")
+	localPath := filepath.Join(baseDir, "local")
+	if _, err := git.PlainInit(remotePath, true); err != nil {
+		t.Fatal(err)
+	}
+	remote, err := git.PlainOpen(remotePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a remote that does not advertise its HEAD.
+	if err := remote.Storer.RemoveReference(plumbing.HEAD); err != nil {
+		t.Fatal(err)
+	}
+	backend := NewGoGitBackend()
+	if err := backend.Clone(FileURLPrefix+remotePath, localPath, 0, "main"); err != nil {
+		t.Fatalf("unable to clone: %v", err)
+	}
+	local, err := git.PlainOpen(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := local.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Target() != plumbing.NewBranchReferenceName("main") {
+		t.Errorf("expected HEAD to point to main, got %s", head.Target())
+	}
+	cfg, err := local.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracking, exists := cfg.Branches["main"]
+	if !exists || tracking.Remote != originRemote || tracking.Merge != plumbing.NewBranchReferenceName("main") {
+		t.Errorf("expected main to track %s, got %+v", originRemote, tracking)
+	}
+}
+
+func TestGoGitBackendFetchCommitOnOutdatedClone(t *testing.T) {
+	baseDir := t.TempDir()
+	remotePath := filepath.Join(baseDir, "remote.git")
+	writerPath := filepath.Join(baseDir, "writer")
+	readerPath := filepath.Join(baseDir, "reader")
+	backend := NewGoGitBackend()
+	if err := backend.InitBare(remotePath, "main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Clone(FileURLPrefix+remotePath, writerPath, 0, "main"); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, backend, writerPath, "first.txt")
+	if err := backend.Push(writerPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Clone(FileURLPrefix+remotePath, readerPath, 0, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The commit is merged after the reader copy was cloned.
+	merged := commitFile(t, backend, writerPath, "second.txt")
+	if err := backend.Push(writerPath); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := backend.HasCommit(readerPath, merged); err != nil || exists {
+		t.Fatalf("expected commit missing on outdated clone, got %v, %v", exists, err)
+	}
+	provider := &GHCommon{Backend: backend}
+	if err := provider.FetchCommit(readerPath, merged, "main"); err != nil {
+		t.Fatalf("unable to fetch commit: %v", err)
+	}
+	if exists, err := backend.HasCommit(readerPath, merged); err != nil || !exists {
+		t.Errorf("expected commit fetched, got %v, %v", exists, err)
+	}
+}
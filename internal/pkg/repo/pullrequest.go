@@ -0,0 +1,88 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PublishMode defines a type for all supported ways of publishing the generated code.
+type PublishMode int
+
+const (
+	// PushMode commits and tags the changes directly on the default branch.
+	PushMode PublishMode = iota
+	// PullRequestMode pushes the changes to a version branch and opens a pull request. The version is tagged once
+	// the pull request is merged.
+	PullRequestMode
+)
+
+// PublishModeToString map associating type to its string representation.
+var PublishModeToString = map[PublishMode]string{
+	PushMode:        "push",
+	PullRequestMode: "pullrequest",
+}
+
+// PublishModeToEnum map associating string representation with type.
+var PublishModeToEnum = map[string]PublishMode{
+	"push":        PushMode,
+	"pullrequest": PullRequestMode,
+}
+
+// VersionBranchPrefix with the prefix of the branches used to publish a new version through a pull request.
+const VersionBranchPrefix = "gpm/"
+
+// VersionBranch returns the name of the branch used to publish a version through a pull request.
+func VersionBranch(version *Version) string {
+	return fmt.Sprintf("%s%s", VersionBranchPrefix, version.String())
+}
+
+// VersionFromBranch returns the version published on a branch, or an error if it is not a version branch.
+func VersionFromBranch(branch string) (*Version, error) {
+	if !strings.HasPrefix(branch, VersionBranchPrefix) {
+		return nil, fmt.Errorf("branch %s is not a version branch", branch)
+	}
+	return FromTag(strings.TrimPrefix(branch, VersionBranchPrefix))
+}
+
+// State values of the pull requests.
+const (
+	// PullRequestOpen for pull requests pending to be merged.
+	PullRequestOpen = "open"
+	// PullRequestMerged for merged pull requests.
+	PullRequestMerged = "merged"
+	// PullRequestClosed for pull requests closed without merging them.
+	PullRequestClosed = "closed"
+)
+
+// PullRequest structure with the information of a pull request (merge request on GitLab).
+type PullRequest struct {
+	// Number identifying the pull request on the repository.
+	Number int
+	// URL of the pull request on the web interface.
+	URL string
+	// SourceBranch with the branch containing the changes.
+	SourceBranch string
+	// TargetBranch with the branch where the changes are merged.
+	TargetBranch string
+	// Title of the pull request.
+	Title string
+	// Description of the pull request.
+	Description string
+	// State of the pull request: open, merged or closed.
+	State string
+	// MergeCommit with the commit that includes the changes on the target branch once merged.
+	MergeCommit string
+}
+
+// PullRequestManager defines the interface for the repository providers that are able to publish the changes through
+// pull requests.
+type PullRequestManager interface {
+	// OpenPullRequest opens a pull request. If there is already an open one for the same source branch, its title and
+	// description are updated instead.
+	OpenPullRequest(organization string, repoName string, request PullRequest) (*PullRequest, error)
+	// ListPullRequests returns the pull requests whose source branch starts with a given prefix.
+	ListPullRequests(organization string, repoName string, branchPrefix string) ([]PullRequest, error)
+	// ClosePullRequest closes a pull request without merging it, adding a comment with the reason, and removes its
+	// source branch.
+	ClosePullRequest(organization string, repoName string, pullRequest PullRequest, comment string) error
+}
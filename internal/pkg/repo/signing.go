@@ -0,0 +1,102 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// SigningFormat defines a type for all supported signature formats of commits and tags.
+type SigningFormat int
+
+const (
+	// GPGSigning signs with an OpenPGP key.
+	GPGSigning SigningFormat = iota
+	// SSHSigning signs with an SSH key. Requires git 2.34 or later.
+	SSHSigning
+)
+
+// SigningFormatToString map associating type to its string representation.
+var SigningFormatToString = map[SigningFormat]string{
+	GPGSigning: "gpg",
+	SSHSigning: "ssh",
+}
+
+// SigningFormatToEnum map associating string representation with type.
+var SigningFormatToEnum = map[string]SigningFormat{
+	"gpg": GPGSigning,
+	"ssh": SSHSigning,
+}
+
+// gitSigningFormat with the values of the gpg.format git setting for each format.
+var gitSigningFormat = map[SigningFormat]string{
+	GPGSigning: "openpgp",
+	SSHSigning: "ssh",
+}
+
+// SSHLiteralKeyPrefix is the prefix used by git to pass an SSH public key instead of a path.
+const SSHLiteralKeyPrefix = "key::"
+
+// SigningKey structure with the key used to sign the commits and tags.
+type SigningKey struct {
+	// Format of the signatures.
+	Format SigningFormat
+	// Key used to sign. For gpg, the key ID with the cli backend, or the path of an armored private key with the
+	// gogit backend. For ssh, the path of the key or a public key prefixed by key::.
+	Key string
+	// Passphrase protecting the armored private key. Only used by the gogit backend.
+	Passphrase string
+	// Program used to sign instead of the one of the git configuration (gpg.program or gpg.ssh.program).
+	Program string
+}
+
+// programSetting returns the git setting that selects the signing program.
+func (sk *SigningKey) programSetting() string {
+	if sk.Format == SSHSigning {
+		return "gpg.ssh.program"
+	}
+	return "gpg.program"
+}
+
+// openPGPEntity loads the armored private key of the signing key, decrypting it if required.
+func (sk *SigningKey) openPGPEntity() (*openpgp.Entity, error) {
+	if sk.Format != GPGSigning {
+		return nil, fmt.Errorf("%s signing is not supported by the gogit backend, use the cli backend", SigningFormatToString[sk.Format])
+	}
+	file, err := os.Open(sk.Key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open signing key: %w", err)
+	}
+	defer file.Close()
+	entities, err := openpgp.ReadArmoredKeyRing(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read signing key %s: %w", sk.Key, err)
+	}
+	if len(entities) == 0 || entities[0].PrivateKey == nil {
+		return nil, fmt.Errorf("signing key %s does not contain a private key", sk.Key)
+	}
+	entity := entities[0]
+	if entity.PrivateKey.Encrypted {
+		if sk.Passphrase == "" {
+			return nil, fmt.Errorf("signing key %s is protected by a passphrase, but none is configured", sk.Key)
+		}
+		if err := entity.PrivateKey.Decrypt([]byte(sk.Passphrase)); err != nil {
+			return nil, fmt.Errorf("cannot decrypt signing key %s: %w", sk.Key, err)
+		}
+		for _, subkey := range entity.Subkeys {
+			if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+				if err := subkey.PrivateKey.Decrypt([]byte(sk.Passphrase)); err != nil {
+					return nil, fmt.Errorf("cannot decrypt signing subkey of %s: %w", sk.Key, err)
+				}
+			}
+		}
+	}
+	return entity, nil
+}
+
+// IsLiteral checks if the key is given inline instead of as a path.
+func (sk *SigningKey) IsLiteral() bool {
+	return sk.Format == SSHSigning && strings.HasPrefix(sk.Key, SSHLiteralKeyPrefix)
+}
@@ -7,7 +7,14 @@ import (
 	"strings"
 )
 
-var semanticMatcher = regexp.MustCompile("^v[0-9]+.[0-9]+.[0-9]+$")
+// semanticMatcher follows the SemVer 2.0 grammar with a v prefix. The groups are major, minor, patch, pre-release
+// and build metadata.
+var semanticMatcher = regexp.MustCompile(`^v(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// invalidIdentifierChars matches the characters that are not allowed on pre-release and build identifiers.
+var invalidIdentifierChars = regexp.MustCompile(`[^0-9A-Za-z-]+`)
 
 // Version object to capture the uploaded version on the repo.
 type Version struct {
@@ -17,6 +24,10 @@ type Version struct {
 	Minor int
 	// Patch version.
 	Patch int
+	// PreRelease with the dot separated identifiers of a pre-release version (e.g., rc.1). Empty for releases.
+	PreRelease []string
+	// Build with the dot separated build metadata identifiers. They are ignored when determining precedence.
+	Build []string
 }
 
 // EmptyVersion returns an empty version.
@@ -29,39 +40,155 @@ func FromTag(repoTag string) (*Version, error) {
 	repoTag = strings.TrimRight(repoTag, "\n")
 
 	// Check if the version matches the regex
-	if !semanticMatcher.MatchString(repoTag) {
-		return nil, fmt.Errorf("version %s does not match %s", repoTag, semanticMatcher.String())
+	parts := semanticMatcher.FindStringSubmatch(repoTag)
+	if parts == nil {
+		return nil, fmt.Errorf("version %s is not a semantic version (e.g., v1.2.3, v1.2.3-rc.1)", repoTag)
 	}
 
-	noVersion := strings.ReplaceAll(repoTag, "v", "")
-	split := strings.Split(noVersion, ".")
-
-	major, err := strconv.Atoi(split[0])
+	major, err := strconv.Atoi(parts[1])
 	if err != nil {
 		return nil, err
 	}
-	minor, err := strconv.Atoi(split[1])
+	minor, err := strconv.Atoi(parts[2])
 	if err != nil {
 		return nil, err
 	}
-	patch, err := strconv.Atoi(split[2])
+	patch, err := strconv.Atoi(parts[3])
 	if err != nil {
 		return nil, err
 	}
 
-	return &Version{
+	result := &Version{
 		Major: major,
 		Minor: minor,
 		Patch: patch,
-	}, nil
+	}
+	if parts[4] != "" {
+		result.PreRelease = strings.Split(parts[4], ".")
+	}
+	if parts[5] != "" {
+		result.Build = strings.Split(parts[5], ".")
+	}
+	return result, nil
 }
 
-// IncrementMinor the minor version.
+// PreReleaseIdentifier transforms a name, such as a branch name, into a valid pre-release identifier.
+func PreReleaseIdentifier(name string) string {
+	return strings.Trim(invalidIdentifierChars.ReplaceAllString(name, "-"), "-")
+}
+
+// IsPreRelease checks if this is a pre-release version.
+func (v *Version) IsPreRelease() bool {
+	return len(v.PreRelease) > 0
+}
+
+// Release returns the release version associated with this one, without pre-release nor build metadata.
+func (v *Version) Release() *Version {
+	return &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// compareInts returns -1, 0 or 1 attending to the order of two integers.
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifiers compares two pre-release identifiers. Numeric identifiers are compared numerically and have
+// lower precedence than alphanumeric ones, which are compared lexically.
+func compareIdentifiers(a string, b string) int {
+	numA, errA := strconv.Atoi(a)
+	numB, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInts(numA, numB)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// Compare returns -1, 0 or 1 if this version precedes, is equivalent to or follows another one following the SemVer
+// precedence rules. A pre-release precedes its release, and build metadata is ignored.
+func (v *Version) Compare(other *Version) int {
+	if result := compareInts(v.Major, other.Major); result != 0 {
+		return result
+	}
+	if result := compareInts(v.Minor, other.Minor); result != 0 {
+		return result
+	}
+	if result := compareInts(v.Patch, other.Patch); result != 0 {
+		return result
+	}
+	switch {
+	case !v.IsPreRelease() && !other.IsPreRelease():
+		return 0
+	case !v.IsPreRelease():
+		return 1
+	case !other.IsPreRelease():
+		return -1
+	}
+	for index := 0; index < len(v.PreRelease) && index < len(other.PreRelease); index++ {
+		if result := compareIdentifiers(v.PreRelease[index], other.PreRelease[index]); result != 0 {
+			return result
+		}
+	}
+	return compareInts(len(v.PreRelease), len(other.PreRelease))
+}
+
+// LessThan checks if this version precedes another one.
+func (v *Version) LessThan(other *Version) bool {
+	return v.Compare(other) < 0
+}
+
+// IncrementMajor the major version. The major version of a pre-release whose minor and patch are zero is released
+// instead (e.g., v2.0.0-rc.1 becomes v2.0.0).
+func (v *Version) IncrementMajor() {
+	if !v.IsPreRelease() || v.Minor != 0 || v.Patch != 0 {
+		v.Major++
+	}
+	v.Minor = 0
+	v.Patch = 0
+	v.clearMetadata()
+}
+
+// IncrementMinor the minor version. The minor version of a pre-release whose patch is zero is released instead.
 func (v *Version) IncrementMinor() {
-	v.Minor++
+	if !v.IsPreRelease() || v.Patch != 0 {
+		v.Minor++
+	}
+	v.Patch = 0
+	v.clearMetadata()
+}
+
+// IncrementPatch the patch version. A pre-release is released instead.
+func (v *Version) IncrementPatch() {
+	if !v.IsPreRelease() {
+		v.Patch++
+	}
+	v.clearMetadata()
+}
+
+// clearMetadata removes the pre-release and build identifiers.
+func (v *Version) clearMetadata() {
+	v.PreRelease = nil
+	v.Build = nil
 }
 
 // String representation of this version.
 func (v *Version) String() string {
-	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	result := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.IsPreRelease() {
+		result = fmt.Sprintf("%s-%s", result, strings.Join(v.PreRelease, "."))
+	}
+	if len(v.Build) > 0 {
+		result = fmt.Sprintf("%s+%s", result, strings.Join(v.Build, "."))
+	}
+	return result
 }
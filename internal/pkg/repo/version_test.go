@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"testing"
+)
+
+// mustParse builds a version from a tag failing the test if it is not valid.
+func mustParse(t *testing.T, tag string) *Version {
+	t.Helper()
+	version, err := FromTag(tag)
+	if err != nil {
+		t.Fatalf("unable to parse %s: %v", tag, err)
+	}
+	return version
+}
+
+func TestFromTag(t *testing.T) {
+	testCases := []struct {
+		tag   string
+		valid bool
+	}{
+		{"v1.2.3", true},
+		{"v1.2.3\n", true},
+		{"v0.0.0", true},
+		{"v1.0.0-rc.1", true},
+		{"v1.0.0-alpha.beta-1.0+build.5", true},
+		{"v1.0.0+20211010", true},
+		{"1.2.3", false},
+		{"v1.2", false},
+		{"v01.2.3", false},
+		{"v1.2.3-01", false},
+		{"v1.2.3-", false},
+		{"v1.2.3-rc..1", false},
+		{"latest", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			version, err := FromTag(tc.tag)
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected error, got %s", version)
+			}
+		})
+	}
+	version := mustParse(t, "v1.0.0-alpha.beta-1.0+build.5")
+	if version.String() != "v1.0.0-alpha.beta-1.0+build.5" {
+		t.Errorf("unexpected string representation %s", version)
+	}
+}
+
+func TestVersionPrecedence(t *testing.T) {
+	ordered := []string{
+		"v0.9.9",
+		"v1.0.0-0",
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+		"v1.0.1",
+		"v1.1.0",
+		"v2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a := mustParse(t, ordered[i])
+			b := mustParse(t, ordered[j])
+			expected := compareInts(i, j)
+			if result := a.Compare(b); result != expected {
+				t.Errorf("%s compared to %s: expected %d, got %d", a, b, expected, result)
+			}
+		}
+	}
+	if mustParse(t, "v1.0.0+build.1").Compare(mustParse(t, "v1.0.0+build.2")) != 0 {
+		t.Errorf("build metadata must be ignored")
+	}
+	if !EmptyVersion().LessThan(mustParse(t, "v0.0.1")) {
+		t.Errorf("empty version must precede any other")
+	}
+}
+
+func TestVersionIncrement(t *testing.T) {
+	testCases := []struct {
+		name      string
+		current   string
+		increment func(*Version)
+		expected  string
+	}{
+		{"major of release", "v1.2.3", (*Version).IncrementMajor, "v2.0.0"},
+		{"minor of release", "v1.2.3", (*Version).IncrementMinor, "v1.3.0"},
+		{"patch of release", "v1.2.3", (*Version).IncrementPatch, "v1.2.4"},
+		{"build metadata removed", "v1.2.3+build.1", (*Version).IncrementPatch, "v1.2.4"},
+		{"major of major pre-release", "v2.0.0-rc.1", (*Version).IncrementMajor, "v2.0.0"},
+		{"minor of major pre-release", "v2.0.0-rc.1", (*Version).IncrementMinor, "v2.0.0"},
+		{"patch of major pre-release", "v2.0.0-rc.1", (*Version).IncrementPatch, "v2.0.0"},
+		{"major of minor pre-release", "v1.3.0-beta", (*Version).IncrementMajor, "v2.0.0"},
+		{"minor of minor pre-release", "v1.3.0-beta", (*Version).IncrementMinor, "v1.3.0"},
+		{"major of patch pre-release", "v1.2.4-alpha.1", (*Version).IncrementMajor, "v2.0.0"},
+		{"minor of patch pre-release", "v1.2.4-alpha.1", (*Version).IncrementMinor, "v1.3.0"},
+		{"patch of patch pre-release", "v1.2.4-alpha.1", (*Version).IncrementPatch, "v1.2.4"},
+		{"major of empty version", "v0.0.0", (*Version).IncrementMajor, "v1.0.0"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			version := mustParse(t, tc.current)
+			tc.increment(version)
+			if version.String() != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, version)
+			}
+		})
+	}
+}
+
+func TestPreReleaseIdentifier(t *testing.T) {
+	testCases := map[string]string{
+		"feature/login":   "feature-login",
+		"fix_#12":         "fix-12",
+		"--release--":     "release",
+		"JIRA-123.update": "JIRA-123-update",
+	}
+	for name, expected := range testCases {
+		if result := PreReleaseIdentifier(name); result != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, result)
+		}
+	}
+}